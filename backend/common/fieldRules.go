@@ -0,0 +1,23 @@
+package common
+
+import "strconv"
+
+// 字段长度限制(字符数)，接口参数校验与批量导入共用
+const (
+	MaxTagNameLength  = 100 // 标签名称
+	MaxItemNameLength = 100 // 物品名称
+	MaxRoomNameLength = 50  // 房间名称
+	MaxRemarkLength   = 500 // 备注
+)
+
+// CurrencyRule 币种校验规则(ISO 4217代码)，与接口参数binding标签中的 iso4217 一致，批量导入使用同一规则校验
+const CurrencyRule = "iso4217"
+
+// FieldRuleAliases 字段校验规则别名，在binding标签中代替具体的长度规则
+// 由controller注册到gin的校验器，修改上方的长度限制即可同时作用于接口和导入
+var FieldRuleAliases = map[string]string{
+	"tag_name":  "max=" + strconv.Itoa(MaxTagNameLength),
+	"item_name": "max=" + strconv.Itoa(MaxItemNameLength),
+	"room_name": "max=" + strconv.Itoa(MaxRoomNameLength),
+	"remark":    "max=" + strconv.Itoa(MaxRemarkLength),
+}
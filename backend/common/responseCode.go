@@ -3,17 +3,60 @@ package common
 // 响应码定义
 const (
 	CodeSuccess               = 200  // 成功
+
+	// 通用错误
+	CodeInvalidParams    = 400 // 请求参数错误
+	CodePermissionDenied = 403 // 无权限操作
+	CodeNotFound         = 404 // 记录不存在
+	CodeConflict         = 409 // 数据冲突
+	CodeTooManyRequests  = 429 // 请求过于频繁
+	CodeInternalError    = 500 // 服务器内部错误
+
 	CodeUserNotLogin          = 10000 // 用户未登录
 	CodeUserNotRegistered     = 10001 // 用户未注册
 	CodeMoveNotFound          = 20000 // 用户无此搬运记录
 	CodeTagNotFound           = 30000 // 用户无此标签记录
+	CodeRoomNotFound          = 40000 // 用户无此房间记录
+	CodeItemNotFound          = 50000 // 用户无此物品记录
+	CodeIssueNotFound         = 60000 // 用户无此问题记录
+	CodeAttachmentNotFound    = 70000 // 用户无此附件记录
+	CodeStorageQuotaExceeded  = 70001 // 存储空间不足
+
+	// 扫码相关错误
+	CodeScanLinkInvalid         = 80000 // 扫码链接无效或已过期
+	CodeAnonymousVerifyDisabled = 80001 // 该搬运未开启免登录核销
+	CodeWebhookNotFound         = 90000 // 用户无此Webhook记录
+
+	// 备份恢复相关错误
+	CodeBackupInvalid     = 100000 // 备份文件无效
+	CodeBackupUnsupported = 100001 // 不支持的备份版本
+	CodeBackupConflict    = 100002 // 备份数据与其他用户的数据冲突
+	CodeHandoverNotFound  = 110000 // 用户无此交接记录
 )
 
 // 响应消息映射
 var CodeMessage = map[int]string{
 	CodeSuccess:               "操作成功",
+	CodeInvalidParams:         "请求参数错误",
+	CodePermissionDenied:      "无权限操作",
+	CodeNotFound:              "记录不存在",
+	CodeConflict:              "数据冲突",
+	CodeTooManyRequests:       "请求过于频繁，请稍后再试",
+	CodeInternalError:         "服务器内部错误",
 	CodeUserNotLogin:          "用户未登录",
 	CodeUserNotRegistered:     "用户未注册",
 	CodeMoveNotFound:          "用户无此搬运记录",
 	CodeTagNotFound:           "用户无此标签记录",
+	CodeRoomNotFound:          "用户无此房间记录",
+	CodeItemNotFound:          "用户无此物品记录",
+	CodeIssueNotFound:         "用户无此问题记录",
+	CodeAttachmentNotFound:    "用户无此附件记录",
+	CodeStorageQuotaExceeded:  "存储空间不足",
+	CodeScanLinkInvalid:         "扫码链接无效或已过期",
+	CodeAnonymousVerifyDisabled: "该搬运未开启免登录核销",
+	CodeWebhookNotFound:         "用户无此Webhook记录",
+	CodeBackupInvalid:           "备份文件无效",
+	CodeBackupUnsupported:       "不支持的备份版本",
+	CodeBackupConflict:          "备份数据与其他用户的数据冲突",
+	CodeHandoverNotFound:        "用户无此交接记录",
 }
\ No newline at end of file
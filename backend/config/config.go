@@ -8,6 +8,62 @@ import (
 
 // Config 应用配置结构
 type Config struct {
+	Server    ServerConfig    `yaml:"server"`     // HTTP服务配置
+	Log       LogConfig       `yaml:"log"`        // 日志配置
+	Storage   StorageConfig   `yaml:"storage"`    // 附件存储配置
+	Scan      ScanConfig      `yaml:"scan"`       // 扫码链接配置
+	Webhook   WebhookConfig   `yaml:"webhook"`    // Webhook推送配置
+	RateLimit RateLimitConfig `yaml:"rate_limit"` // 限流配置
+}
+
+// ServerConfig HTTP服务配置
+type ServerConfig struct {
+	Addr                   string   `yaml:"addr"`                     // 监听地址
+	ReadTimeoutSeconds     int      `yaml:"read_timeout_seconds"`     // 读取请求(含请求体)超时(秒)
+	WriteTimeoutSeconds    int      `yaml:"write_timeout_seconds"`    // 写响应超时(秒)，需覆盖PDF等耗时导出
+	IdleTimeoutSeconds     int      `yaml:"idle_timeout_seconds"`     // keep-alive空闲连接超时(秒)
+	ShutdownTimeoutSeconds int      `yaml:"shutdown_timeout_seconds"` // 退出时等待进行中请求完成的最长时间(秒)
+	TLSCertFile            string   `yaml:"tls_cert_file"`            // TLS证书文件，与私钥同时配置时启用HTTPS
+	TLSKeyFile             string   `yaml:"tls_key_file"`             // TLS私钥文件
+	TrustedProxies         []string `yaml:"trusted_proxies"`          // 可信反向代理的IP或网段，为空时忽略X-Forwarded-For
+}
+
+// LogConfig 日志配置
+type LogConfig struct {
+	Level     string `yaml:"level"`       // 日志级别(debug/info/warn/error)
+	Format    string `yaml:"format"`      // 输出格式(json/text)
+	SQLLevel  string `yaml:"sql_level"`   // SQL日志级别(silent/error/warn/debug)，debug记录全部语句(不含参数值)
+	SlowSQLMs int    `yaml:"slow_sql_ms"` // 慢SQL阈值(毫秒)，超过时按warn记录
+}
+
+// RateLimitConfig 限流配置
+type RateLimitConfig struct {
+	Store    string            `yaml:"store"`     // 计数存储(memory/redis)，多实例部署时使用redis共享计数
+	RedisURL string            `yaml:"redis_url"` // Redis地址，如 redis://localhost:6379/0
+	Rules    map[string]string `yaml:"rules"`     // 各规则限额，格式"次数-周期"(周期S/M/H/D)，"off"表示关闭
+}
+
+// StorageConfig 附件存储配置
+type StorageConfig struct {
+	Driver        string `yaml:"driver"`           // 存储驱动(local)
+	LocalRoot     string `yaml:"local_root"`       // 本地存储根目录
+	UserQuotaMB   int64  `yaml:"user_quota_mb"`    // 每个用户的存储配额(MB)
+	MaxFileSizeMB int64  `yaml:"max_file_size_mb"` // 单个文件大小上限(MB)
+	ThumbMaxSize  int    `yaml:"thumb_max_size"`   // 缩略图最长边(像素)
+}
+
+// ScanConfig 扫码链接配置
+type ScanConfig struct {
+	Secret      string `yaml:"secret"`        // 签名密钥(必填，为空时拒绝启动；修改后已打印的二维码失效)
+	BaseURL     string `yaml:"base_url"`      // 前端访问地址
+	LinkTTLDays int    `yaml:"link_ttl_days"` // 链接有效期(天)
+}
+
+// WebhookConfig Webhook推送配置
+type WebhookConfig struct {
+	MaxAttempts         int `yaml:"max_attempts"`          // 最大尝试次数
+	TimeoutSeconds      int `yaml:"timeout_seconds"`       // 单次请求超时(秒)
+	PollIntervalSeconds int `yaml:"poll_interval_seconds"` // 投递队列轮询间隔(秒)
 }
 
 // 全局配置实例
@@ -27,4 +83,4 @@ func InitConfig(configPath string) error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,225 @@
+package controller
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+
+	"movingManager/common"
+	"movingManager/dto"
+	"movingManager/model"
+	"movingManager/service"
+)
+
+// UploadTagAttachmentRequest 上传标签附件请求参数(multipart/form-data，文件字段为file)
+type UploadTagAttachmentRequest struct {
+	TagUid string `form:"tag_uid" binding:"required,uuid"` // 标签UID
+}
+
+// UploadTagAttachment 上传标签照片接口
+func UploadTagAttachment(c *gin.Context) {
+	var req UploadTagAttachmentRequest
+	if err := c.ShouldBind(&req); err != nil {
+		respondInvalidParams(c, err)
+		return
+	}
+
+	uploadAttachment(c, service.UploadAttachmentRequest{TagUid: req.TagUid})
+}
+
+// UploadMoveAttachmentRequest 上传搬运附件请求参数(multipart/form-data，文件字段为file)
+type UploadMoveAttachmentRequest struct {
+	MoveUid string `form:"move_uid" binding:"required,uuid"` // 搬运UID
+}
+
+// UploadMoveAttachment 上传搬运照片接口
+func UploadMoveAttachment(c *gin.Context) {
+	var req UploadMoveAttachmentRequest
+	if err := c.ShouldBind(&req); err != nil {
+		respondInvalidParams(c, err)
+		return
+	}
+
+	uploadAttachment(c, service.UploadAttachmentRequest{MoveUid: req.MoveUid})
+}
+
+// uploadAttachment 读取上传文件并调用服务层保存附件
+func uploadAttachment(c *gin.Context, serviceReq service.UploadAttachmentRequest) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		respondBadRequest(c, "请求参数错误: 缺少上传文件")
+		return
+	}
+
+	// 获取当前用户UID
+	userUid, exists := c.Get("userUid")
+	if !exists {
+		respondNotLogin(c)
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		respondError(c, "读取上传文件失败: ", err)
+		return
+	}
+	defer file.Close()
+
+	// 调用服务层保存附件
+	serviceReq.FileName = fileHeader.Filename
+	serviceReq.Reader = file
+	attachment, err := service.UploadAttachment(userUid.(string), serviceReq)
+	if err != nil {
+		respondError(c, "上传附件失败: ", err)
+		return
+	}
+
+	// 返回成功响应
+	respondSuccess(c, gin.H{
+		"code":       common.CodeSuccess,
+		"message":    "上传成功",
+		"attachment": attachment,
+	}, attachment)
+}
+
+// GetTagAttachmentListRequest 标签附件列表请求参数
+type GetTagAttachmentListRequest struct {
+	TagUid string `json:"tag_uid" binding:"required,uuid"` // 标签UID
+}
+
+// GetTagAttachmentList 获取标签照片列表接口
+func GetTagAttachmentList(c *gin.Context) {
+	var req GetTagAttachmentListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidParams(c, err)
+		return
+	}
+
+	listAttachments(c, "", req.TagUid)
+}
+
+// GetMoveAttachmentListRequest 搬运附件列表请求参数
+type GetMoveAttachmentListRequest struct {
+	MoveUid string `json:"move_uid" binding:"required,uuid"` // 搬运UID
+}
+
+// GetMoveAttachmentList 获取搬运照片列表接口
+func GetMoveAttachmentList(c *gin.Context) {
+	var req GetMoveAttachmentListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidParams(c, err)
+		return
+	}
+
+	listAttachments(c, req.MoveUid, "")
+}
+
+// listAttachments 查询附件列表及当前用户存储用量
+func listAttachments(c *gin.Context, moveUid, tagUid string) {
+	// 获取当前用户UID
+	userUid, exists := c.Get("userUid")
+	if !exists {
+		respondNotLogin(c)
+		return
+	}
+
+	// 调用服务层获取附件列表
+	attachments, err := service.GetAttachmentList(userUid.(string), moveUid, tagUid)
+	if err != nil {
+		respondError(c, "获取附件列表失败: ", err)
+		return
+	}
+	usage, err := service.GetStorageUsage(userUid.(string))
+	if err != nil {
+		respondError(c, "获取附件列表失败: ", err)
+		return
+	}
+
+	// 返回成功响应
+	respondSuccess(c, gin.H{
+		"code":        common.CodeSuccess,
+		"attachments": attachments,
+		"usage":       usage,
+	}, dto.AttachmentList{Attachments: attachments, Usage: usage})
+}
+
+// DeleteAttachmentRequest 删除附件请求参数
+type DeleteAttachmentRequest struct {
+	AttachmentUid string `json:"attachment_uid" binding:"required,uuid"` // 附件UID
+}
+
+// DeleteTagAttachment 删除标签照片接口
+func DeleteTagAttachment(c *gin.Context) {
+	deleteAttachment(c, model.AttachmentOwnerTag)
+}
+
+// DeleteMoveAttachment 删除搬运照片接口
+func DeleteMoveAttachment(c *gin.Context) {
+	deleteAttachment(c, model.AttachmentOwnerMove)
+}
+
+// deleteAttachment 删除指定归属类型的附件
+func deleteAttachment(c *gin.Context, ownerType string) {
+	var req DeleteAttachmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidParams(c, err)
+		return
+	}
+
+	// 获取当前用户UID
+	userUid, exists := c.Get("userUid")
+	if !exists {
+		respondNotLogin(c)
+		return
+	}
+
+	// 调用服务层删除附件
+	if err := service.DeleteAttachment(userUid.(string), req.AttachmentUid, ownerType); err != nil {
+		respondError(c, "删除附件失败: ", err)
+		return
+	}
+
+	// 返回成功响应
+	respondSuccess(c, gin.H{
+		"code":    common.CodeSuccess,
+		"message": "操作成功",
+	}, nil)
+}
+
+// DownloadAttachmentRequest 下载附件请求参数
+type DownloadAttachmentRequest struct {
+	AttachmentUid string `json:"attachment_uid" binding:"required,uuid"` // 附件UID
+	Thumb         bool   `json:"thumb"`                                  // 是否下载缩略图
+}
+
+// DownloadAttachment 下载附件原图或缩略图接口
+func DownloadAttachment(c *gin.Context) {
+	var req DownloadAttachmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidParams(c, err)
+		return
+	}
+
+	// 获取当前用户UID
+	userUid, exists := c.Get("userUid")
+	if !exists {
+		respondNotLogin(c)
+		return
+	}
+
+	// 调用服务层打开附件
+	reader, attachment, err := service.OpenAttachment(userUid.(string), req.AttachmentUid, req.Thumb)
+	if err != nil {
+		respondError(c, "下载附件失败: ", err)
+		return
+	}
+	defer reader.Close()
+
+	// 设置响应头，返回文件内容
+	c.Header("Content-Type", attachment.ContentType)
+	c.Header("Content-Disposition", "inline; filename*=UTF-8''"+url.PathEscape(attachment.FileName))
+	c.Status(http.StatusOK)
+	io.Copy(c.Writer, reader)
+}
@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"movingManager/common"
+	"movingManager/logging"
+	"movingManager/service"
+)
+
+// BackupAccount 导出账户备份接口
+// 返回包含全部搬运、标签、物品、问题、扫码事件、交接记录和附件的zip文件
+func BackupAccount(c *gin.Context) {
+	// 获取当前用户UID
+	userUid, exists := c.Get("userUid")
+	if !exists {
+		respondNotLogin(c)
+		return
+	}
+
+	// 读取数据记录，开始输出前的错误仍以JSON返回
+	backup, err := service.PrepareAccountBackup(userUid.(string))
+	if err != nil {
+		respondError(c, "导出账户备份失败: ", err)
+		return
+	}
+
+	// 设置响应头，流式返回备份文件
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", "attachment; filename=moving-backup-"+time.Now().Format("20060102")+".zip")
+	c.Status(http.StatusOK)
+	if err := backup.Write(c.Writer); err != nil {
+		// 响应已开始输出，只能记录日志并中断连接
+		logging.FromContext(c.Request.Context()).Error("导出账户备份失败", "error", err)
+		c.Abort()
+	}
+}
+
+// RestoreAccount 从备份恢复账户数据接口
+// multipart表单字段 file 为备份zip文件，数据恢复到当前用户并保留原有UID
+func RestoreAccount(c *gin.Context) {
+	// 限制请求体大小，避免超大上传占满磁盘
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, service.MaxBackupSize())
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			respondBadRequest(c, "请求参数错误: 备份文件过大")
+			return
+		}
+		respondBadRequest(c, "请求参数错误: 缺少备份文件")
+		return
+	}
+
+	// 获取当前用户UID
+	userUid, exists := c.Get("userUid")
+	if !exists {
+		respondNotLogin(c)
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		respondError(c, "读取备份文件失败: ", err)
+		return
+	}
+	defer file.Close()
+
+	// 调用服务层恢复数据
+	result, err := service.RestoreAccountBackup(userUid.(string), file, fileHeader.Size)
+	if err != nil {
+		respondError(c, "恢复账户备份失败: ", err)
+		return
+	}
+
+	// 返回成功响应
+	respondSuccess(c, gin.H{
+		"code":    common.CodeSuccess,
+		"message": "恢复成功",
+		"result":  result,
+	}, result)
+}
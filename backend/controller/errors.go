@@ -0,0 +1,43 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"movingManager/common"
+	"movingManager/dto"
+	"movingManager/service"
+)
+
+// resolveError 将服务层错误转换为响应码、HTTP状态码和提示信息
+// 业务错误使用其自带的响应码和提示信息；其他错误视为内部错误，提示信息加上操作前缀
+func resolveError(prefix string, err error) (int, int, string) {
+	var domainErr *service.DomainError
+	if errors.As(err, &domainErr) {
+		return domainErr.Code, domainErr.Status, domainErr.Error()
+	}
+	return common.CodeInternalError, http.StatusInternalServerError, prefix + err.Error()
+}
+
+// respondError 返回错误响应
+func respondError(c *gin.Context, prefix string, err error) {
+	code, status, message := resolveError(prefix, err)
+	dto.WriteError(c, status, code, message)
+}
+
+// respondInvalidParams 返回请求绑定失败的参数错误响应
+func respondInvalidParams(c *gin.Context, err error) {
+	respondBadRequest(c, "请求参数错误: "+err.Error())
+}
+
+// respondBadRequest 返回参数错误响应
+func respondBadRequest(c *gin.Context, message string) {
+	dto.WriteError(c, http.StatusBadRequest, common.CodeInvalidParams, message)
+}
+
+// respondNotLogin 返回用户未登录响应
+func respondNotLogin(c *gin.Context) {
+	dto.WriteError(c, http.StatusUnauthorized, common.CodeUserNotLogin, common.CodeMessage[common.CodeUserNotLogin])
+}
@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"movingManager/common"
+	"movingManager/service"
+)
+
+func TestResolveError(t *testing.T) {
+	cases := []struct {
+		name    string
+		err     error
+		code    int
+		status  int
+		message string
+	}{
+		{"标签不存在", service.ErrTagNotFound, common.CodeTagNotFound, http.StatusNotFound, "用户无此标签记录"},
+		{"包装后的搬运不存在", fmt.Errorf("查询失败: %w", service.ErrMoveNotFound), common.CodeMoveNotFound, http.StatusNotFound, "用户无此搬运记录"},
+		{"存储空间不足", service.ErrStorageQuotaExceeded, common.CodeStorageQuotaExceeded, http.StatusConflict, "存储空间不足"},
+		{"扫码链接无效", service.ErrScanLinkInvalid, common.CodeScanLinkInvalid, http.StatusForbidden, "扫码链接无效或已过期"},
+		{"备份冲突", service.ErrBackupConflict, common.CodeBackupConflict, http.StatusConflict, "备份数据与其他用户的数据冲突"},
+		{"参数校验", service.ErrNoTags, common.CodeInvalidParams, http.StatusBadRequest, "该搬运下没有标签"},
+		{"内部错误", errors.New("连接断开"), common.CodeInternalError, http.StatusInternalServerError, "更新标签失败: 连接断开"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			code, status, message := resolveError("更新标签失败: ", tc.err)
+			if code != tc.code || status != tc.status || message != tc.message {
+				t.Errorf("resolveError() = (%d, %d, %q), want (%d, %d, %q)", code, status, message, tc.code, tc.status, tc.message)
+			}
+		})
+	}
+}
+
+func TestDomainErrorKind(t *testing.T) {
+	cases := []struct {
+		err  error
+		kind error
+	}{
+		{service.ErrTagNotFound, service.ErrNotFound},
+		{service.ErrStorageQuotaExceeded, service.ErrConflict},
+		{service.ErrAnonymousVerifyDisabled, service.ErrPermission},
+		{service.ErrBackupInvalid, service.ErrValidation},
+	}
+	for _, tc := range cases {
+		if !errors.Is(tc.err, tc.kind) {
+			t.Errorf("errors.Is(%v, %v) = false", tc.err, tc.kind)
+		}
+	}
+	if errors.Is(service.ErrTagNotFound, service.ErrMoveNotFound) {
+		t.Error("不同的不存在错误不应相互匹配")
+	}
+}
@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"movingManager/service"
+)
+
+// sseHeartbeatInterval SSE心跳间隔，避免代理因连接空闲断开
+const sseHeartbeatInterval = 25 * time.Second
+
+// MoveEventsRequest 订阅搬运实时事件请求参数
+type MoveEventsRequest struct {
+	MoveUid string `form:"move_uid" binding:"required,uuid"` // 搬运UID
+}
+
+// GetMoveEvents 搬运实时事件推送接口(Server-Sent Events)
+// 推送标签创建、核销、删除等事件和搬运标签统计变更
+func GetMoveEvents(c *gin.Context) {
+	var req MoveEventsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondInvalidParams(c, err)
+		return
+	}
+
+	// 获取当前用户UID
+	userUid, exists := c.Get("userUid")
+	if !exists {
+		respondNotLogin(c)
+		return
+	}
+
+	// 调用服务层订阅事件
+	events, cancel, err := service.SubscribeMoveEvents(userUid.(string), req.MoveUid)
+	if err != nil {
+		respondError(c, "订阅搬运事件失败: ", err)
+		return
+	}
+	defer cancel()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // 关闭Nginx缓冲
+
+	// SSE为长连接，取消服务端的写超时
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	// 先发送连接成功事件，便于客户端确认订阅生效
+	c.SSEvent("ready", gin.H{"move_uid": req.MoveUid})
+	c.Writer.Flush()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case e, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.SSEvent(e.Type, e)
+			return true
+		case <-heartbeat.C:
+			// SSE注释行作为心跳
+			if _, err := io.WriteString(w, ": ping\n\n"); err != nil {
+				return false
+			}
+			return true
+		}
+	})
+}
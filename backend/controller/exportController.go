@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"movingManager/logging"
+	"movingManager/service"
+)
+
+// ExportMoveRequest 导出搬运清单请求参数
+type ExportMoveRequest struct {
+	MoveUid string `json:"move_uid" binding:"required,uuid"`              // 搬运UID
+	Format  string `json:"format" binding:"required,oneof=csv xlsx json"` // 导出格式(csv,xlsx,json)
+}
+
+// ExportMove 导出搬运清单接口
+// 清单内容边读取边写入响应，大搬运不会整体缓存在内存中
+func ExportMove(c *gin.Context) {
+	var req ExportMoveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidParams(c, err)
+		return
+	}
+
+	// 获取当前用户UID
+	userUid, exists := c.Get("userUid")
+	if !exists {
+		respondNotLogin(c)
+		return
+	}
+
+	// 校验搬运权限，开始输出前的错误仍以JSON返回
+	export, err := service.PrepareMoveExport(userUid.(string), req.MoveUid)
+	if err != nil {
+		respondError(c, "导出搬运清单失败: ", err)
+		return
+	}
+
+	// 设置响应头，流式返回清单文件
+	c.Header("Content-Type", service.ExportContentType(req.Format))
+	c.Header("Content-Disposition", "attachment; filename=move-"+req.MoveUid+"."+req.Format)
+	c.Status(http.StatusOK)
+	if err := export.Write(req.Format, c.Writer); err != nil {
+		// 响应已开始输出，只能记录日志并中断连接
+		logging.FromContext(c.Request.Context()).Error("导出搬运清单失败", "move_uid", req.MoveUid, "format", req.Format, "error", err)
+		c.Abort()
+	}
+}
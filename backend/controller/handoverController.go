@@ -0,0 +1,132 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"movingManager/common"
+	"movingManager/service"
+)
+
+// CreateHandoverRequest 创建交接记录请求参数(multipart/form-data，签名图片字段为signature)
+type CreateHandoverRequest struct {
+	MoveUid      string `form:"move_uid" binding:"required,uuid"`        // 搬运UID
+	ReceiverName string `form:"receiver_name" binding:"required,max=50"` // 收货人姓名
+	Remark       string `form:"remark" binding:"remark"`                // 备注
+}
+
+// CreateHandover 创建交接记录接口
+func CreateHandover(c *gin.Context) {
+	var req CreateHandoverRequest
+	if err := c.ShouldBind(&req); err != nil {
+		respondInvalidParams(c, err)
+		return
+	}
+
+	fileHeader, err := c.FormFile("signature")
+	if err != nil {
+		respondBadRequest(c, "请求参数错误: 缺少签名图片")
+		return
+	}
+
+	// 获取当前用户UID
+	userUid, exists := c.Get("userUid")
+	if !exists {
+		respondNotLogin(c)
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		respondError(c, "读取签名图片失败: ", err)
+		return
+	}
+	defer file.Close()
+
+	// 调用服务层创建交接记录
+	handover, err := service.CreateHandover(userUid.(string), service.CreateHandoverRequest{
+		MoveUid:      req.MoveUid,
+		ReceiverName: req.ReceiverName,
+		Remark:       req.Remark,
+		Signature:    file,
+	})
+	if err != nil {
+		respondError(c, "创建交接记录失败: ", err)
+		return
+	}
+
+	// 返回成功响应
+	respondSuccess(c, gin.H{
+		"code":     common.CodeSuccess,
+		"message":  "交接成功",
+		"handover": handover,
+	}, handover)
+}
+
+// GetHandoverListRequest 交接记录列表请求参数
+type GetHandoverListRequest struct {
+	MoveUid string `json:"move_uid" binding:"required,uuid"` // 搬运UID
+}
+
+// GetHandoverList 交接记录列表接口
+func GetHandoverList(c *gin.Context) {
+	var req GetHandoverListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidParams(c, err)
+		return
+	}
+
+	// 获取当前用户UID
+	userUid, exists := c.Get("userUid")
+	if !exists {
+		respondNotLogin(c)
+		return
+	}
+
+	// 调用服务层获取交接记录
+	handovers, err := service.GetHandoverList(userUid.(string), req.MoveUid)
+	if err != nil {
+		respondError(c, "获取交接记录失败: ", err)
+		return
+	}
+
+	// 返回成功响应
+	respondSuccess(c, gin.H{
+		"code":         common.CodeSuccess,
+		"message":      "获取成功",
+		"handoverList": handovers,
+	}, handovers)
+}
+
+// GenerateHandoverReceiptRequest 生成送达回执请求参数
+type GenerateHandoverReceiptRequest struct {
+	HandoverUid string `json:"handover_uid" binding:"required,uuid"` // 交接UID
+}
+
+// GenerateHandoverReceipt 生成送达回执PDF接口
+func GenerateHandoverReceipt(c *gin.Context) {
+	var req GenerateHandoverReceiptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidParams(c, err)
+		return
+	}
+
+	// 获取当前用户UID
+	userUid, exists := c.Get("userUid")
+	if !exists {
+		respondNotLogin(c)
+		return
+	}
+
+	// 调用服务层生成回执
+	pdfBytes, err := service.GenerateHandoverReceiptPDF(userUid.(string), req.HandoverUid)
+	if err != nil {
+		respondError(c, "生成送达回执失败: ", err)
+		return
+	}
+
+	// 设置响应头，返回PDF文件
+	c.Header("Content-Disposition", "attachment; filename=delivery-receipt.pdf")
+	c.Data(http.StatusOK, "application/pdf", pdfBytes)
+}
@@ -0,0 +1,23 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"movingManager/service"
+)
+
+// Healthz 存活检查接口，进程能处理请求即返回200
+func Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readyz 就绪检查接口，数据库可用且迁移已执行时返回200，否则返回503
+func Readyz(c *gin.Context) {
+	if err := service.CheckReadiness(c.Request.Context()); err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
@@ -0,0 +1,204 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"movingManager/common"
+	"movingManager/service"
+)
+
+// CreateIssueRequest 登记问题请求参数
+type CreateIssueRequest struct {
+	TagUid        string   `json:"tag_uid" binding:"required,uuid"`          // 标签UID
+	ItemUid       string   `json:"item_uid" binding:"omitempty,uuid"`        // 物品UID(为空表示整个标签)
+	IssueType     int      `json:"issue_type" binding:"required,oneof=1 2"`  // 问题类型(1-丢失,2-损坏)
+	DeclaredValue int64    `json:"declared_value" binding:"min=0"`           // 申报损失金额(分)
+	Remark        string   `json:"remark" binding:"remark"`                 // 问题说明
+	PhotoRefs     []string `json:"photo_refs" binding:"max=10,dive,max=500"` // 照片引用
+}
+
+// CreateIssue 登记丢失/损坏问题接口
+func CreateIssue(c *gin.Context) {
+	var req CreateIssueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidParams(c, err)
+		return
+	}
+
+	// 获取当前用户UID
+	userUid, exists := c.Get("userUid")
+	if !exists {
+		respondNotLogin(c)
+		return
+	}
+
+	// 调用服务层登记问题
+	issue, err := service.CreateIssue(userUid.(string), service.CreateIssueRequest{
+		TagUid:        req.TagUid,
+		ItemUid:       req.ItemUid,
+		IssueType:     req.IssueType,
+		DeclaredValue: req.DeclaredValue,
+		Remark:        req.Remark,
+		PhotoRefs:     req.PhotoRefs,
+	})
+	if err != nil {
+		respondError(c, "登记问题失败: ", err)
+		return
+	}
+
+	// 返回成功响应
+	respondSuccess(c, gin.H{
+		"code":    common.CodeSuccess,
+		"message": "创建成功",
+		"issue":   issue,
+	}, issue)
+}
+
+// UpdateIssueRequest 更新问题请求参数
+type UpdateIssueRequest struct {
+	IssueUid      string   `json:"issue_uid" binding:"required,uuid"`        // 问题UID
+	Status        int      `json:"status" binding:"oneof=0 1 2"`             // 处理状态(0-待处理,1-已找回,2-已处理)
+	DeclaredValue int64    `json:"declared_value" binding:"min=0"`           // 申报损失金额(分)
+	Remark        string   `json:"remark" binding:"remark"`                 // 问题说明
+	PhotoRefs     []string `json:"photo_refs" binding:"max=10,dive,max=500"` // 照片引用
+}
+
+// UpdateIssue 更新问题处理进度接口
+func UpdateIssue(c *gin.Context) {
+	var req UpdateIssueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidParams(c, err)
+		return
+	}
+
+	// 获取当前用户UID
+	userUid, exists := c.Get("userUid")
+	if !exists {
+		respondNotLogin(c)
+		return
+	}
+
+	// 调用服务层更新问题
+	issue, err := service.UpdateIssue(userUid.(string), service.UpdateIssueRequest{
+		IssueUid:      req.IssueUid,
+		Status:        req.Status,
+		DeclaredValue: req.DeclaredValue,
+		Remark:        req.Remark,
+		PhotoRefs:     req.PhotoRefs,
+	})
+	if err != nil {
+		respondError(c, "更新问题失败: ", err)
+		return
+	}
+
+	// 返回成功响应
+	respondSuccess(c, gin.H{
+		"code":    common.CodeSuccess,
+		"message": "操作成功",
+		"issue":   issue,
+	}, issue)
+}
+
+// DeleteIssueRequest 删除问题请求参数
+type DeleteIssueRequest struct {
+	IssueUid  string `json:"issue_uid" binding:"required,uuid"` // 问题UID
+	IsDeleted int    `json:"is_deleted" binding:"oneof=0 1"`    // 是否删除(0-未删除,1-已删除)
+}
+
+// DeleteIssue 删除问题接口
+func DeleteIssue(c *gin.Context) {
+	var req DeleteIssueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidParams(c, err)
+		return
+	}
+
+	// 获取当前用户UID
+	userUid, exists := c.Get("userUid")
+	if !exists {
+		respondNotLogin(c)
+		return
+	}
+
+	// 调用服务层删除问题
+	err := service.DeleteIssue(userUid.(string), req.IssueUid, req.IsDeleted)
+	if err != nil {
+		respondError(c, "删除问题失败: ", err)
+		return
+	}
+
+	// 返回成功响应
+	respondSuccess(c, gin.H{
+		"code":    common.CodeSuccess,
+		"message": "操作成功",
+	}, nil)
+}
+
+// GetIssueListRequest 问题列表请求参数
+type GetIssueListRequest struct {
+	MoveUid string `json:"move_uid" binding:"required_without=TagUid,omitempty,uuid"` // 搬运UID
+	TagUid  string `json:"tag_uid" binding:"omitempty,uuid"`                          // 标签UID(指定时只返回该标签的问题)
+}
+
+// GetIssueList 获取问题列表接口
+func GetIssueList(c *gin.Context) {
+	var req GetIssueListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidParams(c, err)
+		return
+	}
+
+	// 获取当前用户UID
+	userUid, exists := c.Get("userUid")
+	if !exists {
+		respondNotLogin(c)
+		return
+	}
+
+	// 调用服务层获取问题列表
+	issues, err := service.GetIssueList(userUid.(string), req.MoveUid, req.TagUid)
+	if err != nil {
+		respondError(c, "获取问题列表失败: ", err)
+		return
+	}
+
+	// 返回成功响应
+	respondSuccess(c, gin.H{
+		"code":   common.CodeSuccess,
+		"issues": issues,
+	}, issues)
+}
+
+// GetLossReportRequest 丢失/损坏报告请求参数
+type GetLossReportRequest struct {
+	MoveUid string `json:"move_uid" binding:"required,uuid"` // 搬运UID
+}
+
+// GetLossReport 获取搬运丢失/损坏报告接口
+func GetLossReport(c *gin.Context) {
+	var req GetLossReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidParams(c, err)
+		return
+	}
+
+	// 获取当前用户UID
+	userUid, exists := c.Get("userUid")
+	if !exists {
+		respondNotLogin(c)
+		return
+	}
+
+	// 调用服务层生成报告
+	report, err := service.GetLossReport(userUid.(string), req.MoveUid)
+	if err != nil {
+		respondError(c, "获取丢失损坏报告失败: ", err)
+		return
+	}
+
+	// 返回成功响应
+	respondSuccess(c, gin.H{
+		"code":   common.CodeSuccess,
+		"report": report,
+	}, report)
+}
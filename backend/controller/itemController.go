@@ -0,0 +1,171 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"movingManager/common"
+	"movingManager/service"
+)
+
+// CreateItemRequest 创建物品请求参数
+type CreateItemRequest struct {
+	TagUid        string `json:"tag_uid" binding:"required,uuid"`      // 标签UID
+	ItemName      string `json:"item_name" binding:"required,item_name"` // 物品名称
+	Quantity      int    `json:"quantity" binding:"min=0,max=9999"`    // 数量(为空默认1)
+	Remark        string `json:"remark" binding:"remark"`             // 物品备注
+	DeclaredValue int64  `json:"declared_value" binding:"min=0"`       // 申报价值(分，整行合计)
+	Currency      string `json:"currency" binding:"omitempty,iso4217"` // 币种(为空默认CNY)
+}
+
+// CreateItem 创建物品接口
+func CreateItem(c *gin.Context) {
+	var req CreateItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidParams(c, err)
+		return
+	}
+
+	// 获取当前用户UID
+	userUid, exists := c.Get("userUid")
+	if !exists {
+		respondNotLogin(c)
+		return
+	}
+
+	// 调用服务层创建物品
+	item, err := service.CreateItem(userUid.(string), service.CreateItemRequest{
+		TagUid:        req.TagUid,
+		ItemName:      req.ItemName,
+		Quantity:      req.Quantity,
+		Remark:        req.Remark,
+		DeclaredValue: req.DeclaredValue,
+		Currency:      req.Currency,
+	})
+	if err != nil {
+		respondError(c, "创建物品失败: ", err)
+		return
+	}
+
+	// 返回成功响应
+	respondSuccess(c, gin.H{
+		"code":    common.CodeSuccess,
+		"message": "创建成功",
+		"item":    item,
+	}, item)
+}
+
+// UpdateItemRequest 编辑物品请求参数
+type UpdateItemRequest struct {
+	ItemUid       string `json:"item_uid" binding:"required,uuid"`     // 物品UID
+	ItemName      string `json:"item_name" binding:"required,item_name"` // 物品名称
+	Quantity      int    `json:"quantity" binding:"min=0,max=9999"`    // 数量(为空保持不变)
+	Remark        string `json:"remark" binding:"remark"`             // 物品备注
+	DeclaredValue int64  `json:"declared_value" binding:"min=0"`       // 申报价值(分，整行合计)
+	Currency      string `json:"currency" binding:"omitempty,iso4217"` // 币种(为空默认CNY)
+}
+
+// UpdateItem 编辑物品接口
+func UpdateItem(c *gin.Context) {
+	var req UpdateItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidParams(c, err)
+		return
+	}
+
+	// 获取当前用户UID
+	userUid, exists := c.Get("userUid")
+	if !exists {
+		respondNotLogin(c)
+		return
+	}
+
+	// 调用服务层更新物品
+	item, err := service.UpdateItem(userUid.(string), service.UpdateItemRequest{
+		ItemUid:       req.ItemUid,
+		ItemName:      req.ItemName,
+		Quantity:      req.Quantity,
+		Remark:        req.Remark,
+		DeclaredValue: req.DeclaredValue,
+		Currency:      req.Currency,
+	})
+	if err != nil {
+		respondError(c, "更新物品失败: ", err)
+		return
+	}
+
+	// 返回成功响应
+	respondSuccess(c, gin.H{
+		"code":    common.CodeSuccess,
+		"message": "操作成功",
+		"item":    item,
+	}, item)
+}
+
+// DeleteItemRequest 删除物品请求参数
+type DeleteItemRequest struct {
+	ItemUid   string `json:"item_uid" binding:"required,uuid"` // 物品UID
+	IsDeleted int    `json:"is_deleted" binding:"oneof=0 1"`   // 是否删除(0-未删除,1-已删除)
+}
+
+// DeleteItem 删除物品接口
+func DeleteItem(c *gin.Context) {
+	var req DeleteItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidParams(c, err)
+		return
+	}
+
+	// 获取当前用户UID
+	userUid, exists := c.Get("userUid")
+	if !exists {
+		respondNotLogin(c)
+		return
+	}
+
+	// 调用服务层删除物品
+	err := service.DeleteItem(userUid.(string), req.ItemUid, req.IsDeleted)
+	if err != nil {
+		respondError(c, "删除物品失败: ", err)
+		return
+	}
+
+	// 返回成功响应
+	respondSuccess(c, gin.H{
+		"code":    common.CodeSuccess,
+		"message": "操作成功",
+	}, nil)
+}
+
+// GetItemListRequest 物品列表请求参数
+type GetItemListRequest struct {
+	TagUid string `json:"tag_uid" binding:"required,uuid"` // 标签UID
+}
+
+// GetItemList 获取标签下的物品列表接口
+func GetItemList(c *gin.Context) {
+	var req GetItemListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidParams(c, err)
+		return
+	}
+
+	// 获取当前用户UID
+	userUid, exists := c.Get("userUid")
+	if !exists {
+		respondNotLogin(c)
+		return
+	}
+
+	// 调用服务层获取物品列表
+	items, err := service.GetItemList(userUid.(string), req.TagUid)
+	if err != nil {
+		respondError(c, "获取物品列表失败: ", err)
+		return
+	}
+
+	// 返回成功响应
+	respondSuccess(c, gin.H{
+		"code":  common.CodeSuccess,
+		"items": items,
+	}, items)
+}
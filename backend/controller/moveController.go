@@ -1,12 +1,12 @@
 package controller
 
 import (
-	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"movingManager/common"
+	"movingManager/dto"
 	"movingManager/model"
 	"movingManager/service"
 )
@@ -17,27 +17,21 @@ type CreateMoveRequest struct {
 	MoveAt        int64 `json:"move_at" binding:"required"` // 搬运时间戳(Unix时间)
 	StartLocation string `json:"start_location" binding:"required,max=100"`      // 出发地
 	EndLocation   string `json:"end_location" binding:"required,max=100"`        // 目的地
-	Remark        string `json:"remark" binding:"max=500"`                       // 备注
+	Remark        string `json:"remark" binding:"remark"`                       // 备注
 }
 
 // CreateMove 创建搬运接口
 func CreateMove(c *gin.Context) {
 	var req CreateMoveRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusOK, gin.H{
-			"code":    400,
-			"message": "请求参数错误: " + err.Error(),
-		})
+		respondInvalidParams(c, err)
 		return
 	}
 
 	// 获取当前用户UID
 	userUid, exists := c.Get("userUid")
 	if !exists {
-		c.JSON(http.StatusOK, gin.H{
-			"code":    common.CodeUserNotLogin,
-			"message": common.CodeMessage[common.CodeUserNotLogin],
-		})
+		respondNotLogin(c)
 		return
 	}
 
@@ -50,10 +44,7 @@ func CreateMove(c *gin.Context) {
 	// 调用服务层创建搬运
 	move, err := service.CreateMove(userUid.(string), moveTime, startLocation, endLocation, remark)
 	if err != nil {
-		c.JSON(http.StatusOK, gin.H{
-			"code":    500,
-			"message": "创建搬运失败: " + err.Error(),
-		})
+		respondError(c, "创建搬运失败: ", err)
 		return
 	}
 
@@ -67,6 +58,7 @@ func CreateMove(c *gin.Context) {
 		"verified_tag_count":   move.VerifiedTagCount,
 		"unverified_tag_count": move.UnverifiedTagCount,
 		"is_completed":         move.IsCompleted,
+		"allow_anonymous_verify": move.AllowAnonymousVerify,
 		"is_deleted":           move.IsDeleted,
 		"remark":               move.Remark,
 		"created_at":           time.Unix(move.CreatedAt, 0).Format("2006-01-02 15:04:05"),
@@ -81,11 +73,11 @@ func CreateMove(c *gin.Context) {
 	}
 
 	// 返回成功响应
-	c.JSON(http.StatusOK, gin.H{
+	respondSuccess(c, gin.H{
 		"code":    common.CodeSuccess,
 		"message": "创建成功",
 		"move":    response,
-	})
+	}, dto.NewMove(move))
 }
 
 // GetMoveDetailRequest 类型使用dto包中的定义
@@ -98,20 +90,14 @@ type GetMoveDetailRequest struct {
 func GetMoveDetail(c *gin.Context) {
 	var req GetMoveDetailRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusOK, gin.H{
-			"code":    400,
-			"message": "请求参数错误: " + err.Error(),
-		})
+		respondInvalidParams(c, err)
 		return
 	}
 
 	// 获取当前用户UID
 	userUid, exists := c.Get("userUid")
 	if !exists {
-		c.JSON(http.StatusOK, gin.H{
-			"code":    common.CodeUserNotLogin,
-			"message": common.CodeMessage[common.CodeUserNotLogin],
-		})
+		respondNotLogin(c)
 		return
 	}
 
@@ -121,17 +107,7 @@ func GetMoveDetail(c *gin.Context) {
 	// 调用服务层获取搬运详情
 	modelMove, err := service.GetMoveDetail(userUid.(string), moveUid, true)
 	if err != nil {
-		if err.Error() == "用户无此搬运记录" {
-			c.JSON(http.StatusOK, gin.H{
-				"code":    common.CodeMoveNotFound,
-				"message": common.CodeMessage[common.CodeMoveNotFound],
-			})
-			return
-		}
-		c.JSON(http.StatusOK, gin.H{
-			"code":    500,
-			"message": "获取搬运详情失败: " + err.Error(),
-		})
+		respondError(c, "获取搬运详情失败: ", err)
 		return
 	}
 
@@ -145,6 +121,7 @@ func GetMoveDetail(c *gin.Context) {
 		"verified_tag_count":   modelMove.VerifiedTagCount,
 		"unverified_tag_count": modelMove.UnverifiedTagCount,
 		"is_completed":         modelMove.IsCompleted,
+		"allow_anonymous_verify": modelMove.AllowAnonymousVerify,
 		"is_deleted":           modelMove.IsDeleted,
 		"remark":               modelMove.Remark,
 		"created_at":           time.Unix(modelMove.CreatedAt, 0).Format("2006-01-02 15:04:05"),
@@ -158,11 +135,21 @@ func GetMoveDetail(c *gin.Context) {
 		response["delete_time"] = modelMove.DeletedAt
 	}
 
+	// 按币种汇总的申报价值
+	declaredTotals, err := service.GetMoveDeclaredTotals(userUid.(string), moveUid)
+	if err != nil {
+		respondError(c, "获取搬运详情失败: ", err)
+		return
+	}
+	response["declared_totals"] = declaredTotals
+	moveDetail := dto.NewMove(modelMove)
+	moveDetail.DeclaredTotals = declaredTotals
+
 	// 返回成功响应
-	c.JSON(http.StatusOK, gin.H{
+	respondSuccess(c, gin.H{
 		"code": common.CodeSuccess,
 		"move": response,
-	})
+	}, moveDetail)
 }
 
 // UpdateMoveRequest 类型使用dto包中的定义
@@ -172,28 +159,23 @@ type UpdateMoveRequest struct {
 	MoveAt        int64 `json:"move_at" binding:"required"` // 搬运时间戳(Unix时间)
 	StartLocation string `json:"start_location" binding:"required,max=100"`      // 出发地
 	EndLocation   string `json:"end_location" binding:"required,max=100"`        // 目的地
-	Remark        string `json:"remark" binding:"max=500"`                       // 备注
+	Remark        string `json:"remark" binding:"remark"`                       // 备注
 	IsCompleted   int    `json:"is_completed" binding:"oneof=0 1"`               // 是否完成(0-未完成,1-已完成)
+	AllowAnonymousVerify *int `json:"allow_anonymous_verify" binding:"omitempty,oneof=0 1"` // 是否允许免登录扫码核销(0-不允许,1-允许，不传保持不变)
 }
 
 // UpdateMove 编辑搬运接口
 func UpdateMove(c *gin.Context) {
 	var req UpdateMoveRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusOK, gin.H{
-			"code":    400,
-			"message": "请求参数错误: " + err.Error(),
-		})
+		respondInvalidParams(c, err)
 		return
 	}
 
 	// 获取当前用户UID
 	userUid, exists := c.Get("userUid")
 	if !exists {
-		c.JSON(http.StatusOK, gin.H{
-			"code":    common.CodeUserNotLogin,
-			"message": common.CodeMessage[common.CodeUserNotLogin],
-		})
+		respondNotLogin(c)
 		return
 	}
 
@@ -214,21 +196,12 @@ func UpdateMove(c *gin.Context) {
 		EndLocation:   endLocation,
 		Remark:        remark,
 		IsCompleted:   isCompleted,
+		AllowAnonymousVerify: req.AllowAnonymousVerify,
 	}
 	// 调用服务层更新搬运
 	updatedMove, err := service.UpdateMove(userUid.(string), updateReq)
 	if err != nil {
-		if err.Error() == "用户无此搬运记录" {
-			c.JSON(http.StatusOK, gin.H{
-				"code":    common.CodeMoveNotFound,
-				"message": common.CodeMessage[common.CodeMoveNotFound],
-			})
-			return
-		}
-		c.JSON(http.StatusOK, gin.H{
-			"code":    500,
-			"message": "更新搬运失败: " + err.Error(),
-		})
+		respondError(c, "更新搬运失败: ", err)
 		return
 	}
 
@@ -242,6 +215,7 @@ func UpdateMove(c *gin.Context) {
 		"verified_tag_count":   updatedMove.VerifiedTagCount,
 		"unverified_tag_count": updatedMove.UnverifiedTagCount,
 		"is_completed":         updatedMove.IsCompleted,
+		"allow_anonymous_verify": updatedMove.AllowAnonymousVerify,
 		"is_deleted":           updatedMove.IsDeleted,
 		"remark":               updatedMove.Remark,
 		"created_at":           time.Unix(updatedMove.CreatedAt, 0).Format("2006-01-02 15:04:05"),
@@ -256,10 +230,10 @@ func UpdateMove(c *gin.Context) {
 	}
 
 	// 返回成功响应
-	c.JSON(http.StatusOK, gin.H{
+	respondSuccess(c, gin.H{
 		"code": common.CodeSuccess,
 		"move": response,
-	})
+	}, dto.NewMove(updatedMove))
 }
 
 // DeleteMoveRequest 类型使用dto包中的定义
@@ -273,20 +247,14 @@ type DeleteMoveRequest struct {
 func DeleteMove(c *gin.Context) {
 	var req DeleteMoveRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusOK, gin.H{
-			"code":    400,
-			"message": "请求参数错误: " + err.Error(),
-		})
+		respondInvalidParams(c, err)
 		return
 	}
 
 	// 获取当前用户UID
 	userUid, exists := c.Get("userUid")
 	if !exists {
-		c.JSON(http.StatusOK, gin.H{
-			"code":    common.CodeUserNotLogin,
-			"message": common.CodeMessage[common.CodeUserNotLogin],
-		})
+		respondNotLogin(c)
 		return
 	}
 
@@ -297,17 +265,7 @@ func DeleteMove(c *gin.Context) {
 	// 调用服务层删除搬运
 	err := service.DeleteMove(userUid.(string), moveUid, isDeleted)
 	if err != nil {
-		if err.Error() == "用户无此搬运记录" {
-			c.JSON(http.StatusOK, gin.H{
-				"code":    common.CodeMoveNotFound,
-				"message": common.CodeMessage[common.CodeMoveNotFound],
-			})
-			return
-		}
-		c.JSON(http.StatusOK, gin.H{
-			"code":    500,
-			"message": "删除搬运失败: " + err.Error(),
-		})
+		respondError(c, "删除搬运失败: ", err)
 		return
 	}
 
@@ -319,11 +277,11 @@ func DeleteMove(c *gin.Context) {
 	}
 
 	// 返回成功响应
-	c.JSON(http.StatusOK, gin.H{
+	respondSuccess(c, gin.H{
 		"code":    common.CodeSuccess,
 		"message": "操作成功",
 		"result":  response,
-	})
+	}, nil)
 }
 
 // GetMoveListRequest 类型使用dto包中的定义
@@ -337,20 +295,14 @@ type GetMoveListRequest struct {
 func GetMoveList(c *gin.Context) {
 	var req GetMoveListRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusOK, gin.H{
-			"code":    400,
-			"message": "请求参数错误: " + err.Error(),
-		})
+		respondInvalidParams(c, err)
 		return
 	}
 
 	// 获取当前用户UID
 	userUid, exists := c.Get("userUid")
 	if !exists {
-		c.JSON(http.StatusOK, gin.H{
-			"code":    common.CodeUserNotLogin,
-			"message": common.CodeMessage[common.CodeUserNotLogin],
-		})
+		respondNotLogin(c)
 		return
 	}
 
@@ -365,10 +317,7 @@ func GetMoveList(c *gin.Context) {
 	}
 	// 删除未使用的变量声明
 	if err != nil {
-		c.JSON(http.StatusOK, gin.H{
-			"code":    500,
-			"message": "获取搬运列表失败: " + err.Error(),
-		})
+		respondError(c, "获取搬运列表失败: ", err)
 		return
 	}
 
@@ -384,6 +333,7 @@ func GetMoveList(c *gin.Context) {
 			"verified_tag_count":   move.VerifiedTagCount,
 			"unverified_tag_count": move.UnverifiedTagCount,
 			"is_completed":         move.IsCompleted,
+			"allow_anonymous_verify": move.AllowAnonymousVerify,
 			"is_deleted":           move.IsDeleted,
 			"remark":               move.Remark,
 			"created_at":           time.Unix(move.CreatedAt, 0).Format("2006-01-02 15:04:05"),
@@ -404,7 +354,7 @@ func GetMoveList(c *gin.Context) {
 	totalPages := (total + int64(pageSize) - 1) / int64(pageSize)
 
 	// 返回成功响应
-	c.JSON(http.StatusOK, gin.H{
+	respondPage(c, gin.H{
 		"code":     common.CodeSuccess,
 		"message":  "获取成功",
 		"moveList": responseList,
@@ -414,5 +364,5 @@ func GetMoveList(c *gin.Context) {
 			"pageSize":   pageSize,
 			"totalPages": totalPages,
 		},
-	})
+	}, dto.NewMoveList(moves), dto.NewPagination(page, pageSize, total))
 }
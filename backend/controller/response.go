@@ -0,0 +1,25 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"movingManager/dto"
+)
+
+// respondSuccess 返回成功响应
+// v1接口输出原有格式legacy；v2接口输出统一响应信封，data为类型化的响应数据
+func respondSuccess(c *gin.Context, legacy gin.H, data interface{}) {
+	respondPage(c, legacy, data, nil)
+}
+
+// respondPage 返回带分页信息的成功响应
+func respondPage(c *gin.Context, legacy gin.H, data interface{}, pagination *dto.Pagination) {
+	if !dto.IsV2(c) {
+		c.JSON(http.StatusOK, legacy)
+		return
+	}
+	message, _ := legacy["message"].(string)
+	dto.WriteData(c, message, data, pagination)
+}
@@ -0,0 +1,166 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"movingManager/common"
+	"movingManager/service"
+)
+
+// CreateRoomRequest 创建房间请求参数
+type CreateRoomRequest struct {
+	MoveUid  string `json:"move_uid" binding:"required,uuid"`    // 搬运UID
+	Side     int    `json:"side" binding:"required,oneof=1 2"`   // 所在位置(1-出发地,2-目的地)
+	RoomName string `json:"room_name" binding:"required,room_name"` // 房间名称
+	Color    string `json:"color" binding:"omitempty,hexcolor"`  // 标识颜色(#RRGGBB)
+	Sort     int    `json:"sort" binding:"min=0"`                // 排序值
+}
+
+// CreateRoom 创建房间接口
+func CreateRoom(c *gin.Context) {
+	var req CreateRoomRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidParams(c, err)
+		return
+	}
+
+	// 获取当前用户UID
+	userUid, exists := c.Get("userUid")
+	if !exists {
+		respondNotLogin(c)
+		return
+	}
+
+	// 调用服务层创建房间
+	room, err := service.CreateRoom(userUid.(string), service.CreateRoomRequest{
+		MoveUid:  req.MoveUid,
+		Side:     req.Side,
+		RoomName: req.RoomName,
+		Color:    req.Color,
+		Sort:     req.Sort,
+	})
+	if err != nil {
+		respondError(c, "创建房间失败: ", err)
+		return
+	}
+
+	// 返回成功响应
+	respondSuccess(c, gin.H{
+		"code":    common.CodeSuccess,
+		"message": "创建成功",
+		"room":    room,
+	}, room)
+}
+
+// UpdateRoomRequest 编辑房间请求参数
+type UpdateRoomRequest struct {
+	RoomUid  string `json:"room_uid" binding:"required,uuid"`    // 房间UID
+	RoomName string `json:"room_name" binding:"required,room_name"` // 房间名称
+	Color    string `json:"color" binding:"omitempty,hexcolor"`  // 标识颜色(#RRGGBB)
+	Sort     int    `json:"sort" binding:"min=0"`                // 排序值
+}
+
+// UpdateRoom 编辑房间接口
+func UpdateRoom(c *gin.Context) {
+	var req UpdateRoomRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidParams(c, err)
+		return
+	}
+
+	// 获取当前用户UID
+	userUid, exists := c.Get("userUid")
+	if !exists {
+		respondNotLogin(c)
+		return
+	}
+
+	// 调用服务层更新房间
+	room, err := service.UpdateRoom(userUid.(string), service.UpdateRoomRequest{
+		RoomUid:  req.RoomUid,
+		RoomName: req.RoomName,
+		Color:    req.Color,
+		Sort:     req.Sort,
+	})
+	if err != nil {
+		respondError(c, "更新房间失败: ", err)
+		return
+	}
+
+	// 返回成功响应
+	respondSuccess(c, gin.H{
+		"code":    common.CodeSuccess,
+		"message": "操作成功",
+		"room":    room,
+	}, room)
+}
+
+// DeleteRoomRequest 删除房间请求参数
+type DeleteRoomRequest struct {
+	RoomUid   string `json:"room_uid" binding:"required,uuid"` // 房间UID
+	IsDeleted int    `json:"is_deleted" binding:"oneof=0 1"`   // 是否删除(0-未删除,1-已删除)
+}
+
+// DeleteRoom 删除房间接口
+func DeleteRoom(c *gin.Context) {
+	var req DeleteRoomRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidParams(c, err)
+		return
+	}
+
+	// 获取当前用户UID
+	userUid, exists := c.Get("userUid")
+	if !exists {
+		respondNotLogin(c)
+		return
+	}
+
+	// 调用服务层删除房间
+	err := service.DeleteRoom(userUid.(string), req.RoomUid, req.IsDeleted)
+	if err != nil {
+		respondError(c, "删除房间失败: ", err)
+		return
+	}
+
+	// 返回成功响应
+	respondSuccess(c, gin.H{
+		"code":    common.CodeSuccess,
+		"message": "操作成功",
+	}, nil)
+}
+
+// GetRoomListRequest 房间列表请求参数
+type GetRoomListRequest struct {
+	MoveUid string `json:"move_uid" binding:"required,uuid"`   // 搬运UID
+	Side    int    `json:"side" binding:"omitempty,oneof=1 2"` // 所在位置(为空返回全部)
+}
+
+// GetRoomList 获取房间列表接口
+func GetRoomList(c *gin.Context) {
+	var req GetRoomListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidParams(c, err)
+		return
+	}
+
+	// 获取当前用户UID
+	userUid, exists := c.Get("userUid")
+	if !exists {
+		respondNotLogin(c)
+		return
+	}
+
+	// 调用服务层获取房间列表
+	rooms, err := service.GetRoomList(userUid.(string), req.MoveUid, req.Side)
+	if err != nil {
+		respondError(c, "获取房间列表失败: ", err)
+		return
+	}
+
+	// 返回成功响应
+	respondSuccess(c, gin.H{
+		"code":  common.CodeSuccess,
+		"rooms": rooms,
+	}, rooms)
+}
@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"movingManager/common"
+	"movingManager/service"
+)
+
+// ScanDetailRequest 免登录扫码查看标签请求参数
+type ScanDetailRequest struct {
+	Token string `json:"token" binding:"required,max=512"` // 扫码令牌
+}
+
+// GetScanDetail 免登录扫码查看标签接口
+func GetScanDetail(c *gin.Context) {
+	var req ScanDetailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidParams(c, err)
+		return
+	}
+
+	tag, err := service.GetPublicTagInfo(req.Token)
+	if err != nil {
+		respondError(c, "获取标签信息失败: ", err)
+		return
+	}
+
+	// 返回成功响应
+	respondSuccess(c, gin.H{
+		"code": common.CodeSuccess,
+		"tag":  tag,
+	}, tag)
+}
+
+// ScanVerifyRequest 免登录扫码核销标签请求参数
+type ScanVerifyRequest struct {
+	Token       string `json:"token" binding:"required,max=512"`       // 扫码令牌
+	ScannerName string `json:"scanner_name" binding:"required,max=50"` // 核销人姓名
+}
+
+// ScanVerifyTag 免登录扫码核销标签接口
+func ScanVerifyTag(c *gin.Context) {
+	var req ScanVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidParams(c, err)
+		return
+	}
+
+	tag, err := service.PublicVerifyTag(req.Token, req.ScannerName)
+	if err != nil {
+		respondError(c, "核销标签失败: ", err)
+		return
+	}
+
+	// 返回成功响应
+	respondSuccess(c, gin.H{
+		"code":    common.CodeSuccess,
+		"message": "核销成功",
+		"tag":     tag,
+	}, tag)
+}
+
+// TagScanLinkRequest 获取标签扫码链接请求参数
+type TagScanLinkRequest struct {
+	TagUid string `json:"tag_uid" binding:"required,uuid"` // 标签UID
+}
+
+// GetTagScanLink 获取标签扫码链接接口
+func GetTagScanLink(c *gin.Context) {
+	var req TagScanLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidParams(c, err)
+		return
+	}
+
+	// 获取当前用户UID
+	userUid, exists := c.Get("userUid")
+	if !exists {
+		respondNotLogin(c)
+		return
+	}
+
+	link, err := service.GetTagScanLink(userUid.(string), req.TagUid)
+	if err != nil {
+		respondError(c, "获取扫码链接失败: ", err)
+		return
+	}
+
+	// 返回成功响应
+	respondSuccess(c, gin.H{
+		"code": common.CodeSuccess,
+		"link": link,
+	}, link)
+}
@@ -6,69 +6,76 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"movingManager/common"
+	"movingManager/dto"
 	"movingManager/service"
 )
 
 // CreateTagRequest 创建标签请求参数
 type CreateTagRequest struct {
-	MoveUid string `json:"move_uid" binding:"required,uuid"`    // 搬运UID
-	TagName string `json:"tag_name" binding:"required,max=100"` // 标签名称
-	Remark  string `json:"remark" binding:"max=500"`            // 标签备注
-	Status  int    `json:"status" binding:"omitempty,oneof=0 1 2"` // 标签状态(0-正常,1-锁定,2-已完成)
+	MoveUid       string `json:"move_uid" binding:"required,uuid"`         // 搬运UID
+	TagName       string `json:"tag_name" binding:"required,tag_name"`     // 标签名称
+	Remark        string `json:"remark" binding:"remark"`                  // 标签备注
+	Status        int    `json:"status" binding:"omitempty,oneof=0 1 2"`   // 标签状态(0-正常,1-锁定,2-已完成)
+	OriginRoomUid string `json:"origin_room_uid" binding:"omitempty,uuid"` // 出发地房间UID
+	DestRoomUid   string `json:"dest_room_uid" binding:"omitempty,uuid"`   // 目的地房间UID
+	ParentTagUid  string `json:"parent_tag_uid" binding:"omitempty,uuid"`  // 外层容器标签UID
+	DeclaredValue int64  `json:"declared_value" binding:"min=0"`           // 申报价值(分)
+	Currency      string `json:"currency" binding:"omitempty,iso4217"`     // 币种(为空默认CNY)
 }
 
 // CreateTag 创建标签接口
 func CreateTag(c *gin.Context) {
 	var req CreateTagRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusOK, gin.H{
-			"code":    400,
-			"message": "请求参数错误: " + err.Error(),
-		})
+		respondInvalidParams(c, err)
 		return
 	}
 
 	// 获取当前用户UID
 	userUid, exists := c.Get("userUid")
 	if !exists {
-		c.JSON(http.StatusOK, gin.H{
-			"code":    common.CodeUserNotLogin,
-			"message": common.CodeMessage[common.CodeUserNotLogin],
-		})
+		respondNotLogin(c)
 		return
 	}
 
 	// 转换为服务层请求结构体
 	serviceReq := service.CreateTagRequest{
-		MoveUid: req.MoveUid,
-		TagName: req.TagName,
-		Remark:  req.Remark,
+		MoveUid:       req.MoveUid,
+		TagName:       req.TagName,
+		Remark:        req.Remark,
+		OriginRoomUid: req.OriginRoomUid,
+		DestRoomUid:   req.DestRoomUid,
+		ParentTagUid:  req.ParentTagUid,
+		DeclaredValue: req.DeclaredValue,
+		Currency:      req.Currency,
 	}
 	// 调用服务层创建标签
 	tag, err := service.CreateTag(userUid.(string), serviceReq)
 	if err != nil {
-		c.JSON(http.StatusOK, gin.H{
-			"code":    500,
-			"message": "创建标签失败: " + err.Error(),
-		})
+		respondError(c, "创建标签失败: ", err)
 		return
 	}
 
 	// 返回成功响应
-	c.JSON(http.StatusOK, gin.H{
+	respondSuccess(c, gin.H{
 		"code":    common.CodeSuccess,
 		"message": "创建成功",
 		"tag":      tag,
-	})
+	}, dto.NewTag(tag))
 }
 
 // UpdateTagRequest 编辑标签请求参数
 type UpdateTagRequest struct {
-	TagUid     string `json:"tag_uid" binding:"required,uuid"`     // 标签UID
-	TagName    string `json:"tag_name" binding:"required,max=100"` // 标签名称
-	Remark     string `json:"remark" binding:"max=500"`            // 标签备注
-	IsVerified int    `json:"is_verified" binding:"oneof=0 1"`     // 是否核销(0-未核销,1-已核销)
-	Status     int    `json:"status" binding:"omitempty,oneof=0 1 2"` // 标签状态(0-正常,1-锁定,2-已完成)
+	TagUid        string  `json:"tag_uid" binding:"required,uuid"`                // 标签UID
+	TagName       string  `json:"tag_name" binding:"required,tag_name"`           // 标签名称
+	Remark        string  `json:"remark" binding:"remark"`                        // 标签备注
+	IsVerified    int     `json:"is_verified" binding:"oneof=0 1"`                // 是否核销(0-未核销,1-已核销)
+	Status        int     `json:"status" binding:"omitempty,oneof=0 1 2"`         // 标签状态(0-正常,1-锁定,2-已完成)
+	OriginRoomUid *string `json:"origin_room_uid" binding:"omitempty,len=0|uuid"` // 出发地房间UID(不传则不修改，传空字符串取消分配)
+	DestRoomUid   *string `json:"dest_room_uid" binding:"omitempty,len=0|uuid"`   // 目的地房间UID(不传则不修改，传空字符串取消分配)
+	ParentTagUid  *string `json:"parent_tag_uid" binding:"omitempty,len=0|uuid"`  // 外层容器标签UID(不传则不修改，传空字符串取出到顶层)
+	DeclaredValue *int64  `json:"declared_value" binding:"omitempty,min=0"`       // 申报价值(分)(不传则不修改)
+	Currency      *string `json:"currency" binding:"omitempty,len=0|iso4217"`     // 币种(不传则不修改，为空默认CNY)
 }
 
 // UpdateTag 编辑标签接口
@@ -81,57 +88,42 @@ type UpdateTagRequest struct {
  func GetTagDetail(c *gin.Context) {
 	var req GetTagDetailRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusOK, gin.H{
-			"code":    400,
-			"message": "请求参数错误: " + err.Error(),
-		})
+		respondInvalidParams(c, err)
 		return
 	}
 
 	// 获取当前用户UID
 	userUid, exists := c.Get("userUid")
 	if !exists {
-		c.JSON(http.StatusOK, gin.H{
-			"code":    common.CodeUserNotLogin,
-			"message": common.CodeMessage[common.CodeUserNotLogin],
-		})
+		respondNotLogin(c)
 		return
 	}
 
 	// 调用服务层获取标签详情
 	tag, err := service.GetTagDetail(userUid.(string), req.TagUid)
 	if err != nil {
-		c.JSON(http.StatusOK, gin.H{
-			"code":    500,
-			"message": "获取标签详情失败: " + err.Error(),
-		})
+		respondError(c, "获取标签详情失败: ", err)
 		return
 	}
 
 	// 返回成功响应
-	c.JSON(http.StatusOK, gin.H{
+	respondSuccess(c, gin.H{
 		"code":    common.CodeSuccess,
 		"message": "获取成功",
 		"tag":  tag,
-	})
+	}, dto.NewTag(tag))
 }
 func UpdateTag(c *gin.Context) {
 	var req UpdateTagRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusOK, gin.H{
-			"code":    400,
-			"message": "请求参数错误: " + err.Error(),
-		})
+		respondInvalidParams(c, err)
 		return
 	}
 
 	// 获取当前用户UID
 	userUid, exists := c.Get("userUid")
 	if !exists {
-		c.JSON(http.StatusOK, gin.H{
-			"code":    common.CodeUserNotLogin,
-			"message": common.CodeMessage[common.CodeUserNotLogin],
-		})
+		respondNotLogin(c)
 		return
 	}
 
@@ -141,28 +133,23 @@ func UpdateTag(c *gin.Context) {
 		TagName:    req.TagName,
 		Remark:     req.Remark,
 		IsVerified: req.IsVerified,
+		OriginRoomUid: req.OriginRoomUid,
+		DestRoomUid:   req.DestRoomUid,
+		ParentTagUid:  req.ParentTagUid,
+		DeclaredValue: req.DeclaredValue,
+		Currency:      req.Currency,
 	})
 	if err != nil {
-		if err.Error() == "用户无此标签" {
-			c.JSON(http.StatusOK, gin.H{
-				"code":    common.CodeTagNotFound,
-				"message": common.CodeMessage[common.CodeTagNotFound],
-			})
-			return
-		}
-		c.JSON(http.StatusOK, gin.H{
-			"code":    500,
-			"message": "更新标签失败: " + err.Error(),
-		})
+		respondError(c, "更新标签失败: ", err)
 		return
 	}
 
 	// 返回成功响应
-	c.JSON(http.StatusOK, gin.H{
+	respondSuccess(c, gin.H{
 		"code":    common.CodeSuccess,
 		"message": "创建成功",
 		"tag":      tag,
-	})
+	}, dto.NewTag(tag))
 }
 
 // DeleteTagRequest 删除标签请求参数
@@ -175,96 +162,66 @@ type DeleteTagRequest struct {
 func DeleteTag(c *gin.Context) {
 	var req DeleteTagRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusOK, gin.H{
-			"code":    400,
-			"message": "请求参数错误: " + err.Error(),
-		})
+		respondInvalidParams(c, err)
 		return
 	}
 
 	// 获取当前用户UID
 	userUid, exists := c.Get("userUid")
 	if !exists {
-		c.JSON(http.StatusOK, gin.H{
-			"code":    common.CodeUserNotLogin,
-			"message": common.CodeMessage[common.CodeUserNotLogin],
-		})
+		respondNotLogin(c)
 		return
 	}
 
 	// 调用服务层删除标签
 	err := service.DeleteTag(userUid.(string), req.TagUid, req.IsDeleted)
 	if err != nil {
-		if err.Error() == "用户无此标签记录" {
-			c.JSON(http.StatusOK, gin.H{
-				"code":    common.CodeTagNotFound,
-				"message": common.CodeMessage[common.CodeTagNotFound],
-			})
-			return
-		}
-		c.JSON(http.StatusOK, gin.H{
-			"code":    500,
-			"message": "删除标签失败: " + err.Error(),
-		})
+		respondError(c, "删除标签失败: ", err)
 		return
 	}
 
 	// 返回成功响应
-	c.JSON(http.StatusOK, gin.H{
+	respondSuccess(c, gin.H{
 		"code":    common.CodeSuccess,
 		"message": "操作成功",
-	})
+	}, nil)
 }
 
 // VerifyTagRequest 核销标签请求参数
 type VerifyTagRequest struct {
 	TagUid     string `json:"tag_uid" binding:"required,uuid"` // 标签UID
 	IsVerified int    `json:"is_verified" binding:"oneof=0 1"` // 是否核销(0-未核销,1-已核销)
+	Cascade    bool   `json:"cascade"`                         // 是否同时核销容器内的全部标签
 }
 
 // VerifyTag 核销标签接口
 func VerifyTag(c *gin.Context) {
 	var req VerifyTagRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusOK, gin.H{
-			"code":    400,
-			"message": "请求参数错误: " + err.Error(),
-		})
+		respondInvalidParams(c, err)
 		return
 	}
 
 	// 获取当前用户UID
 	userUid, exists := c.Get("userUid")
 	if !exists {
-		c.JSON(http.StatusOK, gin.H{
-			"code":    common.CodeUserNotLogin,
-			"message": common.CodeMessage[common.CodeUserNotLogin],
-		})
+		respondNotLogin(c)
 		return
 	}
 
 	// 调用服务层核销标签
-	err := service.VerifyTag(userUid.(string), req.TagUid, req.IsVerified)
+	changedCount, err := service.VerifyTag(userUid.(string), req.TagUid, req.IsVerified, req.Cascade)
 	if err != nil {
-		if err.Error() == "用户无此标签记录" {
-			c.JSON(http.StatusOK, gin.H{
-				"code":    common.CodeTagNotFound,
-				"message": common.CodeMessage[common.CodeTagNotFound],
-			})
-			return
-		}
-		c.JSON(http.StatusOK, gin.H{
-			"code":    500,
-			"message": "核销标签失败: " + err.Error(),
-		})
+		respondError(c, "核销标签失败: ", err)
 		return
 	}
 
 	// 返回成功响应
-	c.JSON(http.StatusOK, gin.H{
-		"code":    common.CodeSuccess,
-		"message": "操作成功",
-	})
+	respondSuccess(c, gin.H{
+		"code":          common.CodeSuccess,
+		"message":       "操作成功",
+		"changed_count": changedCount,
+	}, dto.VerifyResult{ChangedCount: changedCount})
 }
 
 // GetTagListRequest 标签列表请求参数
@@ -278,30 +235,21 @@ type GetTagListRequest struct {
 func GetTagList(c *gin.Context) {
 	var req GetTagListRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusOK, gin.H{
-			"code":    400,
-			"message": "请求参数错误: " + err.Error(),
-		})
+		respondInvalidParams(c, err)
 		return
 	}
 
 	// 获取当前用户UID
 	userUid, exists := c.Get("userUid")
 	if !exists {
-		c.JSON(http.StatusOK, gin.H{
-			"code":    common.CodeUserNotLogin,
-			"message": common.CodeMessage[common.CodeUserNotLogin],
-		})
+		respondNotLogin(c)
 		return
 	}
 
 	// 调用服务层获取标签列表
 	tags, total, err := service.GetTagList(userUid.(string), req.MoveUid, req.Page, req.PageSize)
 	if err != nil {
-		c.JSON(http.StatusOK, gin.H{
-			"code":    500,
-			"message": "获取标签列表失败: " + err.Error(),
-		})
+		respondError(c, "获取标签列表失败: ", err)
 		return
 	}
 
@@ -309,7 +257,7 @@ func GetTagList(c *gin.Context) {
 	totalPages := (total + int64(req.PageSize) - 1) / int64(req.PageSize)
 
 	// 返回成功响应
-	c.JSON(http.StatusOK, gin.H{
+	respondPage(c, gin.H{
 		"code": common.CodeSuccess,
 		"tags": tags,
 		"pagination": gin.H{
@@ -318,7 +266,83 @@ func GetTagList(c *gin.Context) {
 			"pageSize":   req.PageSize,
 			"totalPages": totalPages,
 		},
-	})
+	}, dto.NewTagList(tags), dto.NewPagination(req.Page, req.PageSize, total))
+}
+
+// GetTagListByRoomRequest 按房间分组的标签列表请求参数
+type GetTagListByRoomRequest struct {
+	MoveUid string `json:"move_uid" binding:"required,uuid"` // 搬运UID
+}
+
+// GetTagListByRoom 按目的地房间分组获取标签列表接口
+func GetTagListByRoom(c *gin.Context) {
+	var req GetTagListByRoomRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidParams(c, err)
+		return
+	}
+
+	// 获取当前用户UID
+	userUid, exists := c.Get("userUid")
+	if !exists {
+		respondNotLogin(c)
+		return
+	}
+
+	// 调用服务层获取分组标签列表
+	groups, err := service.GetTagListByRoom(userUid.(string), req.MoveUid)
+	if err != nil {
+		respondError(c, "获取标签列表失败: ", err)
+		return
+	}
+
+	// 返回成功响应
+	respondSuccess(c, gin.H{
+		"code":   common.CodeSuccess,
+		"groups": groups,
+	}, dto.NewTagRoomGroups(groups))
+}
+
+// GenerateInsuranceReportRequest 生成保险申报清单请求参数
+type GenerateInsuranceReportRequest struct {
+	MoveUid string `json:"move_uid" binding:"required,uuid"`     // 搬运UID
+	Format  string `json:"format" binding:"omitempty,oneof=pdf csv"` // 报告格式(pdf,csv，默认pdf)
+}
+
+// GenerateInsuranceReport 生成保险申报清单接口
+func GenerateInsuranceReport(c *gin.Context) {
+	var req GenerateInsuranceReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidParams(c, err)
+		return
+	}
+
+	// 获取当前用户UID
+	userUid, exists := c.Get("userUid")
+	if !exists {
+		respondNotLogin(c)
+		return
+	}
+
+	format := req.Format
+	if format == "" {
+		format = service.ReportFormatPDF
+	}
+
+	// 调用服务层生成报告
+	data, err := service.GenerateInsuranceReport(userUid.(string), req.MoveUid, format)
+	if err != nil {
+		respondError(c, "生成保险申报清单失败: ", err)
+		return
+	}
+
+	// 设置响应头，返回报告文件
+	contentType := "application/pdf"
+	if format == service.ReportFormatCSV {
+		contentType = "text/csv; charset=utf-8"
+	}
+	c.Header("Content-Disposition", "attachment; filename=insurance-inventory."+format)
+	c.Data(http.StatusOK, contentType, data)
 }
 
 // GeneratePDFRequest 生成PDF请求参数
@@ -330,30 +354,21 @@ type GeneratePDFRequest struct {
 func GeneratePDF(c *gin.Context) {
 	var req GeneratePDFRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusOK, gin.H{
-			"code":    400,
-			"message": "请求参数错误: " + err.Error(),
-		})
+		respondInvalidParams(c, err)
 		return
 	}
 
 	// 获取当前用户UID
 	userUid, exists := c.Get("userUid")
 	if !exists {
-		c.JSON(http.StatusOK, gin.H{
-			"code":    common.CodeUserNotLogin,
-			"message": common.CodeMessage[common.CodeUserNotLogin],
-		})
+		respondNotLogin(c)
 		return
 	}
 
 	// 调用服务层生成PDF
 	pdfBytes, err := service.GenerateTagPDF(userUid.(string), req.MoveUid)
 	if err != nil {
-		c.JSON(http.StatusOK, gin.H{
-			"code":    500,
-			"message": "生成PDF失败: " + err.Error(),
-		})
+		respondError(c, "生成PDF失败: ", err)
 		return
 	}
 
@@ -362,3 +377,201 @@ func GeneratePDF(c *gin.Context) {
 	c.Header("Content-Disposition", "attachment; filename=tags.pdf")
 	c.Data(http.StatusOK, "application/pdf", pdfBytes)
 }
+
+// SyncScanEventRequest 离线扫码事件参数
+type SyncScanEventRequest struct {
+	EventUid     string `json:"event_uid" binding:"required,max=64"` // 客户端生成的事件唯一标识
+	TagUid       string `json:"tag_uid" binding:"required,uuid"`     // 标签UID
+	IsVerified   int    `json:"is_verified" binding:"oneof=0 1"`     // 是否核销(0-未核销,1-已核销)
+	StateVersion int64  `json:"state_version" binding:"min=0"`       // 扫码时标签的核销状态版本号(取自标签查询或上次同步结果)
+	ClientAt     int64  `json:"client_at" binding:"required,min=1"`  // 客户端扫码时间戳(毫秒)
+}
+
+// SyncTagsRequest 离线扫码批量同步请求参数
+type SyncTagsRequest struct {
+	Events []SyncScanEventRequest `json:"events" binding:"required,min=1,max=500,dive"` // 扫码事件列表
+}
+
+// SyncTags 离线扫码批量同步接口
+func SyncTags(c *gin.Context) {
+	var req SyncTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidParams(c, err)
+		return
+	}
+
+	// 获取当前用户UID
+	userUid, exists := c.Get("userUid")
+	if !exists {
+		respondNotLogin(c)
+		return
+	}
+
+	events := make([]service.SyncScanEvent, 0, len(req.Events))
+	for _, event := range req.Events {
+		events = append(events, service.SyncScanEvent{
+			EventUid:     event.EventUid,
+			TagUid:       event.TagUid,
+			IsVerified:   event.IsVerified,
+			StateVersion: event.StateVersion,
+			ClientAt:     event.ClientAt,
+		})
+	}
+
+	// 调用服务层同步扫码事件
+	result, err := service.SyncScanEvents(userUid.(string), events)
+	if err != nil {
+		respondError(c, "同步扫码记录失败: ", err)
+		return
+	}
+
+	// 返回成功响应
+	respondSuccess(c, gin.H{
+		"code":    common.CodeSuccess,
+		"message": "同步成功",
+		"results": result.Results,
+		"tags":    result.Tags,
+	}, result)
+}
+
+// ImportTagsRequest 导入标签请求参数
+type ImportTagsRequest struct {
+	MoveUid string `form:"move_uid" binding:"required,uuid"` // 搬运UID
+	DryRun  bool   `form:"dry_run"`                          // 是否仅校验不写入
+}
+
+// ImportTags 从CSV或XLSX文件导入标签接口
+func ImportTags(c *gin.Context) {
+	var req ImportTagsRequest
+	if err := c.ShouldBind(&req); err != nil {
+		respondInvalidParams(c, err)
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		respondBadRequest(c, "请求参数错误: 缺少导入文件")
+		return
+	}
+
+	// 获取当前用户UID
+	userUid, exists := c.Get("userUid")
+	if !exists {
+		respondNotLogin(c)
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		respondError(c, "读取导入文件失败: ", err)
+		return
+	}
+	defer file.Close()
+
+	// 调用服务层导入标签
+	result, err := service.ImportTags(userUid.(string), service.ImportTagsRequest{
+		MoveUid:  req.MoveUid,
+		FileName: fileHeader.Filename,
+		Reader:   file,
+		DryRun:   req.DryRun,
+	})
+	if err != nil {
+		respondError(c, "导入标签失败: ", err)
+		return
+	}
+
+	// 存在行错误时不写入任何数据
+	if !req.DryRun && len(result.Errors) > 0 {
+		if dto.IsV2(c) {
+			dto.Write(c, http.StatusBadRequest, dto.Response{
+				Code:    common.CodeInvalidParams,
+				Message: "导入文件存在错误，未导入任何数据",
+				Data:    result,
+			})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"code":    common.CodeInvalidParams,
+			"message": "导入文件存在错误，未导入任何数据",
+			"result":  result,
+		})
+		return
+	}
+
+	// 返回成功响应
+	message := "导入成功"
+	if req.DryRun {
+		message = "校验完成"
+	}
+	respondSuccess(c, gin.H{
+		"code":    common.CodeSuccess,
+		"message": message,
+		"result":  result,
+	}, result)
+}
+
+// GenerateManifestPDF 生成装箱清单PDF接口
+func GenerateManifestPDF(c *gin.Context) {
+	var req GeneratePDFRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidParams(c, err)
+		return
+	}
+
+	// 获取当前用户UID
+	userUid, exists := c.Get("userUid")
+	if !exists {
+		respondNotLogin(c)
+		return
+	}
+
+	// 调用服务层生成装箱清单
+	pdfBytes, err := service.GenerateManifestPDF(userUid.(string), req.MoveUid)
+	if err != nil {
+		respondError(c, "生成装箱清单失败: ", err)
+		return
+	}
+
+	// 设置响应头，返回PDF文件
+	c.Header("Content-Disposition", "attachment; filename=manifest.pdf")
+	c.Data(http.StatusOK, "application/pdf", pdfBytes)
+}
+
+// RenderTagLabelRequest 渲染单个标签图片请求参数
+type RenderTagLabelRequest struct {
+	TagUid string `json:"tag_uid" binding:"required,uuid"`      // 标签UID
+	Format string `json:"format" binding:"omitempty,oneof=png svg"` // 图片格式(png,svg)，默认png
+	Dpi    int    `json:"dpi" binding:"omitempty,min=72,max=600"`   // 分辨率，默认300
+}
+
+// RenderTagLabel 渲染单个标签图片接口
+func RenderTagLabel(c *gin.Context) {
+	var req RenderTagLabelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidParams(c, err)
+		return
+	}
+
+	// 获取当前用户UID
+	userUid, exists := c.Get("userUid")
+	if !exists {
+		respondNotLogin(c)
+		return
+	}
+
+	format := req.Format
+	if format == "" {
+		format = service.LabelFormatPNG
+	}
+
+	// 调用服务层渲染标签
+	data, err := service.RenderTagLabel(userUid.(string), req.TagUid, format, req.Dpi)
+	if err != nil {
+		respondError(c, "生成标签图片失败: ", err)
+		return
+	}
+
+	// 设置响应头，返回图片
+	c.Header("Content-Disposition", "inline; filename=label."+format)
+	c.Data(http.StatusOK, service.LabelContentType(format), data)
+}
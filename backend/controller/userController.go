@@ -1,12 +1,12 @@
 package controller
 
 import (
-	"net/http"
 	"regexp"
 
 	"github.com/gin-gonic/gin"
 
 	"movingManager/common"
+	"movingManager/dto"
 	"movingManager/service"
 )
 
@@ -20,41 +20,32 @@ type UserAuthRequest struct {
 func UserAuth(c *gin.Context) {
 	var req UserAuthRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusOK, gin.H{
-			"code":    400,
-			"message": "请求参数错误: " + err.Error(),
-		})
+		respondInvalidParams(c, err)
 		return
 	}
 
 	// 验证手机号格式
 	if !isValidMobile(req.Mobile) {
-		c.JSON(http.StatusOK, gin.H{
-			"code":    400,
-			"message": "手机号格式不正确",
-		})
+		respondBadRequest(c, "手机号格式不正确")
 		return
 	}
 
 	// 调用服务层处理业务逻辑
 	user, token, err := service.UserAuth(req.Mobile)
 	if err != nil {
-		c.JSON(http.StatusOK, gin.H{
-			"code":    500,
-			"message": "操作失败: " + err.Error(),
-		})
+		respondError(c, "操作失败: ", err)
 		return
 	}
 
 	// 返回成功响应
-	c.JSON(http.StatusOK, gin.H{
+	respondSuccess(c, gin.H{
 		"code":    common.CodeSuccess,
 		"message": common.CodeMessage[common.CodeSuccess],
 		"user": gin.H{
 			"user_name": user.UserName,
 		},
 		"token": token,
-	})
+	}, dto.Auth{UserName: user.UserName, Token: token})
 }
 
 // isValidMobile 验证手机号格式
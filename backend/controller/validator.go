@@ -0,0 +1,19 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+
+	"movingManager/common"
+)
+
+// 注册字段校验规则别名，binding标签中的 tag_name、remark 等规则与批量导入使用相同的长度限制
+func init() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	for alias, tags := range common.FieldRuleAliases {
+		v.RegisterAlias(alias, tags)
+	}
+}
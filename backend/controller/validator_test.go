@@ -0,0 +1,47 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin/binding"
+
+	"movingManager/common"
+)
+
+func TestFieldRuleAliases(t *testing.T) {
+	type request struct {
+		TagName  string `binding:"required,tag_name"`
+		ItemName string `binding:"item_name"`
+		RoomName string `binding:"room_name"`
+		Remark   string `binding:"remark"`
+	}
+	valid := request{
+		TagName:  strings.Repeat("箱", common.MaxTagNameLength),
+		ItemName: strings.Repeat("物", common.MaxItemNameLength),
+		RoomName: strings.Repeat("房", common.MaxRoomNameLength),
+		Remark:   strings.Repeat("备", common.MaxRemarkLength),
+	}
+
+	cases := []struct {
+		name    string
+		modify  func(r *request)
+		wantErr bool
+	}{
+		{"长度上限", func(r *request) {}, false},
+		{"标签名称超长", func(r *request) { r.TagName += "箱" }, true},
+		{"物品名称超长", func(r *request) { r.ItemName += "物" }, true},
+		{"房间名称超长", func(r *request) { r.RoomName += "房" }, true},
+		{"备注超长", func(r *request) { r.Remark += "备" }, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := valid
+			tc.modify(&req)
+			err := binding.Validator.ValidateStruct(&req)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("ValidateStruct() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package controller
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"movingManager/common"
+	"movingManager/dto"
+	"movingManager/service"
+)
+
+// CreateWebhookRequest 创建Webhook请求参数
+type CreateWebhookRequest struct {
+	Url        string   `json:"url" binding:"required,url,max=500"`                // 推送地址(http/https)
+	EventTypes []string `json:"event_types" binding:"max=20,dive,required,max=50"` // 订阅的事件类型(为空订阅全部)
+	Remark     string   `json:"remark" binding:"max=200"`                          // 备注
+}
+
+// CreateWebhook 创建Webhook接口
+func CreateWebhook(c *gin.Context) {
+	var req CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidParams(c, err)
+		return
+	}
+	if !isHTTPURL(req.Url) {
+		respondBadRequest(c, "请求参数错误: 推送地址仅支持http或https")
+		return
+	}
+
+	// 获取当前用户UID
+	userUid, exists := c.Get("userUid")
+	if !exists {
+		respondNotLogin(c)
+		return
+	}
+
+	// 调用服务层创建Webhook
+	webhook, err := service.CreateWebhook(userUid.(string), service.CreateWebhookRequest{
+		Url:        req.Url,
+		EventTypes: req.EventTypes,
+		Remark:     req.Remark,
+	})
+	if err != nil {
+		respondError(c, "创建Webhook失败: ", err)
+		return
+	}
+
+	// 返回成功响应
+	respondSuccess(c, gin.H{
+		"code":    common.CodeSuccess,
+		"message": "创建成功",
+		"webhook": webhook,
+	}, webhook)
+}
+
+// UpdateWebhookRequest 编辑Webhook请求参数
+type UpdateWebhookRequest struct {
+	WebhookUid string   `json:"webhook_uid" binding:"required,uuid"`               // WebhookUID
+	Url        string   `json:"url" binding:"required,url,max=500"`                // 推送地址(http/https)
+	EventTypes []string `json:"event_types" binding:"max=20,dive,required,max=50"` // 订阅的事件类型(为空订阅全部)
+	IsEnabled  int      `json:"is_enabled" binding:"oneof=0 1"`                    // 是否启用(0-停用,1-启用)
+	Remark     string   `json:"remark" binding:"max=200"`                          // 备注
+}
+
+// UpdateWebhook 编辑Webhook接口
+func UpdateWebhook(c *gin.Context) {
+	var req UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidParams(c, err)
+		return
+	}
+	if !isHTTPURL(req.Url) {
+		respondBadRequest(c, "请求参数错误: 推送地址仅支持http或https")
+		return
+	}
+
+	// 获取当前用户UID
+	userUid, exists := c.Get("userUid")
+	if !exists {
+		respondNotLogin(c)
+		return
+	}
+
+	// 调用服务层编辑Webhook
+	webhook, err := service.UpdateWebhook(userUid.(string), service.UpdateWebhookRequest{
+		WebhookUid: req.WebhookUid,
+		Url:        req.Url,
+		EventTypes: req.EventTypes,
+		IsEnabled:  req.IsEnabled,
+		Remark:     req.Remark,
+	})
+	if err != nil {
+		respondError(c, "更新Webhook失败: ", err)
+		return
+	}
+
+	// 返回成功响应
+	respondSuccess(c, gin.H{
+		"code":    common.CodeSuccess,
+		"message": "更新成功",
+		"webhook": webhook,
+	}, webhook)
+}
+
+// DeleteWebhookRequest 删除Webhook请求参数
+type DeleteWebhookRequest struct {
+	WebhookUid string `json:"webhook_uid" binding:"required,uuid"` // WebhookUID
+	IsDeleted  int    `json:"is_deleted" binding:"oneof=0 1"`      // 是否删除(0-未删除,1-已删除)
+}
+
+// DeleteWebhook 删除Webhook接口
+func DeleteWebhook(c *gin.Context) {
+	var req DeleteWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidParams(c, err)
+		return
+	}
+
+	// 获取当前用户UID
+	userUid, exists := c.Get("userUid")
+	if !exists {
+		respondNotLogin(c)
+		return
+	}
+
+	// 调用服务层删除Webhook
+	if err := service.DeleteWebhook(userUid.(string), req.WebhookUid, req.IsDeleted); err != nil {
+		respondError(c, "删除Webhook失败: ", err)
+		return
+	}
+
+	// 返回成功响应
+	respondSuccess(c, gin.H{
+		"code":    common.CodeSuccess,
+		"message": "操作成功",
+	}, nil)
+}
+
+// GetWebhookList Webhook列表接口
+func GetWebhookList(c *gin.Context) {
+	// 获取当前用户UID
+	userUid, exists := c.Get("userUid")
+	if !exists {
+		respondNotLogin(c)
+		return
+	}
+
+	webhooks, err := service.GetWebhookList(userUid.(string))
+	if err != nil {
+		respondError(c, "获取Webhook列表失败: ", err)
+		return
+	}
+
+	// 返回成功响应
+	respondSuccess(c, gin.H{
+		"code":     common.CodeSuccess,
+		"webhooks": webhooks,
+	}, webhooks)
+}
+
+// GetWebhookDeliveriesRequest Webhook投递记录请求参数
+type GetWebhookDeliveriesRequest struct {
+	WebhookUid string `json:"webhook_uid" binding:"required,uuid"` // WebhookUID
+	Page       int    `json:"page" binding:"min=1"`                // 页码
+	PageSize   int    `json:"page_size" binding:"min=1,max=50"`    // 每页条数
+}
+
+// GetWebhookDeliveries Webhook投递记录接口
+func GetWebhookDeliveries(c *gin.Context) {
+	var req GetWebhookDeliveriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidParams(c, err)
+		return
+	}
+
+	// 获取当前用户UID
+	userUid, exists := c.Get("userUid")
+	if !exists {
+		respondNotLogin(c)
+		return
+	}
+
+	deliveries, total, err := service.GetWebhookDeliveries(userUid.(string), req.WebhookUid, req.Page, req.PageSize)
+	if err != nil {
+		respondError(c, "获取投递记录失败: ", err)
+		return
+	}
+
+	// 计算总页数
+	totalPages := (total + int64(req.PageSize) - 1) / int64(req.PageSize)
+
+	// 返回成功响应
+	respondPage(c, gin.H{
+		"code":       common.CodeSuccess,
+		"deliveries": deliveries,
+		"pagination": gin.H{
+			"total":      total,
+			"page":       req.Page,
+			"pageSize":   req.PageSize,
+			"totalPages": totalPages,
+		},
+	}, deliveries, dto.NewPagination(req.Page, req.PageSize, total))
+}
+
+// isHTTPURL 判断地址是否为http或https协议
+func isHTTPURL(url string) bool {
+	lower := strings.ToLower(url)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
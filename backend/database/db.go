@@ -3,11 +3,13 @@ package database
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"time"
 
 	"gopkg.in/yaml.v3"
 	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
@@ -15,8 +17,23 @@ import (
 // DB 全局数据库连接实例
 var DB *gorm.DB
 
+// gormLog 数据库日志记录器，默认只记录错误和慢查询
+var gormLog logger.Interface = logger.Default.LogMode(logger.Warn)
+
+// SetLogger 设置数据库日志记录器，需在 InitDB 之前调用
+func SetLogger(l logger.Interface) {
+	gormLog = l
+}
+
+// 支持的数据库驱动
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
 // Config 数据库配置结构体
 type Config struct {
+	Driver     string `yaml:"driver"` // 数据库驱动(postgres/sqlite)，为空时使用postgres
 	PostgreSQL struct {
 		Host            string `yaml:"host"`
 		Port            string `yaml:"port"`
@@ -28,6 +45,9 @@ type Config struct {
 		MaxIdleConns    int    `yaml:"max_idle_conns"`
 		ConnMaxLifetime int    `yaml:"conn_max_lifetime"`
 	} `yaml:"postgresql"`
+	SQLite struct {
+		Path string `yaml:"path"` // 数据库文件路径
+	} `yaml:"sqlite"`
 }
 
 // InitDB 初始化数据库连接
@@ -38,34 +58,80 @@ func InitDB() error {
 		return fmt.Errorf("加载数据库配置失败: %v", err)
 	}
 
-	// 构建DSN
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		config.PostgreSQL.Host,
-		config.PostgreSQL.Port,
-		config.PostgreSQL.User,
-		config.PostgreSQL.Password,
-		config.PostgreSQL.Dbname,
-		config.PostgreSQL.Sslmode,
-	)
-
 	// 连接数据库
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info), // 显示SQL日志
+	DB, err = Open(config)
+	return err
+}
+
+// Open 根据配置的驱动打开数据库连接并设置连接池
+func Open(config *Config) (*gorm.DB, error) {
+	var dialector gorm.Dialector
+	switch config.Driver {
+	case "", DriverPostgres:
+		dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+			config.PostgreSQL.Host,
+			config.PostgreSQL.Port,
+			config.PostgreSQL.User,
+			config.PostgreSQL.Password,
+			config.PostgreSQL.Dbname,
+			config.PostgreSQL.Sslmode,
+		)
+		dialector = PostgresDialector(dsn)
+	case DriverSQLite:
+		if config.SQLite.Path == "" {
+			return nil, fmt.Errorf("未配置SQLite数据库文件路径")
+		}
+		if err := os.MkdirAll(filepath.Dir(config.SQLite.Path), 0o755); err != nil {
+			return nil, fmt.Errorf("创建SQLite数据目录失败: %v", err)
+		}
+		dialector = SQLiteDialector(config.SQLite.Path)
+	default:
+		return nil, fmt.Errorf("不支持的数据库驱动: %s", config.Driver)
+	}
+
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger: gormLog,
 	})
 	if err != nil {
-		return fmt.Errorf("数据库连接失败: %v", err)
+		return nil, fmt.Errorf("数据库连接失败: %v", err)
 	}
 
 	// 设置连接池
-	sqlDB, err := DB.DB()
+	sqlDB, err := db.DB()
 	if err != nil {
-		return fmt.Errorf("获取数据库连接池失败: %v", err)
+		return nil, fmt.Errorf("获取数据库连接池失败: %v", err)
+	}
+	if config.Driver == DriverSQLite {
+		return db, nil
 	}
 	sqlDB.SetMaxOpenConns(config.PostgreSQL.MaxOpenConns)
 	sqlDB.SetMaxIdleConns(config.PostgreSQL.MaxIdleConns)
 	sqlDB.SetConnMaxLifetime(time.Duration(config.PostgreSQL.ConnMaxLifetime) * time.Second)
 
-	return err
+	return db, nil
+}
+
+// Close 关闭数据库连接池
+func Close() error {
+	if DB == nil {
+		return nil
+	}
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+// PostgresDialector 创建PostgreSQL方言
+func PostgresDialector(dsn string) gorm.Dialector {
+	return postgres.Open(dsn)
+}
+
+// SQLiteDialector 创建SQLite方言
+// WAL模式下读写互不阻塞；写事务开始时即加锁，并发写入排队等待而不是返回SQLITE_BUSY
+func SQLiteDialector(path string) gorm.Dialector {
+	return sqlite.Open(path + "?_busy_timeout=5000&_journal_mode=WAL&_txlock=immediate")
 }
 
 // loadConfig 从YAML文件加载配置
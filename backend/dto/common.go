@@ -0,0 +1,27 @@
+package dto
+
+import (
+	"time"
+
+	"movingManager/service"
+)
+
+// Auth 登录结果
+type Auth struct {
+	UserName string `json:"user_name"`
+	Token    string `json:"token"`
+}
+
+// AttachmentList 附件列表及存储用量
+type AttachmentList struct {
+	Attachments []service.AttachmentResponse `json:"attachments"`
+	Usage       *service.StorageUsage        `json:"usage"`
+}
+
+// formatTime 格式化Unix时间戳，0表示未设置
+func formatTime(ts int64) string {
+	if ts == 0 {
+		return ""
+	}
+	return time.Unix(ts, 0).Format("2006-01-02 15:04:05")
+}
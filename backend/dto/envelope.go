@@ -0,0 +1,79 @@
+package dto
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"movingManager/common"
+)
+
+// 上下文键
+const (
+	APIVersionKey = "apiVersion" // 接口版本(1或2)，由版本中间件写入
+	RequestIDKey  = "requestId"  // 请求ID，由请求ID中间件写入
+)
+
+// Response v2接口统一响应信封
+type Response struct {
+	Code       int         `json:"code"`
+	Message    string      `json:"message"`
+	Data       interface{} `json:"data,omitempty"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+	RequestID  string      `json:"request_id,omitempty"`
+}
+
+// Pagination 分页信息
+type Pagination struct {
+	Page       int   `json:"page"`
+	PageSize   int   `json:"page_size"`
+	Total      int64 `json:"total"`
+	TotalPages int64 `json:"total_pages"`
+}
+
+// NewPagination 根据页码、每页条数和总数计算分页信息
+func NewPagination(page, pageSize int, total int64) *Pagination {
+	return &Pagination{
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      total,
+		TotalPages: (total + int64(pageSize) - 1) / int64(pageSize),
+	}
+}
+
+// IsV2 判断当前请求是否为v2接口
+func IsV2(c *gin.Context) bool {
+	return c.GetInt(APIVersionKey) == 2
+}
+
+// Write 写出v2响应信封
+func Write(c *gin.Context, status int, resp Response) {
+	resp.RequestID = c.GetString(RequestIDKey)
+	c.JSON(status, resp)
+}
+
+// WriteData 写出v2成功响应
+func WriteData(c *gin.Context, message string, data interface{}, pagination *Pagination) {
+	if message == "" {
+		message = common.CodeMessage[common.CodeSuccess]
+	}
+	Write(c, http.StatusOK, Response{
+		Code:       common.CodeSuccess,
+		Message:    message,
+		Data:       data,
+		Pagination: pagination,
+	})
+}
+
+// WriteError 按接口版本写出错误响应
+// v1接口统一使用HTTP 200并保持原有格式；v2接口使用对应的HTTP状态码和响应信封
+func WriteError(c *gin.Context, status, code int, message string) {
+	if !IsV2(c) {
+		c.JSON(http.StatusOK, gin.H{
+			"code":    code,
+			"message": message,
+		})
+		return
+	}
+	Write(c, status, Response{Code: code, Message: message})
+}
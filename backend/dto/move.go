@@ -0,0 +1,53 @@
+package dto
+
+import (
+	"movingManager/model"
+)
+
+// Move 搬运记录
+type Move struct {
+	MoveUid              string                `json:"move_uid"`
+	MoveAt               string                `json:"move_at"`
+	StartLocation        string                `json:"start_location"`
+	EndLocation          string                `json:"end_location"`
+	TagCount             int                   `json:"tag_count"`
+	VerifiedTagCount     int                   `json:"verified_tag_count"`
+	UnverifiedTagCount   int                   `json:"unverified_tag_count"`
+	IsCompleted          int                   `json:"is_completed"`
+	AllowAnonymousVerify int                   `json:"allow_anonymous_verify"`
+	Remark               string                `json:"remark"`
+	IsDeleted            int                   `json:"is_deleted"`
+	DeletedAt            string                `json:"deleted_at,omitempty"`
+	CreatedAt            string                `json:"created_at"`
+	UpdatedAt            string                `json:"updated_at,omitempty"`
+	DeclaredTotals       []model.CurrencyTotal `json:"declared_totals,omitempty"` // 按币种汇总的申报价值(仅详情返回)
+}
+
+// NewMove 由搬运模型构建响应
+func NewMove(m *model.MoveModel) Move {
+	return Move{
+		MoveUid:              m.MoveUid,
+		MoveAt:               formatTime(m.MoveAt),
+		StartLocation:        m.StartLocation,
+		EndLocation:          m.EndLocation,
+		TagCount:             m.TagCount,
+		VerifiedTagCount:     m.VerifiedTagCount,
+		UnverifiedTagCount:   m.UnverifiedTagCount,
+		IsCompleted:          m.IsCompleted,
+		AllowAnonymousVerify: m.AllowAnonymousVerify,
+		Remark:               m.Remark,
+		IsDeleted:            m.IsDeleted,
+		DeletedAt:            formatTime(m.DeletedAt),
+		CreatedAt:            formatTime(m.CreatedAt),
+		UpdatedAt:            formatTime(m.UpdatedAt),
+	}
+}
+
+// NewMoveList 由搬运模型列表构建响应
+func NewMoveList(moves []model.MoveModel) []Move {
+	list := make([]Move, 0, len(moves))
+	for i := range moves {
+		list = append(list, NewMove(&moves[i]))
+	}
+	return list
+}
@@ -0,0 +1,54 @@
+package dto
+
+import (
+	"movingManager/service"
+)
+
+// Tag 标签
+// 在服务层响应基础上统一删除时间格式，并递归转换内部标签
+type Tag struct {
+	service.TagResponse
+	Children  []Tag  `json:"children,omitempty"`
+	DeletedAt string `json:"deleted_at,omitempty"`
+}
+
+// NewTag 由服务层标签响应构建响应
+func NewTag(t *service.TagResponse) Tag {
+	tag := Tag{
+		TagResponse: *t,
+		DeletedAt:   formatTime(t.DeletedAt),
+	}
+	if len(t.Children) > 0 {
+		tag.Children = NewTagList(t.Children)
+	}
+	return tag
+}
+
+// NewTagList 由服务层标签响应列表构建响应
+func NewTagList(tags []service.TagResponse) []Tag {
+	list := make([]Tag, 0, len(tags))
+	for i := range tags {
+		list = append(list, NewTag(&tags[i]))
+	}
+	return list
+}
+
+// TagRoomGroup 按房间分组的标签
+type TagRoomGroup struct {
+	service.TagRoomGroup
+	Tags []Tag `json:"tags"`
+}
+
+// NewTagRoomGroups 由服务层分组结果构建响应
+func NewTagRoomGroups(groups []service.TagRoomGroup) []TagRoomGroup {
+	list := make([]TagRoomGroup, 0, len(groups))
+	for _, group := range groups {
+		list = append(list, TagRoomGroup{TagRoomGroup: group, Tags: NewTagList(group.Tags)})
+	}
+	return list
+}
+
+// VerifyResult 核销结果
+type VerifyResult struct {
+	ChangedCount int `json:"changed_count"` // 状态发生变化的标签数(含级联)
+}
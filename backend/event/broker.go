@@ -0,0 +1,124 @@
+package event
+
+import (
+	"sync"
+	"time"
+)
+
+// 事件类型
+const (
+	TypeTagCreated   = "tag.created"   // 标签创建
+	TypeTagUpdated   = "tag.updated"   // 标签编辑
+	TypeTagVerified  = "tag.verified"  // 标签核销状态变更
+	TypeTagDeleted   = "tag.deleted"   // 标签删除或恢复
+	TypeMoveCreated  = "move.created"  // 搬运创建
+	TypeMoveUpdated  = "move.updated"  // 搬运编辑
+	TypeMoveDeleted  = "move.deleted"  // 搬运删除或恢复
+	TypeMoveProgress = "move.progress" // 搬运标签统计变更
+)
+
+// subscriberBuffer 每个订阅者的事件缓冲数量，缓冲满时丢弃新事件，避免慢消费者阻塞业务
+const subscriberBuffer = 64
+
+// Event 搬运相关事件
+type Event struct {
+	Type      string      `json:"type"`       // 事件类型
+	UserUid   string      `json:"-"`          // 所属用户UID
+	MoveUid   string      `json:"move_uid"`   // 所属搬运UID
+	Data      interface{} `json:"data"`       // 事件数据
+	CreatedAt int64       `json:"created_at"` // 事件时间戳(毫秒)
+}
+
+// AllTypes 全部事件类型
+var AllTypes = []string{
+	TypeTagCreated, TypeTagUpdated, TypeTagVerified, TypeTagDeleted,
+	TypeMoveCreated, TypeMoveUpdated, TypeMoveDeleted, TypeMoveProgress,
+}
+
+// Broker 进程内事件分发器
+// 业务提交成功后发布事件，订阅者按搬运UID过滤接收
+type Broker struct {
+	mu          sync.RWMutex
+	nextID      int
+	subscribers map[int]*subscriber
+	closed      bool
+}
+
+// subscriber 事件订阅者
+type subscriber struct {
+	moveUid string // 为空表示订阅全部事件
+	ch      chan Event
+}
+
+// Default 全局事件分发器
+var Default = NewBroker()
+
+// NewBroker 创建事件分发器
+func NewBroker() *Broker {
+	return &Broker{subscribers: make(map[int]*subscriber)}
+}
+
+// Subscribe 订阅搬运事件，moveUid 为空时订阅全部事件
+// 返回事件通道和取消订阅函数，调用方不再接收时必须调用取消函数
+// 分发器关闭后返回已关闭的通道
+func (b *Broker) Subscribe(moveUid string) (<-chan Event, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		ch := make(chan Event)
+		close(ch)
+		return ch, func() {}
+	}
+
+	id := b.nextID
+	b.nextID++
+	sub := &subscriber{moveUid: moveUid, ch: make(chan Event, subscriberBuffer)}
+	b.subscribers[id] = sub
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			// 分发器关闭时已关闭通道
+			if _, ok := b.subscribers[id]; ok {
+				delete(b.subscribers, id)
+				close(sub.ch)
+			}
+		})
+	}
+	return sub.ch, cancel
+}
+
+// Close 关闭分发器，关闭全部订阅者的通道
+// 服务退出时调用，使SSE等长连接结束，不影响同步处理函数
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for id, sub := range b.subscribers {
+		delete(b.subscribers, id)
+		close(sub.ch)
+	}
+}
+
+// Publish 发布事件，不阻塞调用方
+func (b *Broker) Publish(e Event) {
+	if e.CreatedAt == 0 {
+		e.CreatedAt = time.Now().UnixMilli()
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, sub := range b.subscribers {
+		if sub.moveUid != "" && sub.moveUid != e.MoveUid {
+			continue
+		}
+		select {
+		case sub.ch <- e:
+		default:
+			// 缓冲已满，丢弃事件
+		}
+	}
+}
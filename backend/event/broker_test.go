@@ -0,0 +1,41 @@
+package event
+
+import "testing"
+
+func TestBrokerClose(t *testing.T) {
+	b := NewBroker()
+	events, cancel := b.Subscribe("m1")
+
+	b.Close()
+	if _, ok := <-events; ok {
+		t.Fatal("关闭后订阅通道应已关闭")
+	}
+	// 关闭后取消订阅不能重复关闭通道
+	cancel()
+
+	late, lateCancel := b.Subscribe("m1")
+	defer lateCancel()
+	if _, ok := <-late; ok {
+		t.Error("关闭后新订阅应返回已关闭的通道")
+	}
+	// 关闭后仍可发布，不会向已关闭的通道写入
+	b.Publish(Event{Type: TypeTagCreated, MoveUid: "m1"})
+}
+
+func TestBrokerPublishFiltersByMove(t *testing.T) {
+	b := NewBroker()
+	events, cancel := b.Subscribe("m1")
+	defer cancel()
+	all, cancelAll := b.Subscribe("")
+	defer cancelAll()
+
+	b.Publish(Event{Type: TypeTagCreated, MoveUid: "m2"})
+	b.Publish(Event{Type: TypeTagVerified, MoveUid: "m1"})
+
+	if e := <-events; e.Type != TypeTagVerified {
+		t.Errorf("订阅m1收到 %s, want %s", e.Type, TypeTagVerified)
+	}
+	if len(all) != 2 {
+		t.Errorf("订阅全部收到 %d 个事件, want 2", len(all))
+	}
+}
@@ -0,0 +1,106 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// defaultSlowSQL 未配置时的慢SQL阈值
+const defaultSlowSQL = 500 * time.Millisecond
+
+// GormLogger 将GORM日志输出到slog
+// SQL语句只记录占位符形式，不记录参数值，避免授权码、手机号等写入日志
+type GormLogger struct {
+	level     gormLogger.LogLevel
+	slowSQL   time.Duration
+	debugging bool // 是否记录全部语句
+}
+
+// NewGormLogger 创建GORM日志记录器
+// level 为 silent/error/warn/debug，为空时使用warn；slowSQLMs<=0 时使用默认阈值
+func NewGormLogger(level string, slowSQLMs int) (*GormLogger, error) {
+	l := &GormLogger{slowSQL: defaultSlowSQL}
+	if slowSQLMs > 0 {
+		l.slowSQL = time.Duration(slowSQLMs) * time.Millisecond
+	}
+	switch strings.ToLower(level) {
+	case "silent":
+		l.level = gormLogger.Silent
+	case "error":
+		l.level = gormLogger.Error
+	case "", "warn":
+		l.level = gormLogger.Warn
+	case "debug":
+		l.level = gormLogger.Info
+		l.debugging = true
+	default:
+		return nil, fmt.Errorf("不支持的SQL日志级别: %s", level)
+	}
+	return l, nil
+}
+
+// LogMode 实现 gormLogger.Interface
+func (l *GormLogger) LogMode(level gormLogger.LogLevel) gormLogger.Interface {
+	c := *l
+	c.level = level
+	c.debugging = level >= gormLogger.Info
+	return &c
+}
+
+// Info 实现 gormLogger.Interface
+func (l *GormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormLogger.Info {
+		FromContext(ctx).InfoContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+// Warn 实现 gormLogger.Interface
+func (l *GormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormLogger.Warn {
+		FromContext(ctx).WarnContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+// Error 实现 gormLogger.Interface
+func (l *GormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormLogger.Error {
+		FromContext(ctx).ErrorContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+// Trace 实现 gormLogger.Interface，记录出错、慢查询以及debug级别下的全部语句
+// 查询不到记录属于正常业务流程，不按错误记录
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= gormLogger.Silent {
+		return
+	}
+	elapsed := time.Since(begin)
+	logger := FromContext(ctx)
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.level >= gormLogger.Error:
+		sql, rows := fc()
+		logger.ErrorContext(ctx, "SQL执行失败", "sql", sql, "rows", rows, "elapsed_ms", elapsed.Milliseconds(), "error", err.Error())
+	case elapsed > l.slowSQL && l.level >= gormLogger.Warn:
+		sql, rows := fc()
+		logger.WarnContext(ctx, "慢SQL", "sql", sql, "rows", rows, "elapsed_ms", elapsed.Milliseconds())
+	case l.debugging:
+		sql, rows := fc()
+		logger.DebugContext(ctx, "SQL", "sql", sql, "rows", rows, "elapsed_ms", elapsed.Milliseconds())
+	}
+}
+
+// ParamsFilter 实现 gorm.ParamsFilter，丢弃参数值，Trace中只得到带占位符的SQL
+func (l *GormLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
+
+var (
+	_ gormLogger.Interface = (*GormLogger)(nil)
+	_ gorm.ParamsFilter    = (*GormLogger)(nil)
+)
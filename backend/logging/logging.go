@@ -0,0 +1,116 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+
+	"movingManager/config"
+)
+
+// redactedValue 敏感字段替换后的值
+const redactedValue = "[REDACTED]"
+
+// secretKeys 整体隐藏的字段名(小写)
+var secretKeys = map[string]bool{
+	"authorization":      true,
+	"authorization_code": true,
+	"auth_code":          true,
+	"token":              true,
+	"secret":             true,
+	"password":           true,
+	"signature":          true,
+}
+
+// mobileKeys 部分隐藏的手机号字段名(小写)
+var mobileKeys = map[string]bool{
+	"mobile": true,
+	"phone":  true,
+}
+
+// contextKey 请求上下文中保存日志记录器的键
+type contextKey struct{}
+
+// Init 根据配置初始化全局日志记录器
+// 同时接管标准库log的输出，未迁移的log.Printf也按结构化格式输出
+func Init(cfg config.LogConfig) error {
+	level, err := ParseLevel(cfg.Level)
+	if err != nil {
+		return err
+	}
+	handler, err := NewHandler(os.Stdout, cfg.Format, level)
+	if err != nil {
+		return err
+	}
+	logger := slog.New(handler)
+	slog.SetDefault(logger)
+	log.SetFlags(0)
+	log.SetOutput(slog.NewLogLogger(handler, slog.LevelInfo).Writer())
+	return nil
+}
+
+// NewHandler 创建带敏感字段脱敏的日志处理器
+func NewHandler(w io.Writer, format string, level slog.Level) (slog.Handler, error) {
+	opts := &slog.HandlerOptions{Level: level, ReplaceAttr: redactAttr}
+	switch format {
+	case "", "json":
+		return slog.NewJSONHandler(w, opts), nil
+	case "text":
+		return slog.NewTextHandler(w, opts), nil
+	default:
+		return nil, fmt.Errorf("不支持的日志格式: %s", format)
+	}
+}
+
+// ParseLevel 解析日志级别，为空时使用info
+func ParseLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return slog.LevelInfo, fmt.Errorf("不支持的日志级别: %s", level)
+	}
+}
+
+// WithLogger 将日志记录器保存到上下文
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext 获取上下文中的日志记录器(带请求ID、用户UID等字段)，不存在时返回全局记录器
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// MaskMobile 隐藏手机号中间部分，如 13812345678 → 138****5678
+func MaskMobile(mobile string) string {
+	if len(mobile) < 7 {
+		return strings.Repeat("*", len(mobile))
+	}
+	return mobile[:3] + strings.Repeat("*", len(mobile)-7) + mobile[len(mobile)-4:]
+}
+
+// redactAttr 按字段名脱敏：授权码、令牌等整体隐藏，手机号隐藏中间部分
+func redactAttr(groups []string, a slog.Attr) slog.Attr {
+	key := strings.ToLower(a.Key)
+	switch {
+	case secretKeys[key]:
+		return slog.String(a.Key, redactedValue)
+	case mobileKeys[key]:
+		return slog.String(a.Key, MaskMobile(a.Value.String()))
+	}
+	return a
+}
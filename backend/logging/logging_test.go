@@ -0,0 +1,119 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMaskMobile(t *testing.T) {
+	cases := []struct {
+		mobile string
+		want   string
+	}{
+		{"13812345678", "138****5678"},
+		{"8613812345678", "861******5678"},
+		{"12345", "*****"},
+		{"", ""},
+	}
+	for _, tc := range cases {
+		if got := MaskMobile(tc.mobile); got != tc.want {
+			t.Errorf("MaskMobile(%q) = %q, want %q", tc.mobile, got, tc.want)
+		}
+	}
+}
+
+func TestHandlerRedactsSensitiveFields(t *testing.T) {
+	var buf bytes.Buffer
+	handler, err := NewHandler(&buf, "json", slog.LevelInfo)
+	if err != nil {
+		t.Fatalf("NewHandler() error = %v", err)
+	}
+	slog.New(handler).Info("login",
+		"authorization_code", "code-abc",
+		"Authorization", "Bearer code-abc",
+		"token", "scan-token",
+		"mobile", "13812345678",
+		"user_uid", "u1",
+	)
+
+	out := buf.String()
+	for _, secret := range []string{"code-abc", "scan-token", "13812345678"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("日志包含敏感值 %q: %s", secret, out)
+		}
+	}
+	for _, want := range []string{`"mobile":"138****5678"`, `"user_uid":"u1"`, redactedValue} {
+		if !strings.Contains(out, want) {
+			t.Errorf("日志缺少 %s: %s", want, out)
+		}
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	cases := []struct {
+		level   string
+		want    slog.Level
+		wantErr bool
+	}{
+		{"", slog.LevelInfo, false},
+		{"DEBUG", slog.LevelDebug, false},
+		{"warn", slog.LevelWarn, false},
+		{"error", slog.LevelError, false},
+		{"verbose", slog.LevelInfo, true},
+	}
+	for _, tc := range cases {
+		got, err := ParseLevel(tc.level)
+		if (err != nil) != tc.wantErr || got != tc.want {
+			t.Errorf("ParseLevel(%q) = %v, %v", tc.level, got, err)
+		}
+	}
+}
+
+func TestGormLoggerTrace(t *testing.T) {
+	cases := []struct {
+		name    string
+		level   string
+		elapsed time.Duration
+		err     error
+		want    string // 为空表示不输出
+	}{
+		{"warn级别忽略普通语句", "warn", 0, nil, ""},
+		{"warn级别记录慢SQL", "warn", time.Second, nil, "慢SQL"},
+		{"error级别记录失败语句", "error", 0, errors.New("boom"), "SQL执行失败"},
+		{"debug级别记录全部语句", "debug", 0, nil, `"msg":"SQL"`},
+		{"silent不输出", "silent", time.Second, errors.New("boom"), ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			handler, _ := NewHandler(&buf, "json", slog.LevelDebug)
+			ctx := WithLogger(context.Background(), slog.New(handler))
+
+			l, err := NewGormLogger(tc.level, 100)
+			if err != nil {
+				t.Fatalf("NewGormLogger() error = %v", err)
+			}
+			sql, _ := l.ParamsFilter(ctx, "SELECT * FROM users WHERE mobile = $1", "13812345678")
+			l.Trace(ctx, time.Now().Add(-tc.elapsed), func() (string, int64) { return sql, 1 }, tc.err)
+
+			out := buf.String()
+			if tc.want == "" {
+				if out != "" {
+					t.Errorf("不应输出日志: %s", out)
+				}
+				return
+			}
+			if !strings.Contains(out, tc.want) {
+				t.Errorf("日志缺少 %q: %s", tc.want, out)
+			}
+			if strings.Contains(out, "13812345678") {
+				t.Errorf("SQL日志包含参数值: %s", out)
+			}
+		})
+	}
+}
@@ -1,39 +1,97 @@
 package main
 
 import (
+	"context"
 	"log"
-	"net/http"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-contrib/secure"
 	"github.com/gin-gonic/gin"
-	"github.com/ulule/limiter/v3"
-	limiterGin "github.com/ulule/limiter/v3/drivers/middleware/gin"
-	"github.com/ulule/limiter/v3/drivers/store/memory"
 
+	"movingManager/config"
 	"movingManager/database"
+	"movingManager/event"
+	"movingManager/logging"
+	"movingManager/metrics"
+	"movingManager/middleware"
+	"movingManager/migrate"
+	"movingManager/ratelimit"
+	"movingManager/repository"
 	"movingManager/router"
+	"movingManager/service"
+	"movingManager/storage"
 )
 
 func main() {
+	// 加载应用配置
+	if err := config.InitConfig("config/app.yaml"); err != nil {
+		log.Fatalf("应用配置加载失败: %v", err)
+	}
+
+	// 初始化日志
+	if err := logging.Init(config.AppConfig.Log); err != nil {
+		log.Fatalf("日志初始化失败: %v", err)
+	}
+	gormLogger, err := logging.NewGormLogger(config.AppConfig.Log.SQLLevel, config.AppConfig.Log.SlowSQLMs)
+	if err != nil {
+		fatal("SQL日志初始化失败", err)
+	}
+	database.SetLogger(gormLogger)
+
 	// 初始化数据库
 	if err := database.InitDB(); err != nil {
-		log.Fatalf("数据库初始化失败: %v", err)
+		fatal("数据库初始化失败", err)
+	}
+	service.SetStore(repository.NewGormStore(database.DB))
+
+	// 数据库迁移子命令：movingManager migrate up|down|status
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrateCommand(os.Args[2:]))
+	}
+
+	// 表结构落后于程序版本时拒绝启动，避免读写不存在的列
+	pending, err := migrate.Pending(database.DB)
+	if err != nil {
+		fatal("检查数据库迁移失败", err)
+	}
+	if len(pending) > 0 {
+		slog.Error("存在未执行的数据库迁移，请先执行 migrate up", "pending", len(pending))
+		os.Exit(1)
+	}
+
+	// 扫码链接签名密钥必须配置，否则拒绝启动
+	if err := service.ValidateScanConfig(); err != nil {
+		fatal("扫码链接配置错误", err)
 	}
 
-	// 数据迁移
-	// migrate.Migrate()
+	// 初始化附件存储
+	if err := storage.InitStorage(config.AppConfig.Storage); err != nil {
+		fatal("附件存储初始化失败", err)
+	}
+
+	// 启动Webhook投递任务
+	workerCtx, stopWorker := context.WithCancel(context.Background())
+	workerDone := service.StartWebhookWorker(workerCtx)
 
-	// 创建Gin引擎
-	r := gin.Default()
+	// 创建Gin引擎，访问日志使用结构化日志
+	r := gin.New()
+	// 只信任配置的反向代理转发的客户端IP，避免伪造X-Forwarded-For绕过按IP限流
+	if err := r.SetTrustedProxies(config.AppConfig.Server.TrustedProxies); err != nil {
+		fatal("可信代理配置错误", err)
+	}
+	r.Use(middleware.RequestID(), middleware.AccessLog(), gin.Recovery())
 
 	// 配置CORS
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Request-ID"},
+		ExposeHeaders:    []string{"Content-Length", "X-Request-ID"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
@@ -49,19 +107,50 @@ func main() {
 		AllowedHosts:         []string{},
 	}))
 
-	// 配置API限流 (100次/分钟)
-	store := memory.NewStore()
-	rate := limiter.Rate{Period: 1 * time.Minute, Limit: 100}
-	limiterMiddleware := limiterGin.NewMiddleware(limiter.New(store, rate))
-	r.Use(limiterMiddleware)
+	// 请求指标
+	r.Use(middleware.Metrics())
+	if sqlDB, err := database.DB.DB(); err == nil {
+		if err := metrics.RegisterDBStats(sqlDB); err != nil {
+			slog.Warn("注册数据库连接池指标失败", "error", err)
+		}
+	}
+
+	// 健康检查和监控指标
+	router.RegisterProbeRoutes(r)
+
+	// 初始化限流，各路由组的限流规则在注册路由时应用
+	if err := ratelimit.Init(config.AppConfig.RateLimit); err != nil {
+		fatal("限流初始化失败", err)
+	}
 
 	// 注册路由
 	router.RegisterRoutes(r)
 
-	// 启动服务器
-	port := "0.0.0.0:8080"
-	log.Printf("服务器启动成功，监听端口: %s", port)
-	if err := r.Run(port); err != nil && err != http.ErrServerClosed {
-		log.Fatalf("服务器启动失败: %v", err)
+	// 启动服务器，收到SIGINT/SIGTERM后优雅退出
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	srv := newHTTPServer(r, config.AppConfig.Server)
+	// 关闭事件订阅，使SSE长连接结束而不阻塞退出
+	srv.RegisterOnShutdown(event.Default.Close)
+	serveErr := runHTTPServer(ctx, srv, config.AppConfig.Server)
+	if serveErr != nil {
+		slog.Error("服务器异常退出", "error", serveErr)
+	}
+
+	// 请求处理完成后再停止后台任务并关闭数据库连接
+	stopWorker()
+	<-workerDone
+	if err := database.Close(); err != nil {
+		slog.Error("关闭数据库连接失败", "error", err)
 	}
+	slog.Info("服务器已退出")
+	if serveErr != nil {
+		os.Exit(1)
+	}
+}
+
+// fatal 记录错误日志后退出进程
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"database/sql"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// namespace 指标名前缀
+const namespace = "moving_manager"
+
+// 扫码核销来源
+const (
+	ScanSourceApp    = "app"    // 登录后在应用内核销
+	ScanSourcePublic = "public" // 免登录扫码核销
+	ScanSourceSync   = "sync"   // 离线扫码批量同步
+)
+
+var (
+	// httpRequests 按路由统计的请求数
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP请求数，按方法、路由模板和状态码统计",
+	}, []string{"method", "route", "status"})
+
+	// httpDuration 按路由统计的请求耗时
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP请求耗时(秒)，按方法和路由模板统计",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	// pdfDuration PDF生成耗时
+	pdfDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "pdf_generation_duration_seconds",
+		Help:      "PDF生成耗时(秒)，按文档类型统计",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"document"})
+
+	// scans 扫码核销次数，rate(...[1m])*60 即每分钟扫码数
+	scans = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "scans_total",
+		Help:      "扫码核销次数，按来源统计",
+	}, []string{"source"})
+
+	// rateLimitRejections 被限流拒绝的请求数
+	rateLimitRejections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limit_rejections_total",
+		Help:      "被限流拒绝的请求数，按路由模板统计",
+	}, []string{"route"})
+)
+
+// ObserveRequest 记录一次HTTP请求
+func ObserveRequest(method, route string, status int, duration time.Duration) {
+	httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	httpDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+// ObservePDF 记录一次PDF生成耗时，配合defer使用：defer metrics.ObservePDF("manifest", time.Now())
+func ObservePDF(document string, start time.Time) {
+	pdfDuration.WithLabelValues(document).Observe(time.Since(start).Seconds())
+}
+
+// AddScans 累加扫码核销次数
+func AddScans(source string, n int) {
+	if n > 0 {
+		scans.WithLabelValues(source).Add(float64(n))
+	}
+}
+
+// IncRateLimitRejection 记录一次限流拒绝
+func IncRateLimitRejection(route string) {
+	rateLimitRejections.WithLabelValues(route).Inc()
+}
+
+// RegisterDBStats 注册数据库连接池指标
+func RegisterDBStats(db *sql.DB) error {
+	return prometheus.Register(collectors.NewDBStatsCollector(db, "main"))
+}
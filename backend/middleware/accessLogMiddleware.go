@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"movingManager/dto"
+	"movingManager/logging"
+)
+
+// quietRoutes 探测类路由，访问日志按debug级别记录
+var quietRoutes = map[string]bool{
+	"/healthz": true,
+	"/readyz":  true,
+	"/metrics": true,
+}
+
+// AccessLog 结构化访问日志中间件，需注册在 RequestID 之后
+// 将带请求ID的日志记录器放入请求上下文，请求结束后记录路由、状态码、耗时和用户UID
+// 只记录路径不记录查询参数，避免扫码令牌等写入日志
+func AccessLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		logger := slog.Default().With("request_id", c.GetString(dto.RequestIDKey))
+		c.Request = c.Request.WithContext(logging.WithLogger(c.Request.Context(), logger))
+
+		c.Next()
+
+		route := routeLabel(c)
+		status := c.Writer.Status()
+		attrs := []any{
+			"method", c.Request.Method,
+			"route", route,
+			"path", c.Request.URL.Path,
+			"status", status,
+			"latency_ms", time.Since(start).Milliseconds(),
+			"client_ip", c.ClientIP(),
+			"bytes", c.Writer.Size(),
+		}
+		if userUid := c.GetString("userUid"); userUid != "" {
+			attrs = append(attrs, "user_uid", userUid)
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, "errors", c.Errors.String())
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case quietRoutes[route]:
+			level = slog.LevelDebug
+		}
+		logger.Log(c.Request.Context(), level, "request", attrs...)
+	}
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"movingManager/logging"
+)
+
+func TestAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	handler, _ := logging.NewHandler(&buf, "json", slog.LevelInfo)
+	previous := slog.Default()
+	slog.SetDefault(slog.New(handler))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RequestID(), AccessLog())
+	r.GET("/scan/:uid", func(c *gin.Context) {
+		c.Set("userUid", "u1")
+		// 处理函数中的日志带上请求ID
+		logging.FromContext(c.Request.Context()).Info("handled")
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/scan/abc?token=secret-token", nil)
+	req.Header.Set(RequestIDHeader, "req-1")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("日志行数 = %d, want 2: %s", len(lines), buf.String())
+	}
+	for _, line := range lines {
+		if !strings.Contains(line, `"request_id":"req-1"`) {
+			t.Errorf("日志缺少请求ID: %s", line)
+		}
+	}
+	access := lines[1]
+	for _, want := range []string{`"route":"/scan/:uid"`, `"status":200`, `"user_uid":"u1"`, `"latency_ms"`} {
+		if !strings.Contains(access, want) {
+			t.Errorf("访问日志缺少 %s: %s", want, access)
+		}
+	}
+	if strings.Contains(access, "secret-token") {
+		t.Errorf("访问日志包含查询参数: %s", access)
+	}
+}
@@ -7,8 +7,9 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"movingManager/common"
-	"movingManager/database"
-	"movingManager/model"
+	"movingManager/dto"
+	"movingManager/logging"
+	"movingManager/service"
 )
 
 // AuthMiddleware 认证中间件，验证用户登录状态
@@ -17,10 +18,7 @@ func AuthMiddleware() gin.HandlerFunc {
 		// 获取Authorization请求头
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusOK, gin.H{
-				"code":    common.CodeUserNotLogin,
-				"message": common.CodeMessage[common.CodeUserNotLogin],
-			})
+			dto.WriteError(c, http.StatusUnauthorized, common.CodeUserNotLogin, common.CodeMessage[common.CodeUserNotLogin])
 			c.Abort()
 			return
 		}
@@ -28,22 +26,15 @@ func AuthMiddleware() gin.HandlerFunc {
 		// 验证token格式 (Bearer token)
 		tokenParts := strings.SplitN(authHeader, " ", 2)
 		if !(len(tokenParts) == 2 && tokenParts[0] == "Bearer") {
-			c.JSON(http.StatusOK, gin.H{
-				"code":    common.CodeUserNotLogin,
-				"message": common.CodeMessage[common.CodeUserNotLogin],
-			})
+			dto.WriteError(c, http.StatusUnauthorized, common.CodeUserNotLogin, common.CodeMessage[common.CodeUserNotLogin])
 			c.Abort()
 			return
 		}
 
 		// 查询用户是否存在
-		var user model.UserModel
-		db := database.DB
-		if err := db.Where("authorization_code = ? AND is_deleted = 0", tokenParts[1]).First(&user).Error; err != nil {
-			c.JSON(http.StatusOK, gin.H{
-				"code":    common.CodeUserNotRegistered,
-				"message": common.CodeMessage[common.CodeUserNotRegistered],
-			})
+		user, err := service.GetUserByAuthCode(tokenParts[1])
+		if err != nil {
+			dto.WriteError(c, http.StatusUnauthorized, common.CodeUserNotRegistered, common.CodeMessage[common.CodeUserNotRegistered])
 			c.Abort()
 			return
 		}
@@ -51,6 +42,9 @@ func AuthMiddleware() gin.HandlerFunc {
 		// 将用户信息存入上下文
 		c.Set("userUid", user.UserUid)
 		c.Set("userName", user.UserName)
+		// 后续日志带上用户UID
+		ctx := c.Request.Context()
+		c.Request = c.Request.WithContext(logging.WithLogger(ctx, logging.FromContext(ctx).With("user_uid", user.UserUid)))
 
 		c.Next()
 	}
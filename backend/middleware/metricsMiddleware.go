@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"movingManager/metrics"
+)
+
+// unmatchedRoute 未匹配任何路由的请求统一使用的路由标签，避免任意路径撑大指标数量
+const unmatchedRoute = "unmatched"
+
+// Metrics 请求指标中间件，按路由模板记录请求数和耗时
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		metrics.ObserveRequest(c.Request.Method, routeLabel(c), c.Writer.Status(), time.Since(start))
+	}
+}
+
+// routeLabel 请求对应的路由模板
+func routeLabel(c *gin.Context) string {
+	if route := c.FullPath(); route != "" {
+		return route
+	}
+	return unmatchedRoute
+}
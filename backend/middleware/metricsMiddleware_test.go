@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// requestCount 从默认注册表读取指定路由和状态码的请求数
+func requestCount(t *testing.T, route, status string) float64 {
+	t.Helper()
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != "moving_manager_http_requests_total" {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			labels := make(map[string]string)
+			for _, label := range m.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["route"] == route && labels["status"] == status {
+				return m.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
+
+func TestMetricsRouteLabel(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Metrics())
+	r.GET("/test/tag/:uid", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	cases := []struct {
+		path   string
+		route  string
+		status string
+	}{
+		{"/test/tag/a", "/test/tag/:uid", "204"},
+		{"/test/tag/b", "/test/tag/:uid", "204"},
+		{"/no/such/path", unmatchedRoute, "404"},
+	}
+	before := make(map[string]float64)
+	for _, tc := range cases {
+		before[tc.route] = requestCount(t, tc.route, tc.status)
+	}
+	for _, tc := range cases {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tc.path, nil))
+	}
+
+	// 同一路由模板的不同路径合并统计
+	if got := requestCount(t, "/test/tag/:uid", "204") - before["/test/tag/:uid"]; got != 2 {
+		t.Errorf("路由模板请求数增加 %v, want 2", got)
+	}
+	if got := requestCount(t, unmatchedRoute, "404") - before[unmatchedRoute]; got != 1 {
+		t.Errorf("未匹配路由请求数增加 %v, want 1", got)
+	}
+}
@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"movingManager/common"
+	"movingManager/dto"
+	"movingManager/logging"
+	"movingManager/metrics"
+	"movingManager/ratelimit"
+)
+
+// maxAuthBodySize 读取登录请求体用于限流计数的最大字节数
+const maxAuthBodySize = 4 << 10
+
+// RateLimitKey 从请求中提取限流计数键
+type RateLimitKey func(c *gin.Context) string
+
+// RateLimit 限流中间件，按规则对计数键限流，超过限额时返回429
+// 规则未初始化或已关闭时不限流；计数存储不可用时放行请求并记录日志
+func RateLimit(rule string, key RateLimitKey) gin.HandlerFunc {
+	l := ratelimit.Limiter(rule)
+	if l == nil {
+		return func(c *gin.Context) { c.Next() }
+	}
+	return func(c *gin.Context) {
+		result, err := l.Get(c, rule+":"+key(c))
+		if err != nil {
+			logging.FromContext(c.Request.Context()).Error("限流计数失败", "rule", rule, "error", err)
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.FormatInt(result.Limit, 10))
+		c.Header("X-RateLimit-Remaining", strconv.FormatInt(result.Remaining, 10))
+		c.Header("X-RateLimit-Reset", strconv.FormatInt(result.Reset, 10))
+		if result.Reached {
+			metrics.IncRateLimitRejection(routeLabel(c))
+			respondTooManyRequests(c)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// ClientIPKey 按客户端IP计数
+func ClientIPKey(c *gin.Context) string {
+	return c.ClientIP()
+}
+
+// UserKey 按登录用户UID计数，需注册在 AuthMiddleware 之后
+func UserKey(c *gin.Context) string {
+	if userUid := c.GetString("userUid"); userUid != "" {
+		return userUid
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// AuthMobileKey 按登录请求中的手机号计数，同一手机号从不同IP尝试也共享限额
+// 读取请求体后重新放回，不影响后续绑定参数
+func AuthMobileKey(c *gin.Context) string {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxAuthBodySize))
+	if err != nil {
+		return "ip:" + c.ClientIP()
+	}
+	c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), c.Request.Body))
+
+	var req struct {
+		Mobile string `json:"mobile"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil || req.Mobile == "" {
+		return "ip:" + c.ClientIP()
+	}
+	return req.Mobile
+}
+
+// respondTooManyRequests 返回限流响应，两个版本均使用429状态码便于客户端退避重试
+func respondTooManyRequests(c *gin.Context) {
+	message := common.CodeMessage[common.CodeTooManyRequests]
+	if dto.IsV2(c) {
+		dto.WriteError(c, http.StatusTooManyRequests, common.CodeTooManyRequests, message)
+		return
+	}
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"code":    common.CodeTooManyRequests,
+		"message": message,
+	})
+}
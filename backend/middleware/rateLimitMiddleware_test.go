@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"movingManager/config"
+	"movingManager/dto"
+	"movingManager/ratelimit"
+)
+
+func TestRateLimitByUser(t *testing.T) {
+	if err := ratelimit.Init(config.RateLimitConfig{Rules: map[string]string{ratelimit.RuleUser: "2-M"}}); err != nil {
+		t.Fatalf("ratelimit.Init() error = %v", err)
+	}
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(APIVersion(2), func(c *gin.Context) {
+		c.Set("userUid", c.GetHeader("X-Test-User"))
+	}, RateLimit(ratelimit.RuleUser, UserKey))
+	r.POST("/move/list", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	// 同一IP下的两个用户各自计数
+	steps := []struct {
+		user   string
+		status int
+	}{
+		{"u1", http.StatusOK},
+		{"u1", http.StatusOK},
+		{"u2", http.StatusOK},
+		{"u1", http.StatusTooManyRequests},
+		{"u2", http.StatusOK},
+	}
+	for i, step := range steps {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/move/list", nil)
+		req.Header.Set("X-Test-User", step.user)
+		r.ServeHTTP(w, req)
+		if w.Code != step.status {
+			t.Fatalf("第%d次请求(%s) 状态码 = %d, want %d", i+1, step.user, w.Code, step.status)
+		}
+		if step.status == http.StatusTooManyRequests && !strings.Contains(w.Body.String(), `"code":429`) {
+			t.Errorf("限流响应 = %s", w.Body.String())
+		}
+	}
+}
+
+func TestAuthMobileKeyKeepsBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	var key, mobile string
+	r.POST("/user/auth", func(c *gin.Context) {
+		key = AuthMobileKey(c)
+		var req struct {
+			Mobile string `json:"mobile"`
+		}
+		c.ShouldBindJSON(&req)
+		mobile = req.Mobile
+	})
+
+	cases := []struct {
+		body       string
+		wantKey    string
+		wantMobile string // 计数后处理函数仍能绑定到的手机号
+	}{
+		{`{"mobile":"13812345678"}`, "13812345678", "13812345678"},
+		{`not json`, "ip:192.0.2.1", ""},
+	}
+	for _, tc := range cases {
+		key, mobile = "", ""
+		req := httptest.NewRequest(http.MethodPost, "/user/auth", strings.NewReader(tc.body))
+		req.RemoteAddr = "192.0.2.1:1234"
+		r.ServeHTTP(httptest.NewRecorder(), req)
+		if key != tc.wantKey {
+			t.Errorf("AuthMobileKey(%s) = %q, want %q", tc.body, key, tc.wantKey)
+		}
+		if mobile != tc.wantMobile {
+			t.Errorf("请求体绑定手机号 = %q, want %q", mobile, tc.wantMobile)
+		}
+	}
+}
+
+func TestRateLimitWithoutInit(t *testing.T) {
+	if ratelimit.Limiter("unknown") != nil {
+		t.Fatal("未知规则不应有限流器")
+	}
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RateLimit("unknown", ClientIPKey))
+	r.GET("/", func(c *gin.Context) { c.String(http.StatusOK, c.GetString(dto.RequestIDKey)) })
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("未配置规则时状态码 = %d, want 200", w.Code)
+	}
+}
+
+// TestClientIPKeyIgnoresForwardedFor 未配置可信代理时，轮换X-Forwarded-For不能绕过按IP限流
+func TestClientIPKeyIgnoresForwardedFor(t *testing.T) {
+	if err := ratelimit.Init(config.RateLimitConfig{Rules: map[string]string{ratelimit.RuleAPIIP: "2-M"}}); err != nil {
+		t.Fatalf("ratelimit.Init() error = %v", err)
+	}
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	if err := r.SetTrustedProxies(nil); err != nil {
+		t.Fatalf("SetTrustedProxies() error = %v", err)
+	}
+	r.Use(RateLimit(ratelimit.RuleAPIIP, ClientIPKey))
+	r.POST("/move/list", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for i, want := range []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/move/list", nil)
+		req.RemoteAddr = "192.0.2.9:1234"
+		req.Header.Set("X-Forwarded-For", fmt.Sprintf("198.51.100.%d", i+1))
+		r.ServeHTTP(w, req)
+		if w.Code != want {
+			t.Fatalf("第%d次请求状态码 = %d, want %d", i+1, w.Code, want)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"movingManager/dto"
+)
+
+// RequestIDHeader 请求ID请求头/响应头
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID 请求ID中间件
+// 优先沿用上游传入的请求ID，否则生成新的UUID，并写入上下文和响应头
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" || len(requestID) > 64 {
+			requestID = uuid.New().String()
+		}
+		c.Set(dto.RequestIDKey, requestID)
+		c.Header(RequestIDHeader, requestID)
+		c.Next()
+	}
+}
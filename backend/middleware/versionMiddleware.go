@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"movingManager/dto"
+)
+
+// APIVersion 接口版本中间件，将版本号写入上下文
+// 控制器和错误处理据此选择v1原有格式或v2统一响应信封
+func APIVersion(version int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(dto.APIVersionKey, version)
+		c.Next()
+	}
+}
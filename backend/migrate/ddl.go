@@ -0,0 +1,102 @@
+package migrate
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// baseColumns 所有表共有的时间戳和软删除列，与 model.BaseModel 对应
+const baseColumns = "created_at bigint, updated_at bigint, is_deleted bigint DEFAULT 0, delete_at bigint"
+
+// step 迁移中的一个DDL步骤
+// 语句只使用PostgreSQL和SQLite都支持的语法，表结构不随模型变化
+type step func(tx *gorm.DB) error
+
+// run 按顺序执行多个步骤
+func run(steps ...step) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		for _, s := range steps {
+			if err := s(tx); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// createTable 创建带自增主键和公共列的表，columns 为业务列定义
+// 表已存在时(此前由AutoMigrate建表)跳过
+func createTable(table, columns string) step {
+	return func(tx *gorm.DB) error {
+		id, err := idColumn(tx)
+		if err != nil {
+			return err
+		}
+		return tx.Exec(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (id %s, %s, %s)", table, id, columns, baseColumns)).Error
+	}
+}
+
+// dropTable 删除表
+func dropTable(table string) step {
+	return func(tx *gorm.DB) error {
+		return tx.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s", table)).Error
+	}
+}
+
+// createIndex 创建普通索引，索引名与AutoMigrate生成的一致
+func createIndex(name, table, columns string) step {
+	return func(tx *gorm.DB) error {
+		return tx.Exec(fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s (%s)", name, table, columns)).Error
+	}
+}
+
+// createUniqueIndex 创建唯一索引
+func createUniqueIndex(name, table, columns string) step {
+	return func(tx *gorm.DB) error {
+		return tx.Exec(fmt.Sprintf("CREATE UNIQUE INDEX IF NOT EXISTS %s ON %s (%s)", name, table, columns)).Error
+	}
+}
+
+// dropIndex 删除索引，SQLite删除列前需先删除该列上的索引
+func dropIndex(name string) step {
+	return func(tx *gorm.DB) error {
+		return tx.Exec(fmt.Sprintf("DROP INDEX IF EXISTS %s", name)).Error
+	}
+}
+
+// addColumn 为已有表添加列，列已存在时跳过
+func addColumn(table, column, definition string) step {
+	return func(tx *gorm.DB) error {
+		if tx.Migrator().HasColumn(table, column) {
+			return nil
+		}
+		return tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)).Error
+	}
+}
+
+// dropColumn 删除列
+func dropColumn(table, column string) step {
+	return func(tx *gorm.DB) error {
+		return tx.Exec(fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", table, column)).Error
+	}
+}
+
+// idStart 设置表的自增ID起始值
+func idStart(table string) step {
+	return func(tx *gorm.DB) error {
+		return setIDStart(tx, table)
+	}
+}
+
+// idColumn 自增主键列的定义
+func idColumn(tx *gorm.DB) (string, error) {
+	switch tx.Dialector.Name() {
+	case "postgres":
+		return "bigserial PRIMARY KEY", nil
+	case "sqlite":
+		return "integer PRIMARY KEY AUTOINCREMENT", nil
+	default:
+		return "", fmt.Errorf("不支持的数据库驱动: %s", tx.Dialector.Name())
+	}
+}
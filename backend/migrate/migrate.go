@@ -2,45 +2,157 @@ package migrate
 
 import (
 	"fmt"
-	"movingManager/database"
-	"movingManager/model"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
 )
 
-// Migrate 执行数据库迁移
-func Migrate() {
-	// 自动迁移数据表结构
-	db := database.DB
-	err := db.AutoMigrate(
-		&model.UserModel{},
-		&model.MoveModel{},
-		&model.TagModel{},
-	)
+// Migration 一个版本的数据库迁移
+// Up 和 Down 在同一个事务中执行并记录版本，失败时整体回滚
+type Migration struct {
+	Version int                     // 版本号，按从小到大的顺序执行
+	Name    string                  // 迁移名称
+	Up      func(tx *gorm.DB) error // 升级
+	Down    func(tx *gorm.DB) error // 回滚
+}
+
+// SchemaMigration 已执行的迁移记录表模型
+type SchemaMigration struct {
+	Version   int    `gorm:"column:version;primaryKey;autoIncrement:false" json:"version"` // 版本号
+	Name      string `gorm:"column:name;size:100" json:"name"`                             // 迁移名称
+	AppliedAt int64  `gorm:"column:applied_at" json:"applied_at"`                          // 执行时间戳
+}
+
+// TableName 设置表名
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Status 迁移的执行状态
+type Status struct {
+	Version   int    // 版本号
+	Name      string // 迁移名称
+	Applied   bool   // 是否已执行
+	AppliedAt int64  // 执行时间戳
+}
+
+// Up 按版本顺序执行所有未执行的迁移，返回本次执行的迁移
+func Up(db *gorm.DB) ([]Migration, error) {
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return nil, fmt.Errorf("创建迁移记录表失败: %v", err)
+	}
+	applied, err := appliedVersions(db)
 	if err != nil {
-		// 处理迁移错误
-		fmt.Printf("自动迁移失败: %v\n", err)
-		return
-	}
-
-	// 设置ID自增起始值为10000（PostgreSQL）
-	// 先检查序列是否存在，不存在则创建
-	sequences := []string{"users_id_seq", "moves_id_seq", "tags_id_seq"}
-	for _, seq := range sequences {
-		// 检查序列是否存在
-		var exists bool
-		err := db.Raw("SELECT EXISTS (SELECT 1 FROM pg_class WHERE relname = ?)", seq).Scan(&exists).Error
-		if err != nil {
-			fmt.Printf("检查序列 %s 失败: %v\n", seq, err)
+		return nil, err
+	}
+
+	var done []Migration
+	for _, m := range migrations {
+		if _, ok := applied[m.Version]; ok {
 			continue
 		}
-		
-		if exists {
-			// 重置序列
-			err := db.Exec(fmt.Sprintf("ALTER SEQUENCE %s RESTART WITH 10000", seq)).Error
-			if err != nil {
-				fmt.Printf("重置序列 %s 失败: %v\n", seq, err)
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Up(tx); err != nil {
+				return err
+			}
+			// 版本号为主键，并发执行同一迁移时后提交的一方会失败回滚
+			return tx.Create(&SchemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now().Unix()}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("执行迁移 %d_%s 失败: %v", m.Version, m.Name, err)
+		}
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+// Down 按版本倒序回滚最近执行的 steps 个迁移，返回本次回滚的迁移
+func Down(db *gorm.DB, steps int) ([]Migration, error) {
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]int, 0, len(applied))
+	for version := range applied {
+		versions = append(versions, version)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+
+	var done []Migration
+	for _, version := range versions {
+		if len(done) >= steps {
+			break
+		}
+		m, ok := findMigration(version)
+		if !ok {
+			return done, fmt.Errorf("数据库中的迁移版本 %d 在当前程序中不存在，无法回滚", version)
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Down(tx); err != nil {
+				return err
 			}
-		} else {
-			fmt.Printf("序列 %s 不存在，跳过重置\n", seq)
+			return tx.Delete(&SchemaMigration{}, "version = ?", m.Version).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("回滚迁移 %d_%s 失败: %v", m.Version, m.Name, err)
+		}
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+// GetStatus 查询所有迁移的执行状态，按版本顺序排列
+func GetStatus(db *gorm.DB) ([]Status, error) {
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]Status, 0, len(migrations))
+	for _, m := range migrations {
+		record, ok := applied[m.Version]
+		statuses = append(statuses, Status{Version: m.Version, Name: m.Name, Applied: ok, AppliedAt: record.AppliedAt})
+	}
+	return statuses, nil
+}
+
+// Pending 查询未执行的迁移
+func Pending(db *gorm.DB) ([]Migration, error) {
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, m := range migrations {
+		if _, ok := applied[m.Version]; !ok {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// appliedVersions 查询已执行的迁移，按版本号索引；迁移记录表不存在时视为未执行任何迁移
+func appliedVersions(db *gorm.DB) (map[int]SchemaMigration, error) {
+	if !db.Migrator().HasTable(&SchemaMigration{}) {
+		return map[int]SchemaMigration{}, nil
+	}
+	var records []SchemaMigration
+	if err := db.Find(&records).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[int]SchemaMigration, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+// findMigration 根据版本号查找迁移
+func findMigration(version int) (Migration, bool) {
+	for _, m := range migrations {
+		if m.Version == version {
+			return m, true
 		}
 	}
+	return Migration{}, false
 }
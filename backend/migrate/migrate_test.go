@@ -0,0 +1,132 @@
+package migrate
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"movingManager/database"
+	"movingManager/model"
+)
+
+// openTestDB 在临时目录中创建空的SQLite数据库
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(database.SQLiteDialector(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+func TestMigrationVersionsIncrease(t *testing.T) {
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].Version <= migrations[i-1].Version {
+			t.Errorf("迁移版本 %d 必须大于 %d", migrations[i].Version, migrations[i-1].Version)
+		}
+	}
+}
+
+func TestUpDownStatus(t *testing.T) {
+	db := openTestDB(t)
+
+	pending, err := Pending(db)
+	if err != nil || len(pending) != len(migrations) {
+		t.Fatalf("Pending() = %d, %v, want %d", len(pending), err, len(migrations))
+	}
+
+	done, err := Up(db)
+	if err != nil || len(done) != len(migrations) {
+		t.Fatalf("Up() = %d, %v", len(done), err)
+	}
+	if done, err := Up(db); err != nil || len(done) != 0 {
+		t.Errorf("重复 Up() = %d, %v, want 0", len(done), err)
+	}
+
+	statuses, err := GetStatus(db)
+	if err != nil {
+		t.Fatalf("GetStatus() error = %v", err)
+	}
+	for _, s := range statuses {
+		if !s.Applied || s.AppliedAt == 0 {
+			t.Errorf("迁移 %d 状态 = %+v, want 已执行", s.Version, s)
+		}
+	}
+
+	move := model.MoveModel{UserUid: "u"}
+	if err := db.Create(&move).Error; err != nil {
+		t.Fatalf("创建记录失败: %v", err)
+	}
+	if move.ID != idStartValue {
+		t.Errorf("首条记录ID = %d, want %d", move.ID, idStartValue)
+	}
+
+	if done, err := Down(db, len(migrations)); err != nil || len(done) != len(migrations) {
+		t.Fatalf("Down() = %d, %v", len(done), err)
+	}
+	if db.Migrator().HasTable(&model.MoveModel{}) {
+		t.Error("回滚全部迁移后 moves 表仍然存在")
+	}
+	if pending, _ := Pending(db); len(pending) != len(migrations) {
+		t.Errorf("回滚后 Pending() = %d, want %d", len(pending), len(migrations))
+	}
+}
+
+// 迁移使用显式DDL，模型增加字段或索引而未追加迁移时失败
+func TestSchemaMatchesModels(t *testing.T) {
+	db := openTestDB(t)
+	if _, err := Up(db); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	models := []interface{}{
+		&model.UserModel{}, &model.MoveModel{}, &model.TagModel{}, &model.RoomModel{}, &model.ItemModel{}, &model.IssueModel{},
+		&model.AttachmentModel{}, &model.ScanEventModel{}, &model.WebhookModel{}, &model.WebhookDeliveryModel{}, &model.HandoverModel{},
+	}
+	for _, m := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(m); err != nil {
+			t.Fatalf("解析模型失败: %v", err)
+		}
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName != "" && !db.Migrator().HasColumn(m, field.DBName) {
+				t.Errorf("%s 缺少列 %s", stmt.Schema.Table, field.DBName)
+			}
+		}
+		for _, index := range stmt.Schema.ParseIndexes() {
+			if !db.Migrator().HasIndex(m, index.Name) {
+				t.Errorf("%s 缺少索引 %s", stmt.Schema.Table, index.Name)
+			}
+		}
+	}
+}
+
+func TestSetIDStartKeepsExistingSequence(t *testing.T) {
+	db := openTestDB(t)
+	if _, err := Up(db); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := db.Create(&model.TagModel{UserUid: "u"}).Error; err != nil {
+			t.Fatalf("创建记录失败: %v", err)
+		}
+	}
+
+	// 再次设置起始值不能回退序列
+	if err := setIDStart(db, "tags"); err != nil {
+		t.Fatalf("setIDStart() error = %v", err)
+	}
+	tag := model.TagModel{UserUid: "u"}
+	if err := db.Create(&tag).Error; err != nil {
+		t.Fatalf("创建记录失败: %v", err)
+	}
+	if tag.ID != idStartValue+2 {
+		t.Errorf("ID = %d, want %d", tag.ID, idStartValue+2)
+	}
+}
@@ -0,0 +1,211 @@
+package migrate
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// idStartValue ID自增起始值
+const idStartValue = 10000
+
+// migrations 所有迁移，版本号必须严格递增
+// 已发布的迁移不要修改，表结构变化追加新的版本；每个版本使用显式DDL，不引用模型结构体，
+// 模型增加字段时需同时追加迁移
+var migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "create_tables",
+		// 已有数据库(此前由AutoMigrate建表)执行时跳过已存在的表和索引
+		Up: run(
+			createTable("users", "user_uid varchar(36), mobile varchar(20), user_name varchar(50), authorization_code varchar(100), salt varchar(50)"),
+			createUniqueIndex("idx_users_user_uid", "users", "user_uid"),
+			createUniqueIndex("idx_users_mobile", "users", "mobile"),
+			createTable("moves", "move_uid varchar(36), user_uid varchar(36), move_at bigint, start_location varchar(100), end_location varchar(100), "+
+				"tag_count bigint DEFAULT 0, verified_tag_count bigint DEFAULT 0, unverified_tag_count bigint DEFAULT 0, is_completed bigint DEFAULT 0, remark varchar(500)"),
+			createUniqueIndex("idx_moves_move_uid", "moves", "move_uid"),
+			createIndex("idx_moves_user_uid", "moves", "user_uid"),
+			createTable("tags", "tag_uid varchar(36), user_uid varchar(36), move_uid varchar(36), tag_name varchar(100), remark varchar(500), is_verified bigint DEFAULT 0"),
+			createUniqueIndex("idx_tags_tag_uid", "tags", "tag_uid"),
+			createIndex("idx_tags_user_uid", "tags", "user_uid"),
+			createIndex("idx_tags_move_uid", "tags", "move_uid"),
+		),
+		Down: run(dropTable("tags"), dropTable("moves"), dropTable("users")),
+	},
+	{
+		Version: 2,
+		Name:    "set_id_start",
+		Up:      run(idStart("users"), idStart("moves"), idStart("tags")),
+		// 自增序列回退可能与已有记录冲突，回滚时保持不变
+		Down: run(),
+	},
+	{
+		Version: 3,
+		Name:    "add_rooms",
+		Up: run(
+			createTable("rooms", "room_uid varchar(36), user_uid varchar(36), move_uid varchar(36), side bigint DEFAULT 2, room_name varchar(50), color varchar(7), sort bigint DEFAULT 0"),
+			createUniqueIndex("idx_rooms_room_uid", "rooms", "room_uid"),
+			createIndex("idx_rooms_user_uid", "rooms", "user_uid"),
+			createIndex("idx_rooms_move_uid", "rooms", "move_uid"),
+			idStart("rooms"),
+			addColumn("tags", "origin_room_uid", "varchar(36)"),
+			addColumn("tags", "dest_room_uid", "varchar(36)"),
+			createIndex("idx_tags_dest_room_uid", "tags", "dest_room_uid"),
+		),
+		Down: run(
+			dropIndex("idx_tags_dest_room_uid"),
+			dropColumn("tags", "dest_room_uid"),
+			dropColumn("tags", "origin_room_uid"),
+			dropTable("rooms"),
+		),
+	},
+	{
+		Version: 4,
+		Name:    "add_parent_tag",
+		Up: run(
+			addColumn("tags", "parent_tag_uid", "varchar(36)"),
+			createIndex("idx_tags_parent_tag_uid", "tags", "parent_tag_uid"),
+		),
+		Down: run(dropIndex("idx_tags_parent_tag_uid"), dropColumn("tags", "parent_tag_uid")),
+	},
+	{
+		Version: 5,
+		Name:    "add_items_and_issues",
+		Up: run(
+			createTable("items", "item_uid varchar(36), user_uid varchar(36), move_uid varchar(36), tag_uid varchar(36), item_name varchar(100), quantity bigint DEFAULT 1, remark varchar(500)"),
+			createUniqueIndex("idx_items_item_uid", "items", "item_uid"),
+			createIndex("idx_items_user_uid", "items", "user_uid"),
+			createIndex("idx_items_move_uid", "items", "move_uid"),
+			createIndex("idx_items_tag_uid", "items", "tag_uid"),
+			idStart("items"),
+			createTable("issues", "issue_uid varchar(36), user_uid varchar(36), move_uid varchar(36), tag_uid varchar(36), item_uid varchar(36), issue_type bigint, "+
+				"status bigint DEFAULT 0, declared_value bigint DEFAULT 0, remark varchar(500), photo_refs text, resolved_at bigint DEFAULT 0"),
+			createUniqueIndex("idx_issues_issue_uid", "issues", "issue_uid"),
+			createIndex("idx_issues_user_uid", "issues", "user_uid"),
+			createIndex("idx_issues_move_uid", "issues", "move_uid"),
+			createIndex("idx_issues_tag_uid", "issues", "tag_uid"),
+			idStart("issues"),
+		),
+		Down: run(dropTable("issues"), dropTable("items")),
+	},
+	{
+		Version: 6,
+		Name:    "add_attachments",
+		Up: run(
+			createTable("attachments", "attachment_uid varchar(36), user_uid varchar(36), move_uid varchar(36), tag_uid varchar(36), owner_type varchar(10), "+
+				"file_name varchar(255), content_type varchar(50), size bigint DEFAULT 0, thumb_size bigint DEFAULT 0, width bigint DEFAULT 0, height bigint DEFAULT 0, "+
+				"storage_key varchar(255), thumb_key varchar(255)"),
+			createUniqueIndex("idx_attachments_attachment_uid", "attachments", "attachment_uid"),
+			createIndex("idx_attachments_user_uid", "attachments", "user_uid"),
+			createIndex("idx_attachments_move_uid", "attachments", "move_uid"),
+			createIndex("idx_attachments_tag_uid", "attachments", "tag_uid"),
+			idStart("attachments"),
+		),
+		Down: run(dropTable("attachments")),
+	},
+	{
+		Version: 7,
+		Name:    "add_declared_values",
+		Up: run(
+			addColumn("tags", "declared_value", "bigint DEFAULT 0"),
+			addColumn("tags", "currency", "varchar(3) DEFAULT 'CNY'"),
+			addColumn("items", "declared_value", "bigint DEFAULT 0"),
+			addColumn("items", "currency", "varchar(3) DEFAULT 'CNY'"),
+		),
+		Down: run(
+			dropColumn("items", "currency"),
+			dropColumn("items", "declared_value"),
+			dropColumn("tags", "currency"),
+			dropColumn("tags", "declared_value"),
+		),
+	},
+	{
+		Version: 8,
+		Name:    "add_anonymous_verify",
+		Up: run(
+			addColumn("moves", "allow_anonymous_verify", "bigint DEFAULT 0"),
+			addColumn("tags", "verified_by", "varchar(50)"),
+			addColumn("tags", "verified_at", "bigint DEFAULT 0"),
+		),
+		Down: run(
+			dropColumn("tags", "verified_at"),
+			dropColumn("tags", "verified_by"),
+			dropColumn("moves", "allow_anonymous_verify"),
+		),
+	},
+	{
+		Version: 9,
+		Name:    "add_scan_events",
+		Up: run(
+			createTable("scan_events", "event_uid varchar(64), user_uid varchar(36), tag_uid varchar(36), move_uid varchar(36), is_verified bigint DEFAULT 0, "+
+				"client_at bigint DEFAULT 0, result bigint DEFAULT 0, message varchar(200)"),
+			createUniqueIndex("idx_scan_events_user_event", "scan_events", "event_uid, user_uid"),
+			createIndex("idx_scan_events_tag_uid", "scan_events", "tag_uid"),
+			idStart("scan_events"),
+			addColumn("tags", "state_changed_at", "bigint DEFAULT 0"),
+			addColumn("tags", "state_version", "bigint DEFAULT 0"),
+		),
+		Down: run(
+			dropColumn("tags", "state_version"),
+			dropColumn("tags", "state_changed_at"),
+			dropTable("scan_events"),
+		),
+	},
+	{
+		Version: 10,
+		Name:    "add_webhooks",
+		Up: run(
+			createTable("webhooks", "webhook_uid varchar(36), user_uid varchar(36), url varchar(500), secret varchar(64), event_types text, is_enabled bigint DEFAULT 1, remark varchar(200)"),
+			createUniqueIndex("idx_webhooks_webhook_uid", "webhooks", "webhook_uid"),
+			createIndex("idx_webhooks_user_uid", "webhooks", "user_uid"),
+			idStart("webhooks"),
+			createTable("webhook_deliveries", "delivery_uid varchar(36), webhook_uid varchar(36), user_uid varchar(36), event_type varchar(50), payload text, "+
+				"status bigint DEFAULT 0, attempts bigint DEFAULT 0, next_attempt_at bigint DEFAULT 0, last_status_code bigint DEFAULT 0, last_error varchar(500), delivered_at bigint DEFAULT 0"),
+			createUniqueIndex("idx_webhook_deliveries_delivery_uid", "webhook_deliveries", "delivery_uid"),
+			createIndex("idx_webhook_deliveries_webhook_uid", "webhook_deliveries", "webhook_uid"),
+			createIndex("idx_webhook_deliveries_user_uid", "webhook_deliveries", "user_uid"),
+			createIndex("idx_deliveries_due", "webhook_deliveries", "status, next_attempt_at"),
+			idStart("webhook_deliveries"),
+		),
+		Down: run(dropTable("webhook_deliveries"), dropTable("webhooks")),
+	},
+	{
+		Version: 11,
+		Name:    "add_handovers",
+		Up: run(
+			createTable("handovers", "handover_uid varchar(36), user_uid varchar(36), move_uid varchar(36), receiver_name varchar(50), signature_key varchar(255), "+
+				"handed_at bigint DEFAULT 0, tag_count bigint DEFAULT 0, received_count bigint DEFAULT 0, missing_tags text, remark varchar(500)"),
+			createUniqueIndex("idx_handovers_handover_uid", "handovers", "handover_uid"),
+			createIndex("idx_handovers_user_uid", "handovers", "user_uid"),
+			createIndex("idx_handovers_move_uid", "handovers", "move_uid"),
+			idStart("handovers"),
+		),
+		Down: run(dropTable("handovers")),
+	},
+}
+
+// setIDStart 将表的下一个自增ID设置为起始值，支持PostgreSQL和SQLite
+// 已有ID达到起始值的表不做调整，避免回退序列造成主键冲突
+func setIDStart(tx *gorm.DB, table string) error {
+	var maxID int64
+	if err := tx.Table(table).Select("COALESCE(MAX(id), 0)").Scan(&maxID).Error; err != nil {
+		return err
+	}
+	if maxID >= idStartValue {
+		return nil
+	}
+
+	switch tx.Dialector.Name() {
+	case "postgres":
+		// is_called=false 表示下一次nextval直接返回该值
+		return tx.Exec("SELECT setval(pg_get_serial_sequence(?, 'id'), ?, false)", table, idStartValue).Error
+	case "sqlite":
+		// AUTOINCREMENT 表的当前序列值记录在 sqlite_sequence 中，下一个ID为 seq+1
+		if err := tx.Exec("DELETE FROM sqlite_sequence WHERE name = ?", table).Error; err != nil {
+			return err
+		}
+		return tx.Exec("INSERT INTO sqlite_sequence (name, seq) VALUES (?, ?)", table, idStartValue-1).Error
+	default:
+		return fmt.Errorf("不支持的数据库驱动: %s", tx.Dialector.Name())
+	}
+}
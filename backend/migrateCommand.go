@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"movingManager/database"
+	"movingManager/migrate"
+)
+
+// migrateUsage 迁移子命令用法
+const migrateUsage = `用法: movingManager migrate <命令>
+
+命令:
+  up         执行所有未执行的迁移
+  down [n]   回滚最近执行的n个迁移(默认1个)
+  status     查看迁移执行状态`
+
+// runMigrateCommand 执行 migrate 子命令，返回进程退出码
+func runMigrateCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	db := database.DB
+	switch args[0] {
+	case "up":
+		done, err := migrate.Up(db)
+		for _, m := range done {
+			fmt.Printf("已执行 %d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if len(done) == 0 {
+			fmt.Println("没有需要执行的迁移")
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n <= 0 {
+				fmt.Fprintln(os.Stderr, "回滚数量必须是正整数")
+				return 2
+			}
+			steps = n
+		}
+		done, err := migrate.Down(db, steps)
+		for _, m := range done {
+			fmt.Printf("已回滚 %d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if len(done) == 0 {
+			fmt.Println("没有可回滚的迁移")
+		}
+	case "status":
+		statuses, err := migrate.GetStatus(db)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		for _, s := range statuses {
+			state := "未执行"
+			if s.Applied {
+				state = "已执行 " + time.Unix(s.AppliedAt, 0).Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%4d  %-30s %s\n", s.Version, s.Name, state)
+		}
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+	return 0
+}
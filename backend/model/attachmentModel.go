@@ -0,0 +1,112 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"movingManager/database"
+)
+
+// 附件归属类型
+const (
+	AttachmentOwnerMove = "move" // 搬运附件
+	AttachmentOwnerTag  = "tag"  // 标签附件
+)
+
+// AttachmentModel 附件表模型
+// 存储搬运或标签的照片附件元数据，文件内容保存在存储后端
+type AttachmentModel struct {
+	ID            uint   `gorm:"primarykey;autoIncrement" json:"id"`                              // 主键ID
+	AttachmentUid string `gorm:"column:attachment_uid;uniqueIndex;size:36" json:"attachment_uid"` // 附件唯一标识
+	UserUid       string `gorm:"column:user_uid;index;size:36" json:"user_uid"`                   // 所属用户UID
+	MoveUid       string `gorm:"column:move_uid;index;size:36" json:"move_uid"`                   // 所属搬运UID
+	TagUid        string `gorm:"column:tag_uid;index;size:36" json:"tag_uid"`                     // 所属标签UID(搬运附件为空)
+	OwnerType     string `gorm:"column:owner_type;size:10" json:"owner_type"`                     // 归属类型(move,tag)
+	FileName      string `gorm:"column:file_name;size:255" json:"file_name"`                      // 原始文件名
+	ContentType   string `gorm:"column:content_type;size:50" json:"content_type"`                 // 文件类型
+	Size          int64  `gorm:"column:size;default:0" json:"size"`                               // 原图大小(字节)
+	ThumbSize     int64  `gorm:"column:thumb_size;default:0" json:"thumb_size"`                   // 缩略图大小(字节)
+	Width         int    `gorm:"column:width;default:0" json:"width"`                             // 原图宽度(像素)
+	Height        int    `gorm:"column:height;default:0" json:"height"`                           // 原图高度(像素)
+	StorageKey    string `gorm:"column:storage_key;size:255" json:"-"`                            // 原图存储路径
+	ThumbKey      string `gorm:"column:thumb_key;size:255" json:"-"`                              // 缩略图存储路径
+	BaseModel            // 嵌入基础模型
+}
+
+// TableName 设置表名
+func (a *AttachmentModel) TableName() string {
+	return "attachments"
+}
+
+// BeforeCreate 创建前钩子：生成UUID作为附件唯一标识
+func (a *AttachmentModel) BeforeCreate(tx *gorm.DB) error {
+	if a.AttachmentUid == "" {
+		a.AttachmentUid = uuid.New().String()
+	}
+	return nil
+}
+
+// Create 插入附件记录到数据库
+func (a *AttachmentModel) Create() error {
+	return database.DB.Create(a).Error
+}
+
+// CreateTx 在事务中插入附件记录
+func (a *AttachmentModel) CreateTx(tx *gorm.DB) error {
+	return tx.Create(a).Error
+}
+
+// GetByUID 根据用户UID和附件UID查询未删除记录
+func (a *AttachmentModel) GetByUID(userUid, attachmentUid string) error {
+	return database.DB.Where("user_uid = ? AND attachment_uid = ? AND is_deleted = 0", userUid, attachmentUid).First(a).Error
+}
+
+// MarkDeleted 标记附件已删除
+func (a *AttachmentModel) MarkDeleted() error {
+	a.IsDeleted = 1
+	a.DeletedAt = time.Now().Unix()
+	return database.DB.Save(a).Error
+}
+
+// ListByOwner 获取搬运或标签下的未删除附件
+// tagUid 为空时返回搬运本身的附件（不含标签附件）
+func (a *AttachmentModel) ListByOwner(userUid, moveUid, tagUid string) ([]AttachmentModel, error) {
+	var attachments []AttachmentModel
+	db := database.DB.Where("user_uid = ? AND move_uid = ? AND is_deleted = 0", userUid, moveUid)
+	if tagUid == "" {
+		db = db.Where("owner_type = ?", AttachmentOwnerMove)
+	} else {
+		db = db.Where("owner_type = ? AND tag_uid = ?", AttachmentOwnerTag, tagUid)
+	}
+	if err := db.Order("created_at asc").Find(&attachments).Error; err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
+
+// SumUsageByUser 统计用户未删除附件占用的存储空间(字节)
+func (a *AttachmentModel) SumUsageByUser(userUid string) (int64, error) {
+	return a.SumUsageByUserTx(database.DB, userUid)
+}
+
+// SumUsageByUserTx 在事务中统计用户未删除附件占用的存储空间(字节)
+func (a *AttachmentModel) SumUsageByUserTx(tx *gorm.DB, userUid string) (int64, error) {
+	var total int64
+	err := tx.Model(&AttachmentModel{}).
+		Select("COALESCE(SUM(size + thumb_size), 0)").
+		Where("user_uid = ? AND is_deleted = 0", userUid).
+		Scan(&total).Error
+	return total, err
+}
+
+// ListAllByUser 获取用户的全部未删除附件，用于账户备份
+// 已删除附件的文件已从存储中移除，不参与备份
+func (a *AttachmentModel) ListAllByUser(userUid string) ([]AttachmentModel, error) {
+	var attachments []AttachmentModel
+	if err := database.DB.Where("user_uid = ? AND is_deleted = 0", userUid).Order("id asc").Find(&attachments).Error; err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
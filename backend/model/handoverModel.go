@@ -0,0 +1,74 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"movingManager/database"
+)
+
+// HandoverMissingTag 交接时未送达的标签快照
+// 保存交接时刻的编号和名称，标签之后被修改或删除也不影响回执
+type HandoverMissingTag struct {
+	TagUid    string `json:"tag_uid"`
+	TagNumber int    `json:"tag_number"`
+	TagName   string `json:"tag_name"`
+}
+
+// HandoverModel 交接记录表模型
+// 记录目的地客户签收时的收货人、签名和当时的标签数量，用于生成送达回执
+type HandoverModel struct {
+	ID            uint                 `gorm:"primarykey;autoIncrement" json:"id"`                                // 主键ID
+	HandoverUid   string               `gorm:"column:handover_uid;uniqueIndex;size:36" json:"handover_uid"`       // 交接唯一标识
+	UserUid       string               `gorm:"column:user_uid;index;size:36" json:"user_uid"`                     // 所属用户UID
+	MoveUid       string               `gorm:"column:move_uid;index;size:36" json:"move_uid"`                     // 所属搬运UID
+	ReceiverName  string               `gorm:"column:receiver_name;size:50" json:"receiver_name"`                 // 收货人姓名
+	SignatureKey  string               `gorm:"column:signature_key;size:255" json:"-"`                            // 签名图片存储路径
+	HandedAt      int64                `gorm:"column:handed_at;default:0" json:"handed_at"`                       // 交接时间戳
+	TagCount      int                  `gorm:"column:tag_count;default:0" json:"tag_count"`                       // 交接时的标签总数
+	ReceivedCount int                  `gorm:"column:received_count;default:0" json:"received_count"`             // 交接时已送达(已核销)的标签数
+	MissingTags   []HandoverMissingTag `gorm:"column:missing_tags;type:text;serializer:json" json:"missing_tags"` // 交接时未送达的标签
+	Remark        string               `gorm:"column:remark;size:500" json:"remark"`                              // 备注
+	BaseModel                          // 嵌入基础模型
+}
+
+// TableName 设置表名
+func (h *HandoverModel) TableName() string {
+	return "handovers"
+}
+
+// BeforeCreate 创建前钩子：生成UUID作为交接唯一标识
+func (h *HandoverModel) BeforeCreate(tx *gorm.DB) error {
+	if h.HandoverUid == "" {
+		h.HandoverUid = uuid.New().String()
+	}
+	return nil
+}
+
+// Create 插入交接记录到数据库
+func (h *HandoverModel) Create() error {
+	return database.DB.Create(h).Error
+}
+
+// GetByUID 根据用户UID和交接UID查询未删除记录
+func (h *HandoverModel) GetByUID(userUid, handoverUid string) error {
+	return database.DB.Where("user_uid = ? AND handover_uid = ? AND is_deleted = 0", userUid, handoverUid).First(h).Error
+}
+
+// ListByMove 获取搬运下的未删除交接记录，按交接时间倒序
+func (h *HandoverModel) ListByMove(userUid, moveUid string) ([]HandoverModel, error) {
+	var handovers []HandoverModel
+	if err := database.DB.Where("user_uid = ? AND move_uid = ? AND is_deleted = 0", userUid, moveUid).Order("handed_at desc, id desc").Find(&handovers).Error; err != nil {
+		return nil, err
+	}
+	return handovers, nil
+}
+
+// ListAllByUser 获取用户的全部未删除交接记录，用于账户备份
+func (h *HandoverModel) ListAllByUser(userUid string) ([]HandoverModel, error) {
+	var handovers []HandoverModel
+	if err := database.DB.Where("user_uid = ? AND is_deleted = 0", userUid).Order("id asc").Find(&handovers).Error; err != nil {
+		return nil, err
+	}
+	return handovers, nil
+}
@@ -0,0 +1,116 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"movingManager/database"
+)
+
+// 问题类型
+const (
+	IssueTypeMissing = 1 // 丢失
+	IssueTypeDamaged = 2 // 损坏
+)
+
+// 问题处理状态
+const (
+	IssueStatusOpen       = 0 // 待处理
+	IssueStatusFoundLater = 1 // 已找回
+	IssueStatusResolved   = 2 // 已处理(如已理赔、已修复)
+)
+
+// IssueModel 丢失/损坏问题表模型
+// 记录标签或标签内单个物品的丢失、损坏情况及处理进度
+type IssueModel struct {
+	ID            uint     `gorm:"primarykey;autoIncrement" json:"id"`                            // 主键ID
+	IssueUid      string   `gorm:"column:issue_uid;uniqueIndex;size:36" json:"issue_uid"`         // 问题唯一标识
+	UserUid       string   `gorm:"column:user_uid;index;size:36" json:"user_uid"`                 // 所属用户UID
+	MoveUid       string   `gorm:"column:move_uid;index;size:36" json:"move_uid"`                 // 所属搬运UID
+	TagUid        string   `gorm:"column:tag_uid;index;size:36" json:"tag_uid"`                   // 所属标签UID
+	ItemUid       string   `gorm:"column:item_uid;size:36" json:"item_uid"`                       // 所属物品UID(为空表示整个标签)
+	IssueType     int      `gorm:"column:issue_type" json:"issue_type"`                           // 问题类型(1-丢失,2-损坏)
+	Status        int      `gorm:"column:status;default:0" json:"status"`                         // 处理状态(0-待处理,1-已找回,2-已处理)
+	DeclaredValue int64    `gorm:"column:declared_value;default:0" json:"declared_value"`         // 申报损失金额(分)
+	Remark        string   `gorm:"column:remark;size:500" json:"remark"`                          // 问题说明
+	PhotoRefs     []string `gorm:"column:photo_refs;type:text;serializer:json" json:"photo_refs"` // 照片引用(URL或附件UID)
+	ResolvedAt    int64    `gorm:"column:resolved_at;default:0" json:"resolved_at"`               // 找回/处理时间戳
+	BaseModel              // 嵌入基础模型
+}
+
+// TableName 设置表名
+func (i *IssueModel) TableName() string {
+	return "issues"
+}
+
+// BeforeCreate 创建前钩子：生成UUID作为问题唯一标识
+func (i *IssueModel) BeforeCreate(tx *gorm.DB) error {
+	if i.IssueUid == "" {
+		i.IssueUid = uuid.New().String()
+	}
+	return nil
+}
+
+// Create 插入问题记录到数据库
+func (i *IssueModel) Create() error {
+	return database.DB.Create(i).Error
+}
+
+// GetByUID 根据用户UID和问题UID查询记录
+// onlyUndeleted 控制是否只查询未删除记录
+func (i *IssueModel) GetByUID(userUid, issueUid string, onlyUndeleted bool) error {
+	where := "user_uid = ? AND issue_uid = ?"
+	if onlyUndeleted {
+		where += " AND is_deleted = 0"
+	}
+	return database.DB.Where(where, userUid, issueUid).First(i).Error
+}
+
+// Update 更新问题记录
+func (i *IssueModel) Update() error {
+	return database.DB.Save(i).Error
+}
+
+// UpdateDeleteStatus 更新删除状态
+func (i *IssueModel) UpdateDeleteStatus(isDeleted int) error {
+	i.IsDeleted = isDeleted
+	if isDeleted == 1 {
+		i.DeletedAt = time.Now().Unix()
+	}
+
+	return i.Update()
+}
+
+// ListByTag 获取标签下的未删除问题
+func (i *IssueModel) ListByTag(userUid, tagUid string) ([]IssueModel, error) {
+	var issues []IssueModel
+	if err := database.DB.Where("user_uid = ? AND tag_uid = ? AND is_deleted = 0", userUid, tagUid).Order("created_at asc").Find(&issues).Error; err != nil {
+		return nil, err
+	}
+	return issues, nil
+}
+
+// ListByMove 获取搬运下的未删除问题
+// onlyOpen 控制是否只查询待处理的问题
+func (i *IssueModel) ListByMove(userUid, moveUid string, onlyOpen bool) ([]IssueModel, error) {
+	var issues []IssueModel
+	db := database.DB.Where("user_uid = ? AND move_uid = ? AND is_deleted = 0", userUid, moveUid)
+	if onlyOpen {
+		db = db.Where("status = ?", IssueStatusOpen)
+	}
+	if err := db.Order("created_at asc").Find(&issues).Error; err != nil {
+		return nil, err
+	}
+	return issues, nil
+}
+
+// ListAllByUser 获取用户的全部问题记录(含已删除)，用于账户备份
+func (i *IssueModel) ListAllByUser(userUid string) ([]IssueModel, error) {
+	var records []IssueModel
+	if err := database.DB.Where("user_uid = ?", userUid).Order("id asc").Find(&records).Error; err != nil {
+		return nil, err
+	}
+	return records, nil
+}
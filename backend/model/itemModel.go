@@ -0,0 +1,121 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"movingManager/database"
+)
+
+// ItemModel 物品表模型
+// 存储标签（箱子/包裹）内装的物品清单
+type ItemModel struct {
+	ID            uint   `gorm:"primarykey;autoIncrement" json:"id"`                    // 主键ID
+	ItemUid       string `gorm:"column:item_uid;uniqueIndex;size:36" json:"item_uid"`   // 物品唯一标识
+	UserUid       string `gorm:"column:user_uid;index;size:36" json:"user_uid"`         // 所属用户UID
+	MoveUid       string `gorm:"column:move_uid;index;size:36" json:"move_uid"`         // 所属搬运UID
+	TagUid        string `gorm:"column:tag_uid;index;size:36" json:"tag_uid"`           // 所属标签UID
+	ItemName      string `gorm:"column:item_name;size:100" json:"item_name"`            // 物品名称
+	Quantity      int    `gorm:"column:quantity;default:1" json:"quantity"`             // 数量
+	Remark        string `gorm:"column:remark;size:500" json:"remark"`                  // 物品备注
+	DeclaredValue int64  `gorm:"column:declared_value;default:0" json:"declared_value"` // 申报价值(分，整行合计)
+	Currency      string `gorm:"column:currency;size:3;default:CNY" json:"currency"`    // 币种(ISO 4217)
+	BaseModel            // 嵌入基础模型
+}
+
+// TableName 设置表名
+func (i *ItemModel) TableName() string {
+	return "items"
+}
+
+// BeforeCreate 创建前钩子：生成UUID作为物品唯一标识
+func (i *ItemModel) BeforeCreate(tx *gorm.DB) error {
+	if i.ItemUid == "" {
+		i.ItemUid = uuid.New().String()
+	}
+	return nil
+}
+
+// Create 插入物品记录到数据库
+func (i *ItemModel) Create() error {
+	return database.DB.Create(i).Error
+}
+
+// GetByUID 根据用户UID和物品UID查询记录
+// onlyUndeleted 控制是否只查询未删除记录
+func (i *ItemModel) GetByUID(userUid, itemUid string, onlyUndeleted bool) error {
+	where := "user_uid = ? AND item_uid = ?"
+	if onlyUndeleted {
+		where += " AND is_deleted = 0"
+	}
+	return database.DB.Where(where, userUid, itemUid).First(i).Error
+}
+
+// Update 更新物品记录
+func (i *ItemModel) Update() error {
+	return database.DB.Save(i).Error
+}
+
+// UpdateDeleteStatus 更新删除状态
+func (i *ItemModel) UpdateDeleteStatus(isDeleted int) error {
+	i.IsDeleted = isDeleted
+	if isDeleted == 1 {
+		i.DeletedAt = time.Now().Unix()
+	}
+
+	return i.Update()
+}
+
+// ListByTag 获取标签下的未删除物品
+func (i *ItemModel) ListByTag(userUid, tagUid string) ([]ItemModel, error) {
+	var items []ItemModel
+	if err := database.DB.Where("user_uid = ? AND tag_uid = ? AND is_deleted = 0", userUid, tagUid).Order("created_at asc").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// ListByTagUids 批量获取多个标签下的未删除物品
+func (i *ItemModel) ListByTagUids(userUid string, tagUids []string) ([]ItemModel, error) {
+	var items []ItemModel
+	if len(tagUids) == 0 {
+		return items, nil
+	}
+	if err := database.DB.Where("user_uid = ? AND tag_uid IN ? AND is_deleted = 0", userUid, tagUids).Order("created_at asc, id asc").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// ListByMove 获取搬运下的全部未删除物品
+func (i *ItemModel) ListByMove(userUid, moveUid string) ([]ItemModel, error) {
+	var items []ItemModel
+	if err := database.DB.Where("user_uid = ? AND move_uid = ? AND is_deleted = 0", userUid, moveUid).Order("created_at asc").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// SumDeclaredValueByMove 按币种汇总搬运下未删除物品的申报价值
+// 只统计所属标签未删除的物品
+func (i *ItemModel) SumDeclaredValueByMove(userUid, moveUid string) ([]CurrencyTotal, error) {
+	var totals []CurrencyTotal
+	err := database.DB.Model(&ItemModel{}).
+		Select("items.currency, COALESCE(SUM(items.declared_value), 0) AS amount").
+		Joins("JOIN tags ON tags.tag_uid = items.tag_uid AND tags.is_deleted = 0").
+		Where("items.user_uid = ? AND items.move_uid = ? AND items.is_deleted = 0 AND items.declared_value > 0", userUid, moveUid).
+		Group("items.currency").
+		Scan(&totals).Error
+	return totals, err
+}
+
+// ListAllByUser 获取用户的全部物品记录(含已删除)，用于账户备份
+func (i *ItemModel) ListAllByUser(userUid string) ([]ItemModel, error) {
+	var records []ItemModel
+	if err := database.DB.Where("user_uid = ?", userUid).Order("id asc").Find(&records).Error; err != nil {
+		return nil, err
+	}
+	return records, nil
+}
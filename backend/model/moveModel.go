@@ -25,6 +25,7 @@ type MoveModel struct {
 	UnverifiedTagCount int    `gorm:"column:unverified_tag_count;default:0" json:"unverified_tag_count"` // 未核销标签数
 	IsCompleted        int    `gorm:"column:is_completed;default:0" json:"is_completed"`                 // 是否完成(0-未完成,1-已完成)
 	Remark             string `gorm:"column:remark;size:500" json:"remark"`                              // 备注信息
+	AllowAnonymousVerify int  `gorm:"column:allow_anonymous_verify;default:0" json:"allow_anonymous_verify"` // 是否允许免登录扫码核销(0-不允许,1-允许)
 	BaseModel                 // 嵌入基础模型
 }
 
@@ -98,3 +99,12 @@ func (m *MoveModel) ListByUser(userUid string, page, pageSize int, onlyUndeleted
 
 	return moves, total, nil
 }
+
+// ListAllByUser 获取用户的全部搬运记录(含已删除)，用于账户备份
+func (m *MoveModel) ListAllByUser(userUid string) ([]MoveModel, error) {
+	var records []MoveModel
+	if err := database.DB.Where("user_uid = ?", userUid).Order("id asc").Find(&records).Error; err != nil {
+		return nil, err
+	}
+	return records, nil
+}
@@ -0,0 +1,114 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"movingManager/database"
+)
+
+// 房间所在位置
+const (
+	RoomSideOrigin      = 1 // 出发地房间
+	RoomSideDestination = 2 // 目的地房间
+)
+
+// RoomModel 房间表模型
+// 存储搬运任务在出发地和目的地的房间定义，供标签标注来源和去向
+type RoomModel struct {
+	ID        uint   `gorm:"primarykey;autoIncrement" json:"id"`                  // 主键ID
+	RoomUid   string `gorm:"column:room_uid;uniqueIndex;size:36" json:"room_uid"` // 房间唯一标识
+	UserUid   string `gorm:"column:user_uid;index;size:36" json:"user_uid"`       // 所属用户UID
+	MoveUid   string `gorm:"column:move_uid;index;size:36" json:"move_uid"`       // 所属搬运UID
+	Side      int    `gorm:"column:side;default:2" json:"side"`                   // 所在位置(1-出发地,2-目的地)
+	RoomName  string `gorm:"column:room_name;size:50" json:"room_name"`           // 房间名称
+	Color     string `gorm:"column:color;size:7" json:"color"`                    // 标识颜色(#RRGGBB，可为空)
+	Sort      int    `gorm:"column:sort;default:0" json:"sort"`                   // 排序值(越小越靠前)
+	BaseModel        // 嵌入基础模型
+}
+
+// TableName 设置表名
+func (r *RoomModel) TableName() string {
+	return "rooms"
+}
+
+// BeforeCreate 创建前钩子：生成UUID作为房间唯一标识
+func (r *RoomModel) BeforeCreate(tx *gorm.DB) error {
+	if r.RoomUid == "" {
+		r.RoomUid = uuid.New().String()
+	}
+	return nil
+}
+
+// Create 插入房间记录到数据库
+func (r *RoomModel) Create() error {
+	return database.DB.Create(r).Error
+}
+
+// GetByUID 根据用户UID和房间UID查询记录
+// onlyUndeleted 控制是否只查询未删除记录
+func (r *RoomModel) GetByUID(userUid, roomUid string, onlyUndeleted bool) error {
+	where := "user_uid = ? AND room_uid = ?"
+	if onlyUndeleted {
+		where += " AND is_deleted = 0"
+	}
+	return database.DB.Where(where, userUid, roomUid).First(r).Error
+}
+
+// GetByUIDTx 事务中根据用户UID和房间UID查询未删除记录
+func (r *RoomModel) GetByUIDTx(tx *gorm.DB, userUid, roomUid string) error {
+	return tx.Where("user_uid = ? AND room_uid = ? AND is_deleted = 0", userUid, roomUid).First(r).Error
+}
+
+// Update 更新房间记录
+func (r *RoomModel) Update() error {
+	return database.DB.Save(r).Error
+}
+
+// UpdateDeleteStatus 更新删除状态
+func (r *RoomModel) UpdateDeleteStatus(isDeleted int) error {
+	r.IsDeleted = isDeleted
+	if isDeleted == 1 {
+		r.DeletedAt = time.Now().Unix()
+	}
+
+	return r.Update()
+}
+
+// ListByMove 获取搬运下的房间列表
+// side 为0时返回出发地和目的地全部房间
+func (r *RoomModel) ListByMove(userUid, moveUid string, side int) ([]RoomModel, error) {
+	var rooms []RoomModel
+	db := database.DB.Where("user_uid = ? AND move_uid = ? AND is_deleted = 0", userUid, moveUid)
+	if side != 0 {
+		db = db.Where("side = ?", side)
+	}
+	if err := db.Order("side asc").Order("sort asc").Order("created_at asc").Find(&rooms).Error; err != nil {
+		return nil, err
+	}
+	return rooms, nil
+}
+
+// MapByMove 获取搬运下的房间，按房间UID索引
+func (r *RoomModel) MapByMove(userUid, moveUid string) (map[string]RoomModel, error) {
+	rooms, err := r.ListByMove(userUid, moveUid, 0)
+	if err != nil {
+		return nil, err
+	}
+	roomMap := make(map[string]RoomModel, len(rooms))
+	for _, room := range rooms {
+		roomMap[room.RoomUid] = room
+	}
+	return roomMap, nil
+}
+
+// ListAllByUser 获取用户的全部房间记录(含已删除)，用于账户备份
+func (r *RoomModel) ListAllByUser(userUid string) ([]RoomModel, error) {
+	var records []RoomModel
+	if err := database.DB.Where("user_uid = ?", userUid).Order("id asc").Find(&records).Error; err != nil {
+		return nil, err
+	}
+	return records, nil
+}
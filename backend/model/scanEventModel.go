@@ -0,0 +1,81 @@
+package model
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"movingManager/database"
+)
+
+// 扫码事件处理结果
+const (
+	ScanEventApplied  = 1 // 已应用
+	ScanEventStale    = 2 // 标签状态已被其他操作修改，未应用
+	ScanEventRejected = 3 // 已拒绝(标签不存在或已删除)
+)
+
+// ScanEventModel 扫码事件表模型
+// 记录离线扫码同步上传的事件及处理结果，用于保证重复上传时的幂等性
+type ScanEventModel struct {
+	ID         uint   `gorm:"primarykey;autoIncrement" json:"id"`                                               // 主键ID
+	EventUid   string `gorm:"column:event_uid;uniqueIndex:idx_scan_events_user_event;size:64" json:"event_uid"` // 客户端生成的事件唯一标识
+	UserUid    string `gorm:"column:user_uid;uniqueIndex:idx_scan_events_user_event;size:36" json:"user_uid"`   // 所属用户UID
+	TagUid     string `gorm:"column:tag_uid;index;size:36" json:"tag_uid"`                                      // 标签UID
+	MoveUid    string `gorm:"column:move_uid;size:36" json:"move_uid"`                                          // 所属搬运UID
+	IsVerified int    `gorm:"column:is_verified;default:0" json:"is_verified"`                                  // 扫码设置的核销状态
+	ClientAt   int64  `gorm:"column:client_at;default:0" json:"client_at"`                                      // 客户端扫码时间戳(毫秒)
+	Result     int    `gorm:"column:result;default:0" json:"result"`                                            // 处理结果(1-已应用,2-已覆盖,3-已拒绝)
+	Message    string `gorm:"column:message;size:200" json:"message"`                                           // 处理说明
+	BaseModel         // 嵌入基础模型
+}
+
+// TableName 设置表名
+func (e *ScanEventModel) TableName() string {
+	return "scan_events"
+}
+
+// ClaimTx 事务中插入扫码事件记录以占用事件UID，事件UID已存在时不插入并返回false
+// 并发请求插入同一事件时，唯一索引冲突只影响该事件，不会使整个事务失败
+func (e *ScanEventModel) ClaimTx(tx *gorm.DB) (bool, error) {
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(e)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// UpdateResultTx 事务中更新扫码事件的处理结果
+func (e *ScanEventModel) UpdateResultTx(tx *gorm.DB) error {
+	return tx.Model(e).Updates(map[string]interface{}{"move_uid": e.MoveUid, "result": e.Result, "message": e.Message}).Error
+}
+
+// GetByEventUidTx 事务中根据事件UID查询扫码事件
+func (e *ScanEventModel) GetByEventUidTx(tx *gorm.DB, userUid, eventUid string) error {
+	return tx.Where("user_uid = ? AND event_uid = ?", userUid, eventUid).First(e).Error
+}
+
+// MapByEventUidsTx 事务中按事件UID批量查询已处理的扫码事件
+func (e *ScanEventModel) MapByEventUidsTx(tx *gorm.DB, userUid string, eventUids []string) (map[string]ScanEventModel, error) {
+	result := make(map[string]ScanEventModel)
+	if len(eventUids) == 0 {
+		return result, nil
+	}
+
+	var events []ScanEventModel
+	if err := tx.Where("user_uid = ? AND event_uid IN ?", userUid, eventUids).Find(&events).Error; err != nil {
+		return nil, err
+	}
+	for _, event := range events {
+		result[event.EventUid] = event
+	}
+	return result, nil
+}
+
+// ListAllByUser 获取用户的全部扫码事件，用于账户备份
+func (e *ScanEventModel) ListAllByUser(userUid string) ([]ScanEventModel, error) {
+	var events []ScanEventModel
+	if err := database.DB.Where("user_uid = ?", userUid).Order("id asc").Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
@@ -12,14 +12,23 @@ import (
 // TagModel 标签表模型
 // 存储搬运任务下的标签信息，包含标签状态和关联关系
 type TagModel struct {
-	ID         uint   `gorm:"primarykey;autoIncrement" json:"id"`                              // 主键ID
-	TagUid     string `gorm:"column:tag_uid;uniqueIndex;size:36" json:"tag_uid"` // 标签唯一标识
-	UserUid    string `gorm:"column:user_uid;index;size:36" json:"user_uid"`     // 所属用户UID
-	MoveUid    string `gorm:"column:move_uid;index;size:36" json:"move_uid"`     // 所属搬运UID
-	TagName    string `gorm:"column:tag_name;size:100" json:"tag_name"`          // 标签名称
-	Remark     string `gorm:"column:remark;size:500" json:"remark"`              // 标签备注
-	IsVerified int    `gorm:"column:is_verified;default:0" json:"is_verified"`   // 是否核销(0-未核销,1-已核销)
-	BaseModel         // 嵌入基础模型
+	ID             uint   `gorm:"primarykey;autoIncrement" json:"id"`                        // 主键ID
+	TagUid         string `gorm:"column:tag_uid;uniqueIndex;size:36" json:"tag_uid"`         // 标签唯一标识
+	UserUid        string `gorm:"column:user_uid;index;size:36" json:"user_uid"`             // 所属用户UID
+	MoveUid        string `gorm:"column:move_uid;index;size:36" json:"move_uid"`             // 所属搬运UID
+	TagName        string `gorm:"column:tag_name;size:100" json:"tag_name"`                  // 标签名称
+	Remark         string `gorm:"column:remark;size:500" json:"remark"`                      // 标签备注
+	IsVerified     int    `gorm:"column:is_verified;default:0" json:"is_verified"`           // 是否核销(0-未核销,1-已核销)
+	OriginRoomUid  string `gorm:"column:origin_room_uid;size:36" json:"origin_room_uid"`     // 出发地房间UID
+	DestRoomUid    string `gorm:"column:dest_room_uid;index;size:36" json:"dest_room_uid"`   // 目的地房间UID
+	ParentTagUid   string `gorm:"column:parent_tag_uid;index;size:36" json:"parent_tag_uid"` // 外层容器标签UID(为空表示顶层)
+	DeclaredValue  int64  `gorm:"column:declared_value;default:0" json:"declared_value"`     // 申报价值(分，不含物品清单中单独申报的价值)
+	Currency       string `gorm:"column:currency;size:3;default:CNY" json:"currency"`        // 币种(ISO 4217)
+	VerifiedBy     string `gorm:"column:verified_by;size:50" json:"verified_by"`             // 免登录扫码核销人姓名
+	VerifiedAt     int64  `gorm:"column:verified_at;default:0" json:"verified_at"`           // 核销时间戳
+	StateChangedAt int64  `gorm:"column:state_changed_at;default:0" json:"state_changed_at"` // 核销状态最后变更时间戳(毫秒，服务器时间)
+	StateVersion   int64  `gorm:"column:state_version;default:0" json:"state_version"`       // 核销状态版本号(每次变更加1，离线同步按此检测冲突)
+	BaseModel             // 嵌入基础模型
 }
 
 // TableName 设置表名
@@ -55,6 +64,12 @@ func (t *TagModel) GetByUID(userUid, tagUid string, onlyUndeleted bool) error {
 	return database.DB.Where(where, userUid, tagUid).First(t).Error
 }
 
+// GetUndeletedByTagUidTx 事务中仅根据标签UID查询未删除记录
+// 仅用于已校验签名的免登录扫码场景
+func (t *TagModel) GetUndeletedByTagUidTx(tx *gorm.DB, tagUid string) error {
+	return tx.Where("tag_uid = ? AND is_deleted = 0", tagUid).First(t).Error
+}
+
 // GetByUserAndTagUidTx 事务中根据用户UID和标签UID查询记录
 // onlyUndeleted 控制是否只查询未删除记录
 func (t *TagModel) GetByUserAndTagUidTx(tx *gorm.DB, userUid, tagUid string, onlyUndeleted bool) error {
@@ -65,6 +80,12 @@ func (t *TagModel) GetByUserAndTagUidTx(tx *gorm.DB, userUid, tagUid string, onl
 	return tx.Where(where, userUid, tagUid).First(t).Error
 }
 
+// LockTx 在事务中锁定标签行，直到事务结束
+// 与 UserModel.LockTx 相同使用不改变数据的更新加锁，用于串行化同一标签的离线同步
+func (t *TagModel) LockTx(tx *gorm.DB, userUid, tagUid string) error {
+	return tx.Model(&TagModel{}).Where("user_uid = ? AND tag_uid = ?", userUid, tagUid).UpdateColumn("state_version", gorm.Expr("state_version")).Error
+}
+
 // Update 更新标签记录
 func (t *TagModel) Update() error {
 	return database.DB.Save(t).Error
@@ -109,20 +130,6 @@ func (t *TagModel) GetMoveByUserAndMoveUidTx(tx *gorm.DB, userUid, moveUid strin
 	return &move, nil
 }
 
-// GetMoveByMoveUidTx 事务中根据搬运UID查询搬运记录
-// onlyUndeleted 控制是否只查询未删除记录
-func (t *TagModel) GetMoveByMoveUidTx(tx *gorm.DB, moveUid string, onlyUndeleted bool) (*MoveModel, error) {
-	var move MoveModel
-	where := "move_uid = ?"
-	if onlyUndeleted {
-		where += " AND is_deleted = 0"
-	}
-	if err := tx.Where(where, moveUid).First(&move).Error; err != nil {
-		return nil, err
-	}
-	return &move, nil
-}
-
 // UpdateMoveTagCountTx 事务中更新搬运记录的标签统计
 func (t *TagModel) UpdateMoveTagCountTx(tx *gorm.DB, move *MoveModel, tagCount, verifiedTagCount, unverifiedTagCount int) error {
 	updates := make(map[string]interface{})
@@ -201,12 +208,37 @@ func (t *TagModel) GetTagsByMove(userUid, moveUid string, onlyUndeleted bool) ([
 	if onlyUndeleted {
 		where += " AND is_deleted = 0"
 	}
-	if err := database.DB.Where(where, userUid, moveUid).Order("created_at asc").Find(&tags).Error; err != nil {
+	if err := database.DB.Where(where, userUid, moveUid).Order("created_at asc, id asc").Find(&tags).Error; err != nil {
 		return nil, err
 	}
 	return tags, nil
 }
 
+// EachByMove 按创建顺序分批遍历搬运下的未删除标签（与 GetTagsByMove 顺序一致）
+// 使用(created_at, id)游标分页，避免一次加载全部标签
+func (t *TagModel) EachByMove(userUid, moveUid string, batchSize int, fn func(tags []TagModel) error) error {
+	lastCreatedAt, lastID := int64(-1), uint(0)
+	for {
+		var tags []TagModel
+		if err := database.DB.Where("user_uid = ? AND move_uid = ? AND is_deleted = 0", userUid, moveUid).
+			Where("created_at > ? OR (created_at = ? AND id > ?)", lastCreatedAt, lastCreatedAt, lastID).
+			Order("created_at asc, id asc").Limit(batchSize).Find(&tags).Error; err != nil {
+			return err
+		}
+		if len(tags) == 0 {
+			return nil
+		}
+		if err := fn(tags); err != nil {
+			return err
+		}
+		if len(tags) < batchSize {
+			return nil
+		}
+		last := tags[len(tags)-1]
+		lastCreatedAt, lastID = last.CreatedAt, last.ID
+	}
+}
+
 // ListByMove 获取搬运下的标签列表
 // onlyUndeleted 控制是否只查询未删除记录
 func (t *TagModel) ListByMove(userUid, moveUid string, page, pageSize int, onlyUndeleted bool) ([]TagModel, int64, error) {
@@ -232,3 +264,84 @@ func (t *TagModel) ListByMove(userUid, moveUid string, page, pageSize int, onlyU
 
 	return tags, total, nil
 }
+
+// GetChildrenTx 事务中查询直接放在该标签内的未删除子标签
+func (t *TagModel) GetChildrenTx(tx *gorm.DB, userUid, parentTagUid string) ([]TagModel, error) {
+	var tags []TagModel
+	if err := tx.Where("user_uid = ? AND parent_tag_uid = ? AND is_deleted = 0", userUid, parentTagUid).Order("created_at asc").Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// GetChildren 查询直接放在该标签内的未删除子标签
+func (t *TagModel) GetChildren(userUid, parentTagUid string) ([]TagModel, error) {
+	return t.GetChildrenTx(database.DB, userUid, parentTagUid)
+}
+
+// CountChildren 统计多个标签各自的未删除子标签数量
+func (t *TagModel) CountChildren(userUid string, parentTagUids []string) (map[string]int, error) {
+	counts := make(map[string]int)
+	if len(parentTagUids) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		ParentTagUid string
+		Total        int
+	}
+	if err := database.DB.Model(&TagModel{}).
+		Select("parent_tag_uid, COUNT(*) AS total").
+		Where("user_uid = ? AND parent_tag_uid IN ? AND is_deleted = 0", userUid, parentTagUids).
+		Group("parent_tag_uid").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		counts[row.ParentTagUid] = row.Total
+	}
+	return counts, nil
+}
+
+// MapByUIDs 根据标签UID批量查询未删除标签，按标签UID索引
+func (t *TagModel) MapByUIDs(userUid string, tagUids []string) (map[string]TagModel, error) {
+	tagMap := make(map[string]TagModel)
+	if len(tagUids) == 0 {
+		return tagMap, nil
+	}
+
+	var tags []TagModel
+	if err := database.DB.Where("user_uid = ? AND tag_uid IN ? AND is_deleted = 0", userUid, tagUids).Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	for _, tag := range tags {
+		tagMap[tag.TagUid] = tag
+	}
+	return tagMap, nil
+}
+
+// CurrencyTotal 按币种汇总的金额
+type CurrencyTotal struct {
+	Currency string `json:"currency"`
+	Amount   int64  `json:"amount"` // 金额(分)
+}
+
+// SumDeclaredValueByMove 按币种汇总搬运下未删除标签的申报价值
+func (t *TagModel) SumDeclaredValueByMove(userUid, moveUid string) ([]CurrencyTotal, error) {
+	var totals []CurrencyTotal
+	err := database.DB.Model(&TagModel{}).
+		Select("currency, COALESCE(SUM(declared_value), 0) AS amount").
+		Where("user_uid = ? AND move_uid = ? AND is_deleted = 0 AND declared_value > 0", userUid, moveUid).
+		Group("currency").
+		Scan(&totals).Error
+	return totals, err
+}
+
+// ListAllByUser 获取用户的全部标签记录(含已删除)，用于账户备份
+func (t *TagModel) ListAllByUser(userUid string) ([]TagModel, error) {
+	var records []TagModel
+	if err := database.DB.Where("user_uid = ?", userUid).Order("id asc").Find(&records).Error; err != nil {
+		return nil, err
+	}
+	return records, nil
+}
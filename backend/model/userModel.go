@@ -50,3 +50,16 @@ func (u *UserModel) UpdateAuthCode(newCode string) error {
 	db := database.DB
 	return db.Model(u).Update("authorization_code", newCode).Error
 }
+
+// LockTx 在事务中锁定用户行，直到事务结束
+// 使用不改变数据的更新加锁，PostgreSQL锁定该行，SQLite获取写锁，用于串行化同一用户的配额校验
+func (u *UserModel) LockTx(tx *gorm.DB, userUid string) error {
+	result := tx.Model(&UserModel{}).Where("user_uid = ?", userUid).UpdateColumn("updated_at", gorm.Expr("updated_at"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
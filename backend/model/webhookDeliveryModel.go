@@ -0,0 +1,98 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"movingManager/database"
+)
+
+// Webhook投递状态
+const (
+	DeliveryPending = 0 // 等待投递(含等待重试)
+	DeliverySuccess = 1 // 投递成功
+	DeliveryFailed  = 2 // 超过重试次数，投递失败
+)
+
+// WebhookDeliveryModel Webhook投递记录表模型
+// 作为持久化投递队列，同时保存每次投递的结果供用户查询
+type WebhookDeliveryModel struct {
+	ID             uint   `gorm:"primarykey;autoIncrement" json:"id"`                                               // 主键ID
+	DeliveryUid    string `gorm:"column:delivery_uid;uniqueIndex;size:36" json:"delivery_uid"`                      // 投递唯一标识
+	WebhookUid     string `gorm:"column:webhook_uid;index;size:36" json:"webhook_uid"`                              // 所属WebhookUID
+	UserUid        string `gorm:"column:user_uid;index;size:36" json:"user_uid"`                                    // 所属用户UID
+	EventType      string `gorm:"column:event_type;size:50" json:"event_type"`                                      // 事件类型
+	Payload        string `gorm:"column:payload;type:text" json:"payload"`                                          // 推送内容(JSON)
+	Status         int    `gorm:"column:status;default:0;index:idx_deliveries_due" json:"status"`                   // 投递状态(0-等待,1-成功,2-失败)
+	Attempts       int    `gorm:"column:attempts;default:0" json:"attempts"`                                        // 已尝试次数
+	NextAttemptAt  int64  `gorm:"column:next_attempt_at;default:0;index:idx_deliveries_due" json:"next_attempt_at"` // 下次尝试时间戳
+	LastStatusCode int    `gorm:"column:last_status_code;default:0" json:"last_status_code"`                        // 最后一次响应状态码
+	LastError      string `gorm:"column:last_error;size:500" json:"last_error"`                                     // 最后一次错误信息
+	DeliveredAt    int64  `gorm:"column:delivered_at;default:0" json:"delivered_at"`                                // 投递成功时间戳
+	BaseModel             // 嵌入基础模型
+}
+
+// TableName 设置表名
+func (d *WebhookDeliveryModel) TableName() string {
+	return "webhook_deliveries"
+}
+
+// BeforeCreate 创建前钩子：生成UUID作为投递唯一标识
+func (d *WebhookDeliveryModel) BeforeCreate(tx *gorm.DB) error {
+	if d.DeliveryUid == "" {
+		d.DeliveryUid = uuid.New().String()
+	}
+	return nil
+}
+
+// Create 插入投递记录到数据库
+func (d *WebhookDeliveryModel) Create() error {
+	return database.DB.Create(d).Error
+}
+
+// Update 更新投递记录
+func (d *WebhookDeliveryModel) Update() error {
+	return database.DB.Save(d).Error
+}
+
+// ClaimDue 领取到期待投递的记录，按计划时间先后排序
+// 通过条件更新将下次尝试时间推迟到 leaseUntil，更新成功的记录才归当前任务处理，
+// 多个实例同时轮询时同一记录只会被领取一次；处理中途退出的记录在租约到期后重新投递
+func (d *WebhookDeliveryModel) ClaimDue(now, leaseUntil int64, limit int) ([]WebhookDeliveryModel, error) {
+	var candidates []WebhookDeliveryModel
+	if err := database.DB.Where("status = ? AND next_attempt_at <= ?", DeliveryPending, now).
+		Order("next_attempt_at asc, id asc").Limit(limit).Find(&candidates).Error; err != nil {
+		return nil, err
+	}
+
+	claimed := make([]WebhookDeliveryModel, 0, len(candidates))
+	for _, delivery := range candidates {
+		result := database.DB.Model(&WebhookDeliveryModel{}).
+			Where("id = ? AND status = ? AND next_attempt_at = ?", delivery.ID, DeliveryPending, delivery.NextAttemptAt).
+			Update("next_attempt_at", leaseUntil)
+		if result.Error != nil {
+			return claimed, result.Error
+		}
+		if result.RowsAffected == 1 {
+			delivery.NextAttemptAt = leaseUntil
+			claimed = append(claimed, delivery)
+		}
+	}
+	return claimed, nil
+}
+
+// ListByWebhook 分页获取Webhook的投递记录，最新的在前
+func (d *WebhookDeliveryModel) ListByWebhook(userUid, webhookUid string, page, pageSize int) ([]WebhookDeliveryModel, int64, error) {
+	var deliveries []WebhookDeliveryModel
+	var total int64
+	db := database.DB.Model(&WebhookDeliveryModel{}).Where("user_uid = ? AND webhook_uid = ?", userUid, webhookUid)
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	offset := (page - 1) * pageSize
+	if err := db.Order("id desc").Limit(pageSize).Offset(offset).Find(&deliveries).Error; err != nil {
+		return nil, 0, err
+	}
+	return deliveries, total, nil
+}
@@ -0,0 +1,94 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"movingManager/database"
+)
+
+// WebhookModel Webhook表模型
+// 存储用户配置的事件推送地址，EventTypes 为空表示订阅全部事件
+type WebhookModel struct {
+	ID         uint     `gorm:"primarykey;autoIncrement" json:"id"`                        // 主键ID
+	WebhookUid string   `gorm:"column:webhook_uid;uniqueIndex;size:36" json:"webhook_uid"` // Webhook唯一标识
+	UserUid    string   `gorm:"column:user_uid;index;size:36" json:"user_uid"`             // 所属用户UID
+	Url        string   `gorm:"column:url;size:500" json:"url"`                            // 推送地址
+	Secret     string   `gorm:"column:secret;size:64" json:"-"`                            // 签名密钥
+	EventTypes []string `gorm:"column:event_types;serializer:json" json:"event_types"`     // 订阅的事件类型
+	IsEnabled  int      `gorm:"column:is_enabled;default:1" json:"is_enabled"`             // 是否启用(0-停用,1-启用)
+	Remark     string   `gorm:"column:remark;size:200" json:"remark"`                      // 备注
+	BaseModel           // 嵌入基础模型
+}
+
+// TableName 设置表名
+func (w *WebhookModel) TableName() string {
+	return "webhooks"
+}
+
+// BeforeCreate 创建前钩子：生成UUID作为Webhook唯一标识
+func (w *WebhookModel) BeforeCreate(tx *gorm.DB) error {
+	if w.WebhookUid == "" {
+		w.WebhookUid = uuid.New().String()
+	}
+	return nil
+}
+
+// Create 插入Webhook记录到数据库
+func (w *WebhookModel) Create() error {
+	return database.DB.Create(w).Error
+}
+
+// GetByUID 根据用户UID和WebhookUID查询记录
+// onlyUndeleted 控制是否只查询未删除记录
+func (w *WebhookModel) GetByUID(userUid, webhookUid string, onlyUndeleted bool) error {
+	where := "user_uid = ? AND webhook_uid = ?"
+	if onlyUndeleted {
+		where += " AND is_deleted = 0"
+	}
+	return database.DB.Where(where, userUid, webhookUid).First(w).Error
+}
+
+// GetByWebhookUid 仅根据WebhookUID查询记录（投递任务使用）
+func (w *WebhookModel) GetByWebhookUid(webhookUid string) error {
+	return database.DB.Where("webhook_uid = ?", webhookUid).First(w).Error
+}
+
+// Update 更新Webhook记录
+func (w *WebhookModel) Update() error {
+	return database.DB.Save(w).Error
+}
+
+// UpdateDeleteStatus 更新删除状态
+func (w *WebhookModel) UpdateDeleteStatus(isDeleted int) error {
+	w.IsDeleted = isDeleted
+	if isDeleted == 1 {
+		w.DeletedAt = time.Now().Unix()
+	}
+
+	return w.Update()
+}
+
+// ListByUser 获取用户的未删除Webhook
+func (w *WebhookModel) ListByUser(userUid string) ([]WebhookModel, error) {
+	var webhooks []WebhookModel
+	if err := database.DB.Where("user_uid = ? AND is_deleted = 0", userUid).Order("created_at asc").Find(&webhooks).Error; err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// Subscribes 判断Webhook是否订阅了指定事件类型
+func (w *WebhookModel) Subscribes(eventType string) bool {
+	if len(w.EventTypes) == 0 {
+		return true
+	}
+	for _, t := range w.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
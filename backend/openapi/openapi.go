@@ -0,0 +1,278 @@
+package openapi
+
+import (
+	_ "embed"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"movingManager/dto"
+)
+
+// Version 接口文档版本
+const Version = "2.0.0"
+
+// Document OpenAPI 3 文档
+type Document struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       Info                  `json:"info"`
+	Servers    []Server              `json:"servers"`
+	Tags       []Tag                 `json:"tags,omitempty"`
+	Paths      map[string]PathItem   `json:"paths"`
+	Components Components            `json:"components"`
+	Security   []map[string][]string `json:"security,omitempty"`
+}
+
+// Info 文档基本信息
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// Server 接口服务地址
+type Server struct {
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+// Tag 接口分组
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem 同一路径下各HTTP方法的接口，键为小写方法名
+type PathItem map[string]*Operation
+
+// Operation 单个接口描述
+type Operation struct {
+	Tags        []string              `json:"tags,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	OperationID string                `json:"operationId"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+// Parameter 查询参数
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+// RequestBody 请求体
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response 响应
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType 指定内容类型的数据结构
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components 可复用的数据结构和认证方式
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+// SecurityScheme 认证方式
+type SecurityScheme struct {
+	Type        string `json:"type"`
+	Scheme      string `json:"scheme,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// 请求参数绑定方式
+const (
+	BindJSON  = ""      // JSON请求体(默认)
+	BindForm  = "form"  // multipart/form-data表单
+	BindQuery = "query" // URL查询参数
+)
+
+// Route 接口登记信息，与 router.RegisterRoutes 中的路由一一对应
+type Route struct {
+	Method    string          // HTTP方法
+	Path      string          // 相对于版本前缀的路径
+	Handler   gin.HandlerFunc // 处理函数
+	Tag       string          // 接口分组
+	Summary   string          // 接口说明
+	Public    bool            // 是否免登录
+	Request   interface{}     // 请求参数类型的零值，nil表示无请求参数
+	Binding   string          // 请求参数绑定方式
+	Files     []string        // multipart文件字段
+	Response  interface{}     // 成功响应中data的类型零值，nil表示无数据
+	Paginated bool            // 成功响应是否包含分页信息
+	Produces  []string        // 非JSON成功响应的内容类型(文件下载、事件流等)
+}
+
+const (
+	securitySchemeName = "bearerAuth"
+	responseSchemaName = "dto.Response"
+)
+
+var (
+	specOnce sync.Once
+	specDoc  *Document
+)
+
+// Build 根据接口登记信息生成文档
+func Build() *Document {
+	b := newSchemaBuilder()
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info: Info{
+			Title: "搬家管理接口",
+			Description: "v2接口使用统一响应信封，出错时返回对应的HTTP状态码；" +
+				"v1接口路径和请求参数与v2相同，响应保持原有格式，出错时HTTP状态码固定为200。",
+			Version: Version,
+		},
+		Servers: []Server{
+			{URL: "/api/v2", Description: "v2接口"},
+			{URL: "/api/v1", Description: "v1接口(原有响应格式)"},
+		},
+		Tags:  tags,
+		Paths: map[string]PathItem{},
+		Components: Components{
+			Schemas: b.schemas,
+			SecuritySchemes: map[string]SecurityScheme{
+				securitySchemeName: {Type: "http", Scheme: "bearer", Description: "登录接口返回的token"},
+			},
+		},
+		Security: []map[string][]string{{securitySchemeName: {}}},
+	}
+	b.schemaOf(reflect.TypeOf(dto.Response{}))
+
+	for _, route := range Routes() {
+		item, ok := doc.Paths[route.Path]
+		if !ok {
+			item = PathItem{}
+			doc.Paths[route.Path] = item
+		}
+		item[strings.ToLower(route.Method)] = b.operation(route)
+	}
+	return doc
+}
+
+// operation 生成单个接口描述
+func (b *schemaBuilder) operation(route Route) *Operation {
+	op := &Operation{
+		Tags:        []string{route.Tag},
+		Summary:     route.Summary,
+		OperationID: operationID(route.Path),
+		Responses: map[string]Response{
+			"200": b.successResponse(route),
+			"default": {
+				Description: "错误响应",
+				Content:     map[string]MediaType{"application/json": {Schema: ref(responseSchemaName)}},
+			},
+		},
+	}
+	if route.Public {
+		// 覆盖全局认证要求
+		op.Security = []map[string][]string{}
+	}
+	if route.Request == nil {
+		return op
+	}
+
+	t := reflect.TypeOf(route.Request)
+	switch route.Binding {
+	case BindQuery:
+		obj := b.objectSchema(t)
+		names := make([]string, 0, len(obj.Properties))
+		for name := range obj.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			op.Parameters = append(op.Parameters, Parameter{Name: name, In: "query", Required: contains(obj.Required, name), Schema: obj.Properties[name]})
+		}
+	case BindForm:
+		obj := b.objectSchema(t)
+		for _, file := range route.Files {
+			obj.Properties[file] = &Schema{Type: "string", Format: "binary"}
+			obj.Required = append(obj.Required, file)
+		}
+		op.RequestBody = &RequestBody{Required: true, Content: map[string]MediaType{"multipart/form-data": {Schema: obj}}}
+	default:
+		op.RequestBody = &RequestBody{Required: true, Content: map[string]MediaType{"application/json": {Schema: b.schemaOf(t)}}}
+	}
+	return op
+}
+
+// successResponse 成功响应，JSON响应的数据包装在响应信封的data字段中
+func (b *schemaBuilder) successResponse(route Route) Response {
+	if len(route.Produces) > 0 {
+		content := map[string]MediaType{}
+		for _, contentType := range route.Produces {
+			content[contentType] = MediaType{Schema: &Schema{Type: "string", Format: "binary"}}
+		}
+		return Response{Description: "成功", Content: content}
+	}
+
+	envelope := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"code":       {Type: "integer", Format: "int32"},
+			"message":    {Type: "string"},
+			"request_id": {Type: "string"},
+		},
+		Required: []string{"code", "message"},
+	}
+	if route.Response != nil {
+		envelope.Properties["data"] = b.schemaOf(reflect.TypeOf(route.Response))
+	}
+	if route.Paginated {
+		envelope.Properties["pagination"] = b.schemaOf(reflect.TypeOf(dto.Pagination{}))
+		envelope.Required = append(envelope.Required, "pagination")
+	}
+	return Response{Description: "成功", Content: map[string]MediaType{"application/json": {Schema: envelope}}}
+}
+
+// operationID 由路径生成接口标识，如 /tag/list-by-room 生成 tagListByRoom
+func operationID(path string) string {
+	parts := strings.FieldsFunc(path, func(r rune) bool { return r == '/' || r == '-' })
+	for i := 1; i < len(parts); i++ {
+		parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+	}
+	return strings.Join(parts, "")
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Spec 返回接口文档(JSON)
+func Spec(c *gin.Context) {
+	specOnce.Do(func() { specDoc = Build() })
+	c.JSON(http.StatusOK, specDoc)
+}
+
+//go:embed docs.html
+var docsPage []byte
+
+// Docs 接口文档页面，读取 /api/openapi.json 展示各接口的请求和响应结构
+func Docs(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", docsPage)
+}
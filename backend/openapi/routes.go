@@ -0,0 +1,161 @@
+package openapi
+
+import (
+	"net/http"
+
+	"movingManager/controller"
+	"movingManager/dto"
+	"movingManager/service"
+)
+
+// tags 接口分组
+var tags = []Tag{
+	{Name: "user", Description: "用户"},
+	{Name: "scan", Description: "免登录扫码"},
+	{Name: "move", Description: "搬运"},
+	{Name: "tag", Description: "标签"},
+	{Name: "room", Description: "房间"},
+	{Name: "item", Description: "物品"},
+	{Name: "issue", Description: "丢失/损坏问题"},
+	{Name: "webhook", Description: "Webhook"},
+	{Name: "account", Description: "账户备份"},
+	{Name: "attachment", Description: "附件"},
+}
+
+// 文件响应的内容类型
+const (
+	contentTypePDF = "application/pdf"
+	contentTypeCSV = "text/csv"
+)
+
+// Routes 全部接口登记信息
+// 新增或修改 router.RegisterRoutes 中的路由时需同步修改此处，路由测试会校验两者一致
+func Routes() []Route {
+	return []Route{
+		// 公开接口
+		{Method: http.MethodPost, Path: "/user/auth", Handler: controller.UserAuth, Tag: "user", Summary: "用户注册/登录", Public: true,
+			Request: controller.UserAuthRequest{}, Response: dto.Auth{}},
+		{Method: http.MethodPost, Path: "/scan/detail", Handler: controller.GetScanDetail, Tag: "scan", Summary: "扫码查看标签", Public: true,
+			Request: controller.ScanDetailRequest{}, Response: service.PublicTagResponse{}},
+		{Method: http.MethodPost, Path: "/scan/verify", Handler: controller.ScanVerifyTag, Tag: "scan", Summary: "扫码核销标签", Public: true,
+			Request: controller.ScanVerifyRequest{}, Response: service.PublicTagResponse{}},
+
+		// 搬运模块
+		{Method: http.MethodPost, Path: "/move/create", Handler: controller.CreateMove, Tag: "move", Summary: "创建搬运",
+			Request: controller.CreateMoveRequest{}, Response: dto.Move{}},
+		{Method: http.MethodPost, Path: "/move/detail", Handler: controller.GetMoveDetail, Tag: "move", Summary: "搬运详情",
+			Request: controller.GetMoveDetailRequest{}, Response: dto.Move{}},
+		{Method: http.MethodPost, Path: "/move/update", Handler: controller.UpdateMove, Tag: "move", Summary: "编辑搬运",
+			Request: controller.UpdateMoveRequest{}, Response: dto.Move{}},
+		{Method: http.MethodPost, Path: "/move/delete", Handler: controller.DeleteMove, Tag: "move", Summary: "删除搬运",
+			Request: controller.DeleteMoveRequest{}},
+		{Method: http.MethodPost, Path: "/move/list", Handler: controller.GetMoveList, Tag: "move", Summary: "搬运列表",
+			Request: controller.GetMoveListRequest{}, Response: []dto.Move{}, Paginated: true},
+		{Method: http.MethodPost, Path: "/move/loss-report", Handler: controller.GetLossReport, Tag: "move", Summary: "丢失损坏报告",
+			Request: controller.GetLossReportRequest{}, Response: service.LossReport{}},
+		{Method: http.MethodGet, Path: "/move/events", Handler: controller.GetMoveEvents, Tag: "move", Summary: "实时事件推送(SSE)",
+			Request: controller.MoveEventsRequest{}, Binding: BindQuery, Produces: []string{"text/event-stream"}},
+		{Method: http.MethodPost, Path: "/move/export", Handler: controller.ExportMove, Tag: "move", Summary: "导出搬运清单(CSV/XLSX/JSON)",
+			Request: controller.ExportMoveRequest{}, Produces: []string{contentTypeCSV, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", "application/json"}},
+		{Method: http.MethodPost, Path: "/move/attachment/upload", Handler: controller.UploadMoveAttachment, Tag: "move", Summary: "上传搬运照片",
+			Request: controller.UploadMoveAttachmentRequest{}, Binding: BindForm, Files: []string{"file"}, Response: service.AttachmentResponse{}},
+		{Method: http.MethodPost, Path: "/move/attachment/list", Handler: controller.GetMoveAttachmentList, Tag: "move", Summary: "搬运照片列表",
+			Request: controller.GetMoveAttachmentListRequest{}, Response: dto.AttachmentList{}},
+		{Method: http.MethodPost, Path: "/move/attachment/delete", Handler: controller.DeleteMoveAttachment, Tag: "move", Summary: "删除搬运照片",
+			Request: controller.DeleteAttachmentRequest{}},
+		{Method: http.MethodPost, Path: "/move/handover/create", Handler: controller.CreateHandover, Tag: "move", Summary: "登记交接签收",
+			Request: controller.CreateHandoverRequest{}, Binding: BindForm, Files: []string{"signature"}, Response: service.HandoverResponse{}},
+		{Method: http.MethodPost, Path: "/move/handover/list", Handler: controller.GetHandoverList, Tag: "move", Summary: "交接记录列表",
+			Request: controller.GetHandoverListRequest{}, Response: []service.HandoverResponse{}},
+		{Method: http.MethodPost, Path: "/move/handover/receipt", Handler: controller.GenerateHandoverReceipt, Tag: "move", Summary: "生成送达回执PDF",
+			Request: controller.GenerateHandoverReceiptRequest{}, Produces: []string{contentTypePDF}},
+
+		// 标签模块
+		{Method: http.MethodPost, Path: "/tag/create", Handler: controller.CreateTag, Tag: "tag", Summary: "创建标签",
+			Request: controller.CreateTagRequest{}, Response: dto.Tag{}},
+		{Method: http.MethodPost, Path: "/tag/update", Handler: controller.UpdateTag, Tag: "tag", Summary: "编辑标签",
+			Request: controller.UpdateTagRequest{}, Response: dto.Tag{}},
+		{Method: http.MethodPost, Path: "/tag/delete", Handler: controller.DeleteTag, Tag: "tag", Summary: "删除标签",
+			Request: controller.DeleteTagRequest{}},
+		{Method: http.MethodPost, Path: "/tag/verify", Handler: controller.VerifyTag, Tag: "tag", Summary: "核销标签",
+			Request: controller.VerifyTagRequest{}, Response: dto.VerifyResult{}},
+		{Method: http.MethodPost, Path: "/tag/sync", Handler: controller.SyncTags, Tag: "tag", Summary: "离线扫码批量同步",
+			Request: controller.SyncTagsRequest{}, Response: service.SyncResponse{}},
+		{Method: http.MethodPost, Path: "/tag/import", Handler: controller.ImportTags, Tag: "tag", Summary: "从CSV/XLSX导入标签",
+			Request: controller.ImportTagsRequest{}, Binding: BindForm, Files: []string{"file"}, Response: service.ImportResult{}},
+		{Method: http.MethodPost, Path: "/tag/detail", Handler: controller.GetTagDetail, Tag: "tag", Summary: "标签详情",
+			Request: controller.GetTagDetailRequest{}, Response: dto.Tag{}},
+		{Method: http.MethodPost, Path: "/tag/list", Handler: controller.GetTagList, Tag: "tag", Summary: "标签列表",
+			Request: controller.GetTagListRequest{}, Response: []dto.Tag{}, Paginated: true},
+		{Method: http.MethodPost, Path: "/tag/list-by-room", Handler: controller.GetTagListByRoom, Tag: "tag", Summary: "按房间分组的标签列表",
+			Request: controller.GetTagListByRoomRequest{}, Response: []dto.TagRoomGroup{}},
+		{Method: http.MethodPost, Path: "/tag/generate-pdf", Handler: controller.GeneratePDF, Tag: "tag", Summary: "生成标签PDF",
+			Request: controller.GeneratePDFRequest{}, Produces: []string{contentTypePDF}},
+		{Method: http.MethodPost, Path: "/tag/manifest-pdf", Handler: controller.GenerateManifestPDF, Tag: "tag", Summary: "生成A4装箱清单PDF",
+			Request: controller.GeneratePDFRequest{}, Produces: []string{contentTypePDF}},
+		{Method: http.MethodPost, Path: "/tag/label", Handler: controller.RenderTagLabel, Tag: "tag", Summary: "生成单个标签图片(PNG/SVG)",
+			Request: controller.RenderTagLabelRequest{}, Produces: []string{"image/png", "image/svg+xml"}},
+		{Method: http.MethodPost, Path: "/tag/scan-link", Handler: controller.GetTagScanLink, Tag: "tag", Summary: "获取标签扫码链接",
+			Request: controller.TagScanLinkRequest{}, Response: service.ScanLinkResponse{}},
+		{Method: http.MethodPost, Path: "/tag/insurance-report", Handler: controller.GenerateInsuranceReport, Tag: "tag", Summary: "生成保险申报清单(PDF/CSV)",
+			Request: controller.GenerateInsuranceReportRequest{}, Produces: []string{contentTypePDF, contentTypeCSV}},
+		{Method: http.MethodPost, Path: "/tag/attachment/upload", Handler: controller.UploadTagAttachment, Tag: "tag", Summary: "上传标签照片",
+			Request: controller.UploadTagAttachmentRequest{}, Binding: BindForm, Files: []string{"file"}, Response: service.AttachmentResponse{}},
+		{Method: http.MethodPost, Path: "/tag/attachment/list", Handler: controller.GetTagAttachmentList, Tag: "tag", Summary: "标签照片列表",
+			Request: controller.GetTagAttachmentListRequest{}, Response: dto.AttachmentList{}},
+		{Method: http.MethodPost, Path: "/tag/attachment/delete", Handler: controller.DeleteTagAttachment, Tag: "tag", Summary: "删除标签照片",
+			Request: controller.DeleteAttachmentRequest{}},
+
+		// 房间模块
+		{Method: http.MethodPost, Path: "/room/create", Handler: controller.CreateRoom, Tag: "room", Summary: "创建房间",
+			Request: controller.CreateRoomRequest{}, Response: service.RoomResponse{}},
+		{Method: http.MethodPost, Path: "/room/update", Handler: controller.UpdateRoom, Tag: "room", Summary: "编辑房间",
+			Request: controller.UpdateRoomRequest{}, Response: service.RoomResponse{}},
+		{Method: http.MethodPost, Path: "/room/delete", Handler: controller.DeleteRoom, Tag: "room", Summary: "删除房间",
+			Request: controller.DeleteRoomRequest{}},
+		{Method: http.MethodPost, Path: "/room/list", Handler: controller.GetRoomList, Tag: "room", Summary: "房间列表",
+			Request: controller.GetRoomListRequest{}, Response: []service.RoomResponse{}},
+
+		// 物品模块
+		{Method: http.MethodPost, Path: "/item/create", Handler: controller.CreateItem, Tag: "item", Summary: "创建物品",
+			Request: controller.CreateItemRequest{}, Response: service.ItemResponse{}},
+		{Method: http.MethodPost, Path: "/item/update", Handler: controller.UpdateItem, Tag: "item", Summary: "编辑物品",
+			Request: controller.UpdateItemRequest{}, Response: service.ItemResponse{}},
+		{Method: http.MethodPost, Path: "/item/delete", Handler: controller.DeleteItem, Tag: "item", Summary: "删除物品",
+			Request: controller.DeleteItemRequest{}},
+		{Method: http.MethodPost, Path: "/item/list", Handler: controller.GetItemList, Tag: "item", Summary: "物品列表",
+			Request: controller.GetItemListRequest{}, Response: []service.ItemResponse{}},
+
+		// 丢失/损坏问题模块
+		{Method: http.MethodPost, Path: "/issue/create", Handler: controller.CreateIssue, Tag: "issue", Summary: "登记问题",
+			Request: controller.CreateIssueRequest{}, Response: service.IssueResponse{}},
+		{Method: http.MethodPost, Path: "/issue/update", Handler: controller.UpdateIssue, Tag: "issue", Summary: "更新处理进度",
+			Request: controller.UpdateIssueRequest{}, Response: service.IssueResponse{}},
+		{Method: http.MethodPost, Path: "/issue/delete", Handler: controller.DeleteIssue, Tag: "issue", Summary: "删除问题",
+			Request: controller.DeleteIssueRequest{}},
+		{Method: http.MethodPost, Path: "/issue/list", Handler: controller.GetIssueList, Tag: "issue", Summary: "问题列表",
+			Request: controller.GetIssueListRequest{}, Response: []service.IssueResponse{}},
+
+		// Webhook模块
+		{Method: http.MethodPost, Path: "/webhook/create", Handler: controller.CreateWebhook, Tag: "webhook", Summary: "创建Webhook",
+			Request: controller.CreateWebhookRequest{}, Response: service.WebhookResponse{}},
+		{Method: http.MethodPost, Path: "/webhook/update", Handler: controller.UpdateWebhook, Tag: "webhook", Summary: "编辑Webhook",
+			Request: controller.UpdateWebhookRequest{}, Response: service.WebhookResponse{}},
+		{Method: http.MethodPost, Path: "/webhook/delete", Handler: controller.DeleteWebhook, Tag: "webhook", Summary: "删除Webhook",
+			Request: controller.DeleteWebhookRequest{}},
+		{Method: http.MethodPost, Path: "/webhook/list", Handler: controller.GetWebhookList, Tag: "webhook", Summary: "Webhook列表",
+			Response: []service.WebhookResponse{}},
+		{Method: http.MethodPost, Path: "/webhook/deliveries", Handler: controller.GetWebhookDeliveries, Tag: "webhook", Summary: "投递记录",
+			Request: controller.GetWebhookDeliveriesRequest{}, Response: []service.WebhookDeliveryResponse{}, Paginated: true},
+
+		// 账户模块
+		{Method: http.MethodPost, Path: "/account/backup", Handler: controller.BackupAccount, Tag: "account", Summary: "导出账户备份(zip)",
+			Produces: []string{"application/zip"}},
+		{Method: http.MethodPost, Path: "/account/restore", Handler: controller.RestoreAccount, Tag: "account", Summary: "从备份恢复账户数据",
+			Binding: BindForm, Request: struct{}{}, Files: []string{"file"}, Response: service.RestoreResult{}},
+
+		// 附件模块
+		{Method: http.MethodPost, Path: "/attachment/download", Handler: controller.DownloadAttachment, Tag: "attachment", Summary: "下载附件原图或缩略图",
+			Request: controller.DownloadAttachmentRequest{}, Produces: []string{"image/jpeg", "image/png", "image/webp"}},
+	}
+}
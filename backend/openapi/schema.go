@@ -0,0 +1,300 @@
+package openapi
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"movingManager/common"
+)
+
+// Schema OpenAPI数据结构描述
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaBuilder 通过反射由Go类型生成数据结构描述
+// 具名结构体登记到components中并以$ref引用，支持递归类型
+type schemaBuilder struct {
+	schemas map[string]*Schema
+}
+
+func newSchemaBuilder() *schemaBuilder {
+	return &schemaBuilder{schemas: map[string]*Schema{}}
+}
+
+// schemaName 组件名称，带包名避免不同包的同名类型冲突
+func schemaName(t reflect.Type) string {
+	pkg := t.PkgPath()
+	if idx := strings.LastIndex(pkg, "/"); idx >= 0 {
+		pkg = pkg[idx+1:]
+	}
+	return pkg + "." + t.Name()
+}
+
+// ref 引用已登记的组件
+func ref(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// schemaOf 生成类型的数据结构描述
+func (b *schemaBuilder) schemaOf(t reflect.Type) *Schema {
+	if t.Kind() == reflect.Ptr {
+		return b.schemaOf(t.Elem())
+	}
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: b.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: b.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.objectSchema(t)
+		}
+		name := schemaName(t)
+		if _, ok := b.schemas[name]; !ok {
+			// 先占位再展开字段，避免递归类型无限展开
+			b.schemas[name] = &Schema{}
+			*b.schemas[name] = *b.objectSchema(t)
+		}
+		return ref(name)
+	default:
+		// interface{} 等任意类型
+		return &Schema{}
+	}
+}
+
+// objectSchema 展开结构体字段生成对象描述
+// 字段名取json标签，没有json标签时取form标签；匿名嵌入的结构体字段提升到外层
+func (b *schemaBuilder) objectSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	b.addFields(s, t)
+	return s
+}
+
+func (b *schemaBuilder) addFields(s *Schema, t reflect.Type) {
+	// 与encoding/json一致，外层字段优先于嵌入结构体中的同名字段
+	var embedded []reflect.Type
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, ok := fieldName(field)
+		if !ok {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded = append(embedded, ft)
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+		b.addField(s, name, field)
+	}
+
+	for _, et := range embedded {
+		inner := &Schema{Properties: map[string]*Schema{}}
+		b.addFields(inner, et)
+		names := make([]string, 0, len(inner.Properties))
+		for name := range inner.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if _, ok := s.Properties[name]; ok {
+				continue
+			}
+			s.Properties[name] = inner.Properties[name]
+			if contains(inner.Required, name) {
+				s.Required = append(s.Required, name)
+			}
+		}
+	}
+}
+
+// addField 添加单个字段的描述
+func (b *schemaBuilder) addField(s *Schema, name string, field reflect.StructField) {
+	prop := b.schemaOf(field.Type)
+	if field.Type.Kind() == reflect.Ptr && prop.Ref == "" {
+		prop.Nullable = true
+	}
+	if applyBinding(prop, field.Type, field.Tag.Get("binding")) {
+		s.Required = append(s.Required, name)
+	}
+	s.Properties[name] = prop
+}
+
+// fieldName 字段的序列化名称，ok为false表示字段不参与序列化
+// 返回空名称表示未设置标签，由调用方决定是否展开匿名字段
+func fieldName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() && !field.Anonymous {
+		return "", false
+	}
+	tag, ok := field.Tag.Lookup("json")
+	if !ok {
+		tag, ok = field.Tag.Lookup("form")
+	}
+	if !ok {
+		return "", true
+	}
+	name := strings.Split(tag, ",")[0]
+	if name == "-" {
+		return "", false
+	}
+	return name, true
+}
+
+// applyBinding 将gin绑定校验规则转换为数据结构约束，返回字段是否必填
+// 引用类型的结构体不附加约束；dive之后的规则作用于数组元素
+func applyBinding(s *Schema, t reflect.Type, binding string) bool {
+	binding = expandAliases(binding)
+	if binding == "" || s.Ref != "" {
+		return binding != "" && hasRule(binding, "required")
+	}
+
+	required := false
+	target, targetType := s, t
+	for _, rule := range strings.Split(binding, ",") {
+		// len=0|xxx 表示允许传空字符串，其余按xxx描述
+		if alt, ok := strings.CutPrefix(rule, "len=0|"); ok {
+			rule = alt
+		}
+		name, param, _ := strings.Cut(rule, "=")
+		if targetType.Kind() == reflect.Ptr {
+			targetType = targetType.Elem()
+		}
+		switch name {
+		case "required":
+			if target == s {
+				required = true
+			}
+		case "dive":
+			if target.Items == nil {
+				return required
+			}
+			target, targetType = target.Items, targetType.Elem()
+		case "oneof":
+			for _, v := range strings.Fields(param) {
+				if target.Type == "integer" {
+					if n, err := strconv.Atoi(v); err == nil {
+						target.Enum = append(target.Enum, n)
+						continue
+					}
+				}
+				target.Enum = append(target.Enum, v)
+			}
+		case "min", "max":
+			n, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				continue
+			}
+			applyLimit(target, targetType, name == "min", n)
+		case "uuid":
+			target.Format = "uuid"
+		case "url":
+			target.Format = "uri"
+		case "hexcolor":
+			target.Pattern = "^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$"
+		case "iso4217":
+			target.Pattern = "^[A-Z]{3}$"
+		case "required_without":
+			target.Description = "与 " + param + " 至少填写一个"
+		}
+	}
+	return required
+}
+
+// expandAliases 将校验规则别名替换为实际规则
+func expandAliases(binding string) string {
+	if binding == "" {
+		return binding
+	}
+	rules := strings.Split(binding, ",")
+	for i, rule := range rules {
+		if expanded, ok := common.FieldRuleAliases[rule]; ok {
+			rules[i] = expanded
+		}
+	}
+	return strings.Join(rules, ",")
+}
+
+// applyLimit 按字段类型设置长度、数量或数值范围
+func applyLimit(s *Schema, t reflect.Type, isMin bool, n float64) {
+	switch t.Kind() {
+	case reflect.String:
+		limit := int(n)
+		if isMin {
+			s.MinLength = &limit
+		} else {
+			s.MaxLength = &limit
+		}
+	case reflect.Slice, reflect.Array, reflect.Map:
+		limit := int(n)
+		if isMin {
+			s.MinItems = &limit
+		} else {
+			s.MaxItems = &limit
+		}
+	default:
+		if isMin {
+			s.Minimum = &n
+		} else {
+			s.Maximum = &n
+		}
+	}
+}
+
+// hasRule 判断校验规则中是否包含指定规则(不含dive之后的元素规则)
+func hasRule(binding, rule string) bool {
+	for _, r := range strings.Split(binding, ",") {
+		if r == "dive" {
+			return false
+		}
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
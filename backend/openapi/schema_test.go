@@ -0,0 +1,88 @@
+package openapi
+
+import (
+	"reflect"
+	"testing"
+)
+
+type testChild struct {
+	Name string `json:"name"`
+}
+
+type testNode struct {
+	testChild
+	Uid      string      `json:"uid" binding:"required,uuid"`
+	Status   int         `json:"status" binding:"omitempty,oneof=0 1 2"`
+	Title    string      `json:"title" binding:"required,max=100"`
+	Remark   string      `json:"remark" binding:"remark"`
+	Value    int64       `json:"value" binding:"min=0"`
+	Refs     []string    `json:"refs" binding:"max=10,dive,max=500"`
+	Optional *string     `json:"optional" binding:"omitempty,uuid"`
+	Children []testNode  `json:"children,omitempty"`
+	Skipped  string      `json:"-"`
+	Form     string      `form:"form_field"`
+	Any      interface{} `json:"any"`
+}
+
+func TestSchemaOf(t *testing.T) {
+	b := newSchemaBuilder()
+	s := b.schemaOf(reflect.TypeOf(testNode{}))
+	if s.Ref != "#/components/schemas/openapi.testNode" {
+		t.Fatalf("schemaOf() ref = %q", s.Ref)
+	}
+	node := b.schemas["openapi.testNode"]
+
+	if !reflect.DeepEqual(node.Required, []string{"uid", "title"}) {
+		t.Errorf("required = %v", node.Required)
+	}
+	if _, ok := node.Properties["name"]; !ok {
+		t.Error("匿名嵌入字段未展开")
+	}
+	if _, ok := node.Properties["-"]; ok {
+		t.Error("json:\"-\" 字段未忽略")
+	}
+	if _, ok := node.Properties["form_field"]; !ok {
+		t.Error("form标签字段缺失")
+	}
+
+	cases := []struct {
+		name  string
+		check func(p *Schema) bool
+	}{
+		{"uid", func(p *Schema) bool { return p.Type == "string" && p.Format == "uuid" }},
+		{"status", func(p *Schema) bool { return reflect.DeepEqual(p.Enum, []interface{}{0, 1, 2}) }},
+		{"title", func(p *Schema) bool { return p.MaxLength != nil && *p.MaxLength == 100 }},
+		{"remark", func(p *Schema) bool { return p.MaxLength != nil && *p.MaxLength == 500 }},
+		{"value", func(p *Schema) bool { return p.Format == "int64" && p.Minimum != nil && *p.Minimum == 0 }},
+		{"refs", func(p *Schema) bool {
+			return *p.MaxItems == 10 && p.Items.MaxLength != nil && *p.Items.MaxLength == 500
+		}},
+		{"optional", func(p *Schema) bool { return p.Nullable && p.Format == "uuid" }},
+		{"children", func(p *Schema) bool { return p.Type == "array" && p.Items.Ref == s.Ref }},
+		{"any", func(p *Schema) bool { return p.Type == "" }},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, ok := node.Properties[tc.name]
+			if !ok {
+				t.Fatalf("缺少字段 %s", tc.name)
+			}
+			if !tc.check(p) {
+				t.Errorf("字段 %s = %+v", tc.name, p)
+			}
+		})
+	}
+}
+
+func TestOperationID(t *testing.T) {
+	cases := map[string]string{
+		"/user/auth":            "userAuth",
+		"/tag/list-by-room":     "tagListByRoom",
+		"/move/attachment/list": "moveAttachmentList",
+	}
+	for path, want := range cases {
+		if got := operationID(path); got != want {
+			t.Errorf("operationID(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package ratelimit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/ulule/limiter/v3"
+	"github.com/ulule/limiter/v3/drivers/store/memory"
+	redisStore "github.com/ulule/limiter/v3/drivers/store/redis"
+
+	"movingManager/config"
+)
+
+// 限流规则名称，对应配置 rate_limit.rules 中的键
+const (
+	RulePublic     = "public"      // 免登录接口，按IP计数
+	RuleAuthIP     = "auth_ip"     // 登录接口，按IP计数
+	RuleAuthMobile = "auth_mobile" // 登录接口，按手机号计数
+	RuleUser       = "user"        // 需登录的接口，按用户UID计数
+	RuleAPIIP      = "api_ip"      // 需登录的接口，认证前按IP计数，限制无效令牌反复查库
+)
+
+// storePrefix 计数键前缀
+const storePrefix = "moving_manager_limiter"
+
+// defaultRules 未配置时的默认限额，格式为"次数-周期"，周期为S/M/H/D
+var defaultRules = map[string]string{
+	RulePublic:     "60-M",
+	RuleAuthIP:     "20-M",
+	RuleAuthMobile: "5-M",
+	RuleUser:       "300-M",
+	RuleAPIIP:      "1200-M", // 高于单用户限额，同一网络下的多个用户不会互相影响
+}
+
+// Store 限流计数存储
+// 单实例使用内存存储；多实例部署时使用共享存储(如Redis)，使计数在实例间共享
+type Store = limiter.Store
+
+// StoreFactory 根据配置创建计数存储
+type StoreFactory func(cfg config.RateLimitConfig) (Store, error)
+
+// storeFactories 已注册的计数存储驱动
+var storeFactories = map[string]StoreFactory{
+	"memory": newMemoryStore,
+	"redis":  newRedisStore,
+}
+
+// limiters 按规则名称索引的限流器，Init之前为空
+var limiters map[string]*limiter.Limiter
+
+// RegisterStore 注册计数存储驱动，需在 Init 之前调用
+func RegisterStore(name string, factory StoreFactory) {
+	storeFactories[name] = factory
+}
+
+// Init 根据配置创建计数存储和各规则的限流器
+func Init(cfg config.RateLimitConfig) error {
+	driver := cfg.Store
+	if driver == "" {
+		driver = "memory"
+	}
+	factory, ok := storeFactories[driver]
+	if !ok {
+		return fmt.Errorf("不支持的限流存储: %s", driver)
+	}
+	store, err := factory(cfg)
+	if err != nil {
+		return fmt.Errorf("创建限流存储失败: %v", err)
+	}
+
+	rules, err := parseRules(cfg.Rules)
+	if err != nil {
+		return err
+	}
+	built := make(map[string]*limiter.Limiter, len(rules))
+	for name, rate := range rules {
+		built[name] = limiter.New(store, rate)
+	}
+	limiters = built
+	return nil
+}
+
+// Limiter 获取规则对应的限流器，未初始化或规则关闭时返回nil
+func Limiter(rule string) *limiter.Limiter {
+	return limiters[rule]
+}
+
+// parseRules 合并默认限额和配置限额，配置为"off"时关闭该规则
+func parseRules(configured map[string]string) (map[string]limiter.Rate, error) {
+	merged := make(map[string]string, len(defaultRules))
+	for name, rate := range defaultRules {
+		merged[name] = rate
+	}
+	for name, rate := range configured {
+		if _, ok := defaultRules[name]; !ok {
+			return nil, fmt.Errorf("未知的限流规则: %s，可选: %s", name, strings.Join(ruleNames(), ", "))
+		}
+		merged[name] = rate
+	}
+
+	rates := make(map[string]limiter.Rate, len(merged))
+	for name, formatted := range merged {
+		if formatted == "off" {
+			continue
+		}
+		rate, err := limiter.NewRateFromFormatted(formatted)
+		if err != nil {
+			return nil, fmt.Errorf("限流规则 %s 格式错误: %v", name, err)
+		}
+		rates[name] = rate
+	}
+	return rates, nil
+}
+
+// ruleNames 全部规则名称
+func ruleNames() []string {
+	names := make([]string, 0, len(defaultRules))
+	for name := range defaultRules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// newMemoryStore 进程内计数存储
+func newMemoryStore(cfg config.RateLimitConfig) (Store, error) {
+	return memory.NewStoreWithOptions(limiter.StoreOptions{Prefix: storePrefix}), nil
+}
+
+// newRedisStore Redis计数存储，多个实例共享计数
+func newRedisStore(cfg config.RateLimitConfig) (Store, error) {
+	if cfg.RedisURL == "" {
+		return nil, fmt.Errorf("未配置 rate_limit.redis_url")
+	}
+	options, err := redis.ParseURL(cfg.RedisURL)
+	if err != nil {
+		return nil, err
+	}
+	return redisStore.NewStoreWithOptions(redis.NewClient(options), limiter.StoreOptions{Prefix: storePrefix})
+}
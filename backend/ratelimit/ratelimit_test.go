@@ -0,0 +1,63 @@
+package ratelimit
+
+import (
+	"testing"
+
+	"movingManager/config"
+)
+
+func TestParseRules(t *testing.T) {
+	cases := []struct {
+		name       string
+		configured map[string]string
+		wantRules  int
+		wantLimit  int64 // user 规则的限额，0表示规则关闭
+		wantErr    bool
+	}{
+		{"使用默认限额", nil, 5, 300, false},
+		{"覆盖部分规则", map[string]string{RuleUser: "1000-H"}, 5, 1000, false},
+		{"关闭规则", map[string]string{RuleUser: "off"}, 4, 0, false},
+		{"未知规则", map[string]string{"admin": "10-M"}, 0, 0, true},
+		{"格式错误", map[string]string{RuleUser: "many"}, 0, 0, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rates, err := parseRules(tc.configured)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("parseRules() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(rates) != tc.wantRules {
+				t.Errorf("规则数 = %d, want %d", len(rates), tc.wantRules)
+			}
+			if rates[RuleUser].Limit != tc.wantLimit {
+				t.Errorf("user 限额 = %d, want %d", rates[RuleUser].Limit, tc.wantLimit)
+			}
+		})
+	}
+}
+
+func TestInitStore(t *testing.T) {
+	cases := []struct {
+		name    string
+		cfg     config.RateLimitConfig
+		wantErr bool
+	}{
+		{"默认内存存储", config.RateLimitConfig{}, false},
+		{"未知存储", config.RateLimitConfig{Store: "etcd"}, true},
+		{"Redis未配置地址", config.RateLimitConfig{Store: "redis"}, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Init(tc.cfg)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Init() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if err == nil && Limiter(RuleAuthMobile) == nil {
+				t.Error("初始化后应存在 auth_mobile 限流器")
+			}
+		})
+	}
+}
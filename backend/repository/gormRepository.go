@@ -0,0 +1,330 @@
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"movingManager/model"
+)
+
+// gormStore 基于GORM的仓储实现
+type gormStore struct {
+	db *gorm.DB
+}
+
+// NewGormStore 创建基于GORM的仓储集合
+// db 可以是数据库连接，也可以是已开启的事务
+func NewGormStore(db *gorm.DB) Store {
+	return &gormStore{db: db}
+}
+
+func (s *gormStore) Moves() MoveRepository { return &gormMoveRepository{db: s.db} }
+func (s *gormStore) Tags() TagRepository   { return &gormTagRepository{db: s.db} }
+func (s *gormStore) Rooms() RoomRepository { return &gormRoomRepository{db: s.db} }
+func (s *gormStore) Items() ItemRepository { return &gormItemRepository{db: s.db} }
+func (s *gormStore) Users() UserRepository { return &gormUserRepository{db: s.db} }
+func (s *gormStore) Webhooks() WebhookRepository {
+	return &gormWebhookRepository{db: s.db}
+}
+
+// Transaction 在数据库事务中执行fn
+func (s *gormStore) Transaction(fn func(Store) error) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&gormStore{db: tx})
+	})
+}
+
+// translateErr 将GORM的记录不存在错误转换为 ErrNotFound，其他错误原样返回
+func translateErr(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// undeletedScope 根据onlyUndeleted追加未删除条件
+func undeletedScope(onlyUndeleted bool) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if onlyUndeleted {
+			return db.Where("is_deleted = 0")
+		}
+		return db
+	}
+}
+
+// nonNegativeIncrement 计数列加上增量，结果不小于0
+// 使用CASE而不是GREATEST，PostgreSQL和SQLite均支持
+func nonNegativeIncrement(column string, delta int) clause.Expr {
+	return gorm.Expr("CASE WHEN "+column+" + ? > 0 THEN "+column+" + ? ELSE 0 END", delta, delta)
+}
+
+// gormMoveRepository 搬运记录仓储的GORM实现
+type gormMoveRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormMoveRepository) Create(move *model.MoveModel) error {
+	return r.db.Create(move).Error
+}
+
+func (r *gormMoveRepository) GetByUID(userUid, moveUid string, onlyUndeleted bool) (*model.MoveModel, error) {
+	var move model.MoveModel
+	if err := r.db.Scopes(undeletedScope(onlyUndeleted)).Where("user_uid = ? AND move_uid = ?", userUid, moveUid).First(&move).Error; err != nil {
+		return nil, translateErr(err)
+	}
+	return &move, nil
+}
+
+func (r *gormMoveRepository) Update(move *model.MoveModel) error {
+	return r.db.Save(move).Error
+}
+
+func (r *gormMoveRepository) ListByUser(userUid string, page, pageSize int, onlyUndeleted bool) ([]model.MoveModel, int64, error) {
+	var moves []model.MoveModel
+	var total int64
+	query := r.db.Model(&model.MoveModel{}).Scopes(undeletedScope(onlyUndeleted)).Where("user_uid = ?", userUid)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := query.Order("is_completed ASC, move_at ASC").Limit(pageSize).Offset((page - 1) * pageSize).Find(&moves).Error; err != nil {
+		return nil, 0, err
+	}
+	return moves, total, nil
+}
+
+func (r *gormMoveRepository) AdjustTagCounts(move *model.MoveModel, total, verified, unverified int) error {
+	updates := map[string]interface{}{
+		"tag_count":            nonNegativeIncrement("tag_count", total),
+		"verified_tag_count":   nonNegativeIncrement("verified_tag_count", verified),
+		"unverified_tag_count": nonNegativeIncrement("unverified_tag_count", unverified),
+	}
+	if unverified > 0 {
+		updates["is_completed"] = 0
+	} else if unverified < 0 {
+		// SET 子句中的列引用均为更新前的值
+		updates["is_completed"] = gorm.Expr("CASE WHEN unverified_tag_count + ? <= 0 AND tag_count + ? > 0 THEN 1 ELSE is_completed END", unverified, total)
+	}
+	return r.db.Model(move).Updates(updates).Error
+}
+
+// gormTagRepository 标签仓储的GORM实现
+type gormTagRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormTagRepository) Create(tag *model.TagModel) error {
+	return r.db.Create(tag).Error
+}
+
+func (r *gormTagRepository) GetByUID(userUid, tagUid string, onlyUndeleted bool) (*model.TagModel, error) {
+	var tag model.TagModel
+	if err := r.db.Scopes(undeletedScope(onlyUndeleted)).Where("user_uid = ? AND tag_uid = ?", userUid, tagUid).First(&tag).Error; err != nil {
+		return nil, translateErr(err)
+	}
+	return &tag, nil
+}
+
+func (r *gormTagRepository) Update(tag *model.TagModel) error {
+	return r.db.Save(tag).Error
+}
+
+func (r *gormTagRepository) ListByMove(userUid, moveUid string, page, pageSize int) ([]model.TagModel, int64, error) {
+	var tags []model.TagModel
+	var total int64
+	query := r.db.Model(&model.TagModel{}).Where("user_uid = ? AND move_uid = ? AND is_deleted = 0", userUid, moveUid)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := query.Order("is_verified asc, created_at asc, id asc").Limit(pageSize).Offset((page - 1) * pageSize).Find(&tags).Error; err != nil {
+		return nil, 0, err
+	}
+	return tags, total, nil
+}
+
+func (r *gormTagRepository) GetChildren(userUid, parentTagUid string) ([]model.TagModel, error) {
+	var tagModel model.TagModel
+	return tagModel.GetChildrenTx(r.db, userUid, parentTagUid)
+}
+
+func (r *gormTagRepository) MapByUIDs(userUid string, tagUids []string) (map[string]model.TagModel, error) {
+	tagMap := make(map[string]model.TagModel)
+	if len(tagUids) == 0 {
+		return tagMap, nil
+	}
+	var tags []model.TagModel
+	if err := r.db.Where("user_uid = ? AND tag_uid IN ? AND is_deleted = 0", userUid, tagUids).Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	for _, tag := range tags {
+		tagMap[tag.TagUid] = tag
+	}
+	return tagMap, nil
+}
+
+func (r *gormTagRepository) CountChildren(userUid string, parentTagUids []string) (map[string]int, error) {
+	counts := make(map[string]int)
+	if len(parentTagUids) == 0 {
+		return counts, nil
+	}
+	var rows []struct {
+		ParentTagUid string
+		Total        int
+	}
+	if err := r.db.Model(&model.TagModel{}).
+		Select("parent_tag_uid, COUNT(*) AS total").
+		Where("user_uid = ? AND parent_tag_uid IN ? AND is_deleted = 0", userUid, parentTagUids).
+		Group("parent_tag_uid").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		counts[row.ParentTagUid] = row.Total
+	}
+	return counts, nil
+}
+
+func (r *gormTagRepository) ListAllByMove(userUid, moveUid string) ([]model.TagModel, error) {
+	var tags []model.TagModel
+	if err := r.db.Where("user_uid = ? AND move_uid = ? AND is_deleted = 0", userUid, moveUid).Order("created_at asc, id asc").Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// gormRoomRepository 房间仓储的GORM实现
+type gormRoomRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormRoomRepository) Create(room *model.RoomModel) error {
+	return r.db.Create(room).Error
+}
+
+func (r *gormRoomRepository) GetByUID(userUid, roomUid string, onlyUndeleted bool) (*model.RoomModel, error) {
+	var room model.RoomModel
+	if err := r.db.Scopes(undeletedScope(onlyUndeleted)).Where("user_uid = ? AND room_uid = ?", userUid, roomUid).First(&room).Error; err != nil {
+		return nil, translateErr(err)
+	}
+	return &room, nil
+}
+
+func (r *gormRoomRepository) Update(room *model.RoomModel) error {
+	return r.db.Save(room).Error
+}
+
+func (r *gormRoomRepository) ListByMove(userUid, moveUid string, side int) ([]model.RoomModel, error) {
+	var rooms []model.RoomModel
+	query := r.db.Where("user_uid = ? AND move_uid = ? AND is_deleted = 0", userUid, moveUid)
+	if side != 0 {
+		query = query.Where("side = ?", side)
+	}
+	if err := query.Order("side asc, sort asc, created_at asc").Find(&rooms).Error; err != nil {
+		return nil, err
+	}
+	return rooms, nil
+}
+
+func (r *gormRoomRepository) MapByMove(userUid, moveUid string) (map[string]model.RoomModel, error) {
+	rooms, err := r.ListByMove(userUid, moveUid, 0)
+	if err != nil {
+		return nil, err
+	}
+	roomMap := make(map[string]model.RoomModel, len(rooms))
+	for _, room := range rooms {
+		roomMap[room.RoomUid] = room
+	}
+	return roomMap, nil
+}
+
+// gormItemRepository 物品仓储的GORM实现
+type gormItemRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormItemRepository) Create(item *model.ItemModel) error {
+	return r.db.Create(item).Error
+}
+
+func (r *gormItemRepository) GetByUID(userUid, itemUid string, onlyUndeleted bool) (*model.ItemModel, error) {
+	var item model.ItemModel
+	if err := r.db.Scopes(undeletedScope(onlyUndeleted)).Where("user_uid = ? AND item_uid = ?", userUid, itemUid).First(&item).Error; err != nil {
+		return nil, translateErr(err)
+	}
+	return &item, nil
+}
+
+func (r *gormItemRepository) Update(item *model.ItemModel) error {
+	return r.db.Save(item).Error
+}
+
+func (r *gormItemRepository) ListByTag(userUid, tagUid string) ([]model.ItemModel, error) {
+	var items []model.ItemModel
+	if err := r.db.Where("user_uid = ? AND tag_uid = ? AND is_deleted = 0", userUid, tagUid).Order("created_at asc, id asc").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// gormUserRepository 用户仓储的GORM实现
+type gormUserRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormUserRepository) Create(user *model.UserModel) error {
+	return r.db.Create(user).Error
+}
+
+func (r *gormUserRepository) GetByMobile(mobile string) (*model.UserModel, error) {
+	var user model.UserModel
+	if err := r.db.Where("mobile = ? AND is_deleted = 0", mobile).First(&user).Error; err != nil {
+		return nil, translateErr(err)
+	}
+	return &user, nil
+}
+
+func (r *gormUserRepository) GetByAuthCode(authCode string) (*model.UserModel, error) {
+	var user model.UserModel
+	if err := r.db.Where("authorization_code = ? AND is_deleted = 0", authCode).First(&user).Error; err != nil {
+		return nil, translateErr(err)
+	}
+	return &user, nil
+}
+
+func (r *gormUserRepository) UpdateAuthCode(user *model.UserModel, authCode string) error {
+	if err := r.db.Model(user).Update("authorization_code", authCode).Error; err != nil {
+		return err
+	}
+	user.AuthCode = authCode
+	return nil
+}
+
+// gormWebhookRepository Webhook仓储的GORM实现
+type gormWebhookRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormWebhookRepository) Create(webhook *model.WebhookModel) error {
+	return r.db.Create(webhook).Error
+}
+
+func (r *gormWebhookRepository) ListEnabledByUser(userUid string) ([]model.WebhookModel, error) {
+	var webhooks []model.WebhookModel
+	if err := r.db.Where("user_uid = ? AND is_enabled = 1 AND is_deleted = 0", userUid).Order("id asc").Find(&webhooks).Error; err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+func (r *gormWebhookRepository) CreateDelivery(delivery *model.WebhookDeliveryModel) error {
+	return r.db.Create(delivery).Error
+}
+
+func (r *gormWebhookRepository) ListDeliveries(userUid, webhookUid string) ([]model.WebhookDeliveryModel, error) {
+	var deliveries []model.WebhookDeliveryModel
+	if err := r.db.Where("user_uid = ? AND webhook_uid = ?", userUid, webhookUid).Order("id asc").Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
@@ -0,0 +1,552 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"movingManager/model"
+)
+
+// memoryData 内存仓储的数据，按UID索引
+type memoryData struct {
+	moves  map[string]model.MoveModel
+	tags   map[string]model.TagModel
+	rooms  map[string]model.RoomModel
+	items  map[string]model.ItemModel
+	users  map[string]model.UserModel
+	nextID uint
+
+	webhooks   map[string]model.WebhookModel
+	deliveries []model.WebhookDeliveryModel
+}
+
+// clone 复制数据，用于事务回滚
+func (d *memoryData) clone() *memoryData {
+	c := &memoryData{
+		moves:  make(map[string]model.MoveModel, len(d.moves)),
+		tags:   make(map[string]model.TagModel, len(d.tags)),
+		rooms:  make(map[string]model.RoomModel, len(d.rooms)),
+		items:  make(map[string]model.ItemModel, len(d.items)),
+		users:  make(map[string]model.UserModel, len(d.users)),
+		nextID: d.nextID,
+
+		webhooks:   make(map[string]model.WebhookModel, len(d.webhooks)),
+		deliveries: append([]model.WebhookDeliveryModel(nil), d.deliveries...),
+	}
+	for k, v := range d.moves {
+		c.moves[k] = v
+	}
+	for k, v := range d.tags {
+		c.tags[k] = v
+	}
+	for k, v := range d.rooms {
+		c.rooms[k] = v
+	}
+	for k, v := range d.items {
+		c.items[k] = v
+	}
+	for k, v := range d.users {
+		c.users[k] = v
+	}
+	for k, v := range d.webhooks {
+		c.webhooks[k] = v
+	}
+	return c
+}
+
+// MemoryStore 基于内存的仓储实现，用于单元测试
+// 所有操作串行执行；事务期间持有锁，失败时恢复到事务开始前的数据
+type MemoryStore struct {
+	mu   *sync.Mutex
+	data **memoryData
+	inTx bool
+}
+
+// NewMemoryStore 创建空的内存仓储
+func NewMemoryStore() *MemoryStore {
+	data := &memoryData{
+		moves: make(map[string]model.MoveModel),
+		tags:  make(map[string]model.TagModel),
+		rooms: make(map[string]model.RoomModel),
+		items: make(map[string]model.ItemModel),
+		users: make(map[string]model.UserModel),
+
+		webhooks: make(map[string]model.WebhookModel),
+	}
+	return &MemoryStore{mu: &sync.Mutex{}, data: &data}
+}
+
+func (s *MemoryStore) Moves() MoveRepository { return &memoryMoveRepository{s} }
+func (s *MemoryStore) Tags() TagRepository   { return &memoryTagRepository{s} }
+func (s *MemoryStore) Rooms() RoomRepository { return &memoryRoomRepository{s} }
+func (s *MemoryStore) Items() ItemRepository { return &memoryItemRepository{s} }
+func (s *MemoryStore) Users() UserRepository { return &memoryUserRepository{s} }
+func (s *MemoryStore) Webhooks() WebhookRepository {
+	return &memoryWebhookRepository{s}
+}
+
+// Transaction 在事务中执行fn，嵌套事务并入外层事务
+func (s *MemoryStore) Transaction(fn func(Store) error) error {
+	if s.inTx {
+		return fn(s)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	snapshot := (*s.data).clone()
+	if err := fn(&MemoryStore{mu: s.mu, data: s.data, inTx: true}); err != nil {
+		*s.data = snapshot
+		return err
+	}
+	return nil
+}
+
+// with 加锁访问数据，事务中已持有锁
+func (s *MemoryStore) with(fn func(d *memoryData)) {
+	if !s.inTx {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+	}
+	fn(*s.data)
+}
+
+// paginate 截取分页数据
+func paginate[T any](records []T, page, pageSize int) []T {
+	start := (page - 1) * pageSize
+	if start >= len(records) {
+		return []T{}
+	}
+	end := start + pageSize
+	if end > len(records) {
+		end = len(records)
+	}
+	return records[start:end]
+}
+
+// clampCount 计数不小于0
+func clampCount(n int) int {
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+// memoryMoveRepository 搬运记录仓储的内存实现
+type memoryMoveRepository struct {
+	s *MemoryStore
+}
+
+func (r *memoryMoveRepository) Create(move *model.MoveModel) error {
+	r.s.with(func(d *memoryData) {
+		if move.MoveUid == "" {
+			move.MoveUid = uuid.New().String()
+		}
+		d.nextID++
+		move.ID = d.nextID
+		move.CreatedAt = time.Now().Unix()
+		d.moves[move.MoveUid] = *move
+	})
+	return nil
+}
+
+func (r *memoryMoveRepository) GetByUID(userUid, moveUid string, onlyUndeleted bool) (*model.MoveModel, error) {
+	var move model.MoveModel
+	var ok bool
+	r.s.with(func(d *memoryData) {
+		move, ok = d.moves[moveUid]
+	})
+	if !ok || move.UserUid != userUid || (onlyUndeleted && move.IsDeleted != 0) {
+		return nil, ErrNotFound
+	}
+	return &move, nil
+}
+
+func (r *memoryMoveRepository) Update(move *model.MoveModel) error {
+	r.s.with(func(d *memoryData) {
+		move.UpdatedAt = time.Now().Unix()
+		d.moves[move.MoveUid] = *move
+	})
+	return nil
+}
+
+func (r *memoryMoveRepository) ListByUser(userUid string, page, pageSize int, onlyUndeleted bool) ([]model.MoveModel, int64, error) {
+	var moves []model.MoveModel
+	r.s.with(func(d *memoryData) {
+		for _, move := range d.moves {
+			if move.UserUid == userUid && (!onlyUndeleted || move.IsDeleted == 0) {
+				moves = append(moves, move)
+			}
+		}
+	})
+	sort.Slice(moves, func(i, j int) bool {
+		if moves[i].IsCompleted != moves[j].IsCompleted {
+			return moves[i].IsCompleted < moves[j].IsCompleted
+		}
+		if moves[i].MoveAt != moves[j].MoveAt {
+			return moves[i].MoveAt < moves[j].MoveAt
+		}
+		return moves[i].ID < moves[j].ID
+	})
+	return paginate(moves, page, pageSize), int64(len(moves)), nil
+}
+
+func (r *memoryMoveRepository) AdjustTagCounts(move *model.MoveModel, total, verified, unverified int) error {
+	var err error
+	r.s.with(func(d *memoryData) {
+		current, ok := d.moves[move.MoveUid]
+		if !ok {
+			err = ErrNotFound
+			return
+		}
+		current.TagCount = clampCount(current.TagCount + total)
+		current.VerifiedTagCount = clampCount(current.VerifiedTagCount + verified)
+		current.UnverifiedTagCount = clampCount(current.UnverifiedTagCount + unverified)
+		if unverified > 0 {
+			current.IsCompleted = 0
+		} else if unverified < 0 && current.UnverifiedTagCount == 0 && current.TagCount > 0 {
+			current.IsCompleted = 1
+		}
+		current.UpdatedAt = time.Now().Unix()
+		d.moves[move.MoveUid] = current
+	})
+	return err
+}
+
+// memoryTagRepository 标签仓储的内存实现
+type memoryTagRepository struct {
+	s *MemoryStore
+}
+
+func (r *memoryTagRepository) Create(tag *model.TagModel) error {
+	r.s.with(func(d *memoryData) {
+		if tag.TagUid == "" {
+			tag.TagUid = uuid.New().String()
+		}
+		d.nextID++
+		tag.ID = d.nextID
+		tag.CreatedAt = time.Now().Unix()
+		d.tags[tag.TagUid] = *tag
+	})
+	return nil
+}
+
+func (r *memoryTagRepository) GetByUID(userUid, tagUid string, onlyUndeleted bool) (*model.TagModel, error) {
+	var tag model.TagModel
+	var ok bool
+	r.s.with(func(d *memoryData) {
+		tag, ok = d.tags[tagUid]
+	})
+	if !ok || tag.UserUid != userUid || (onlyUndeleted && tag.IsDeleted != 0) {
+		return nil, ErrNotFound
+	}
+	return &tag, nil
+}
+
+func (r *memoryTagRepository) Update(tag *model.TagModel) error {
+	r.s.with(func(d *memoryData) {
+		tag.UpdatedAt = time.Now().Unix()
+		d.tags[tag.TagUid] = *tag
+	})
+	return nil
+}
+
+// filter 按条件筛选未删除标签，按创建顺序排列
+func (r *memoryTagRepository) filter(userUid string, match func(tag *model.TagModel) bool) []model.TagModel {
+	var tags []model.TagModel
+	r.s.with(func(d *memoryData) {
+		for _, tag := range d.tags {
+			if tag.UserUid == userUid && tag.IsDeleted == 0 && match(&tag) {
+				tags = append(tags, tag)
+			}
+		}
+	})
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].CreatedAt != tags[j].CreatedAt {
+			return tags[i].CreatedAt < tags[j].CreatedAt
+		}
+		return tags[i].ID < tags[j].ID
+	})
+	return tags
+}
+
+func (r *memoryTagRepository) ListByMove(userUid, moveUid string, page, pageSize int) ([]model.TagModel, int64, error) {
+	tags := r.filter(userUid, func(tag *model.TagModel) bool { return tag.MoveUid == moveUid })
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].IsVerified < tags[j].IsVerified })
+	return paginate(tags, page, pageSize), int64(len(tags)), nil
+}
+
+func (r *memoryTagRepository) GetChildren(userUid, parentTagUid string) ([]model.TagModel, error) {
+	return r.filter(userUid, func(tag *model.TagModel) bool { return tag.ParentTagUid == parentTagUid }), nil
+}
+
+func (r *memoryTagRepository) MapByUIDs(userUid string, tagUids []string) (map[string]model.TagModel, error) {
+	wanted := make(map[string]bool, len(tagUids))
+	for _, uid := range tagUids {
+		wanted[uid] = true
+	}
+	tagMap := make(map[string]model.TagModel)
+	for _, tag := range r.filter(userUid, func(tag *model.TagModel) bool { return wanted[tag.TagUid] }) {
+		tagMap[tag.TagUid] = tag
+	}
+	return tagMap, nil
+}
+
+func (r *memoryTagRepository) CountChildren(userUid string, parentTagUids []string) (map[string]int, error) {
+	wanted := make(map[string]bool, len(parentTagUids))
+	for _, uid := range parentTagUids {
+		wanted[uid] = true
+	}
+	counts := make(map[string]int)
+	for _, tag := range r.filter(userUid, func(tag *model.TagModel) bool { return wanted[tag.ParentTagUid] }) {
+		counts[tag.ParentTagUid]++
+	}
+	return counts, nil
+}
+
+func (r *memoryTagRepository) ListAllByMove(userUid, moveUid string) ([]model.TagModel, error) {
+	return r.filter(userUid, func(tag *model.TagModel) bool { return tag.MoveUid == moveUid }), nil
+}
+
+// memoryRoomRepository 房间仓储的内存实现
+type memoryRoomRepository struct {
+	s *MemoryStore
+}
+
+func (r *memoryRoomRepository) Create(room *model.RoomModel) error {
+	r.s.with(func(d *memoryData) {
+		if room.RoomUid == "" {
+			room.RoomUid = uuid.New().String()
+		}
+		d.nextID++
+		room.ID = d.nextID
+		room.CreatedAt = time.Now().Unix()
+		d.rooms[room.RoomUid] = *room
+	})
+	return nil
+}
+
+func (r *memoryRoomRepository) GetByUID(userUid, roomUid string, onlyUndeleted bool) (*model.RoomModel, error) {
+	var room model.RoomModel
+	var ok bool
+	r.s.with(func(d *memoryData) {
+		room, ok = d.rooms[roomUid]
+	})
+	if !ok || room.UserUid != userUid || (onlyUndeleted && room.IsDeleted != 0) {
+		return nil, ErrNotFound
+	}
+	return &room, nil
+}
+
+func (r *memoryRoomRepository) Update(room *model.RoomModel) error {
+	r.s.with(func(d *memoryData) {
+		room.UpdatedAt = time.Now().Unix()
+		d.rooms[room.RoomUid] = *room
+	})
+	return nil
+}
+
+func (r *memoryRoomRepository) ListByMove(userUid, moveUid string, side int) ([]model.RoomModel, error) {
+	var rooms []model.RoomModel
+	r.s.with(func(d *memoryData) {
+		for _, room := range d.rooms {
+			if room.UserUid == userUid && room.MoveUid == moveUid && room.IsDeleted == 0 && (side == 0 || room.Side == side) {
+				rooms = append(rooms, room)
+			}
+		}
+	})
+	sort.Slice(rooms, func(i, j int) bool {
+		if rooms[i].Side != rooms[j].Side {
+			return rooms[i].Side < rooms[j].Side
+		}
+		if rooms[i].Sort != rooms[j].Sort {
+			return rooms[i].Sort < rooms[j].Sort
+		}
+		if rooms[i].CreatedAt != rooms[j].CreatedAt {
+			return rooms[i].CreatedAt < rooms[j].CreatedAt
+		}
+		return rooms[i].ID < rooms[j].ID
+	})
+	return rooms, nil
+}
+
+func (r *memoryRoomRepository) MapByMove(userUid, moveUid string) (map[string]model.RoomModel, error) {
+	rooms, _ := r.ListByMove(userUid, moveUid, 0)
+	roomMap := make(map[string]model.RoomModel, len(rooms))
+	for _, room := range rooms {
+		roomMap[room.RoomUid] = room
+	}
+	return roomMap, nil
+}
+
+// memoryItemRepository 物品仓储的内存实现
+type memoryItemRepository struct {
+	s *MemoryStore
+}
+
+func (r *memoryItemRepository) Create(item *model.ItemModel) error {
+	r.s.with(func(d *memoryData) {
+		if item.ItemUid == "" {
+			item.ItemUid = uuid.New().String()
+		}
+		d.nextID++
+		item.ID = d.nextID
+		item.CreatedAt = time.Now().Unix()
+		d.items[item.ItemUid] = *item
+	})
+	return nil
+}
+
+func (r *memoryItemRepository) GetByUID(userUid, itemUid string, onlyUndeleted bool) (*model.ItemModel, error) {
+	var item model.ItemModel
+	var ok bool
+	r.s.with(func(d *memoryData) {
+		item, ok = d.items[itemUid]
+	})
+	if !ok || item.UserUid != userUid || (onlyUndeleted && item.IsDeleted != 0) {
+		return nil, ErrNotFound
+	}
+	return &item, nil
+}
+
+func (r *memoryItemRepository) Update(item *model.ItemModel) error {
+	r.s.with(func(d *memoryData) {
+		item.UpdatedAt = time.Now().Unix()
+		d.items[item.ItemUid] = *item
+	})
+	return nil
+}
+
+func (r *memoryItemRepository) ListByTag(userUid, tagUid string) ([]model.ItemModel, error) {
+	var items []model.ItemModel
+	r.s.with(func(d *memoryData) {
+		for _, item := range d.items {
+			if item.UserUid == userUid && item.TagUid == tagUid && item.IsDeleted == 0 {
+				items = append(items, item)
+			}
+		}
+	})
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].CreatedAt != items[j].CreatedAt {
+			return items[i].CreatedAt < items[j].CreatedAt
+		}
+		return items[i].ID < items[j].ID
+	})
+	return items, nil
+}
+
+// memoryUserRepository 用户仓储的内存实现
+type memoryUserRepository struct {
+	s *MemoryStore
+}
+
+func (r *memoryUserRepository) Create(user *model.UserModel) error {
+	r.s.with(func(d *memoryData) {
+		if user.UserUid == "" {
+			user.UserUid = uuid.New().String()
+		}
+		d.nextID++
+		user.ID = d.nextID
+		user.CreatedAt = time.Now().Unix()
+		d.users[user.UserUid] = *user
+	})
+	return nil
+}
+
+// find 查找第一个满足条件的有效用户
+func (r *memoryUserRepository) find(match func(user *model.UserModel) bool) (*model.UserModel, error) {
+	var found *model.UserModel
+	r.s.with(func(d *memoryData) {
+		for _, user := range d.users {
+			if user.IsDeleted == 0 && match(&user) {
+				u := user
+				found = &u
+				return
+			}
+		}
+	})
+	if found == nil {
+		return nil, ErrNotFound
+	}
+	return found, nil
+}
+
+func (r *memoryUserRepository) GetByMobile(mobile string) (*model.UserModel, error) {
+	return r.find(func(user *model.UserModel) bool { return user.Mobile == mobile })
+}
+
+func (r *memoryUserRepository) GetByAuthCode(authCode string) (*model.UserModel, error) {
+	return r.find(func(user *model.UserModel) bool { return user.AuthCode == authCode })
+}
+
+func (r *memoryUserRepository) UpdateAuthCode(user *model.UserModel, authCode string) error {
+	var err error
+	r.s.with(func(d *memoryData) {
+		current, ok := d.users[user.UserUid]
+		if !ok {
+			err = ErrNotFound
+			return
+		}
+		current.AuthCode = authCode
+		d.users[user.UserUid] = current
+		user.AuthCode = authCode
+	})
+	return err
+}
+
+// memoryWebhookRepository Webhook仓储的内存实现
+type memoryWebhookRepository struct {
+	s *MemoryStore
+}
+
+func (r *memoryWebhookRepository) Create(webhook *model.WebhookModel) error {
+	r.s.with(func(d *memoryData) {
+		if webhook.WebhookUid == "" {
+			webhook.WebhookUid = uuid.New().String()
+		}
+		d.nextID++
+		webhook.ID = d.nextID
+		webhook.CreatedAt = time.Now().Unix()
+		d.webhooks[webhook.WebhookUid] = *webhook
+	})
+	return nil
+}
+
+func (r *memoryWebhookRepository) ListEnabledByUser(userUid string) ([]model.WebhookModel, error) {
+	var webhooks []model.WebhookModel
+	r.s.with(func(d *memoryData) {
+		for _, webhook := range d.webhooks {
+			if webhook.UserUid == userUid && webhook.IsEnabled == 1 && webhook.IsDeleted == 0 {
+				webhooks = append(webhooks, webhook)
+			}
+		}
+	})
+	sort.Slice(webhooks, func(i, j int) bool { return webhooks[i].ID < webhooks[j].ID })
+	return webhooks, nil
+}
+
+func (r *memoryWebhookRepository) CreateDelivery(delivery *model.WebhookDeliveryModel) error {
+	r.s.with(func(d *memoryData) {
+		if delivery.DeliveryUid == "" {
+			delivery.DeliveryUid = uuid.New().String()
+		}
+		d.nextID++
+		delivery.ID = d.nextID
+		delivery.CreatedAt = time.Now().Unix()
+		d.deliveries = append(d.deliveries, *delivery)
+	})
+	return nil
+}
+
+func (r *memoryWebhookRepository) ListDeliveries(userUid, webhookUid string) ([]model.WebhookDeliveryModel, error) {
+	var deliveries []model.WebhookDeliveryModel
+	r.s.with(func(d *memoryData) {
+		for _, delivery := range d.deliveries {
+			if delivery.UserUid == userUid && delivery.WebhookUid == webhookUid {
+				deliveries = append(deliveries, delivery)
+			}
+		}
+	})
+	return deliveries, nil
+}
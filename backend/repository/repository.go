@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"errors"
+
+	"movingManager/model"
+)
+
+// ErrNotFound 记录不存在
+// 各实现查询单条记录不存在时统一返回该错误，服务层使用 err == repository.ErrNotFound 判断
+var ErrNotFound = errors.New("记录不存在")
+
+// MoveRepository 搬运记录仓储
+type MoveRepository interface {
+	// Create 创建搬运记录，生成搬运UID
+	Create(move *model.MoveModel) error
+	// GetByUID 查询用户的搬运记录，onlyUndeleted 控制是否只查询未删除记录
+	GetByUID(userUid, moveUid string, onlyUndeleted bool) (*model.MoveModel, error)
+	// Update 保存搬运记录的全部字段
+	Update(move *model.MoveModel) error
+	// ListByUser 分页查询用户的搬运列表，未完成的在前，再按搬运时间正序
+	ListByUser(userUid string, page, pageSize int, onlyUndeleted bool) ([]model.MoveModel, int64, error)
+	// AdjustTagCounts 按增量调整搬运的标签统计，计数不小于0
+	// 未核销数增加时搬运变为未完成；未核销数减少到0且仍有标签时搬运变为已完成
+	AdjustTagCounts(move *model.MoveModel, total, verified, unverified int) error
+}
+
+// TagRepository 标签仓储
+type TagRepository interface {
+	// Create 创建标签，生成标签UID
+	Create(tag *model.TagModel) error
+	// GetByUID 查询用户的标签，onlyUndeleted 控制是否只查询未删除记录
+	GetByUID(userUid, tagUid string, onlyUndeleted bool) (*model.TagModel, error)
+	// Update 保存标签的全部字段
+	Update(tag *model.TagModel) error
+	// ListByMove 分页查询搬运下的未删除标签，未核销的在前，再按创建时间正序
+	ListByMove(userUid, moveUid string, page, pageSize int) ([]model.TagModel, int64, error)
+	// GetChildren 查询直接放在该标签内的未删除子标签，按创建时间正序
+	GetChildren(userUid, parentTagUid string) ([]model.TagModel, error)
+	// MapByUIDs 根据标签UID批量查询未删除标签，按标签UID索引
+	MapByUIDs(userUid string, tagUids []string) (map[string]model.TagModel, error)
+	// CountChildren 统计多个标签各自的未删除子标签数量
+	CountChildren(userUid string, parentTagUids []string) (map[string]int, error)
+	// ListAllByMove 查询搬运下的全部未删除标签，按创建时间正序
+	ListAllByMove(userUid, moveUid string) ([]model.TagModel, error)
+}
+
+// RoomRepository 房间仓储
+type RoomRepository interface {
+	// Create 创建房间，生成房间UID
+	Create(room *model.RoomModel) error
+	// GetByUID 查询用户的房间，onlyUndeleted 控制是否只查询未删除记录
+	GetByUID(userUid, roomUid string, onlyUndeleted bool) (*model.RoomModel, error)
+	// Update 保存房间的全部字段
+	Update(room *model.RoomModel) error
+	// ListByMove 查询搬运下的未删除房间，side 为0时返回出发地和目的地全部房间
+	// 按位置、排序值、创建时间正序
+	ListByMove(userUid, moveUid string, side int) ([]model.RoomModel, error)
+	// MapByMove 查询搬运下的未删除房间，按房间UID索引
+	MapByMove(userUid, moveUid string) (map[string]model.RoomModel, error)
+}
+
+// ItemRepository 物品仓储
+type ItemRepository interface {
+	// Create 创建物品，生成物品UID
+	Create(item *model.ItemModel) error
+	// GetByUID 查询用户的物品，onlyUndeleted 控制是否只查询未删除记录
+	GetByUID(userUid, itemUid string, onlyUndeleted bool) (*model.ItemModel, error)
+	// Update 保存物品的全部字段
+	Update(item *model.ItemModel) error
+	// ListByTag 查询标签下的未删除物品，按创建时间正序
+	ListByTag(userUid, tagUid string) ([]model.ItemModel, error)
+}
+
+// UserRepository 用户仓储
+type UserRepository interface {
+	// Create 创建用户，生成用户UID
+	Create(user *model.UserModel) error
+	// GetByMobile 根据手机号查询有效用户
+	GetByMobile(mobile string) (*model.UserModel, error)
+	// GetByAuthCode 根据登录授权码查询有效用户
+	GetByAuthCode(authCode string) (*model.UserModel, error)
+	// UpdateAuthCode 更新用户的登录授权码
+	UpdateAuthCode(user *model.UserModel, authCode string) error
+}
+
+// WebhookRepository Webhook仓储
+type WebhookRepository interface {
+	// Create 创建Webhook，生成WebhookUID
+	Create(webhook *model.WebhookModel) error
+	// ListEnabledByUser 查询用户已启用的未删除Webhook
+	ListEnabledByUser(userUid string) ([]model.WebhookModel, error)
+	// CreateDelivery 写入待投递记录，生成投递UID
+	// 在业务事务中调用，事务回滚时投递记录一并撤销
+	CreateDelivery(delivery *model.WebhookDeliveryModel) error
+	// ListDeliveries 查询Webhook的全部投递记录，按写入顺序排列
+	ListDeliveries(userUid, webhookUid string) ([]model.WebhookDeliveryModel, error)
+}
+
+// Store 仓储集合
+type Store interface {
+	Moves() MoveRepository
+	Tags() TagRepository
+	Rooms() RoomRepository
+	Items() ItemRepository
+	Users() UserRepository
+	Webhooks() WebhookRepository
+	// Transaction 在事务中执行fn，fn返回错误时回滚全部修改
+	// fn 中需使用传入的Store访问数据
+	Transaction(fn func(Store) error) error
+}
@@ -2,44 +2,144 @@ package router
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"movingManager/controller"
 	"movingManager/middleware"
+	"movingManager/openapi"
+	"movingManager/ratelimit"
 )
 
 // RegisterRoutes 注册所有路由
+// v1保持原有响应格式；v2使用统一响应信封，出错时返回对应的HTTP状态码
 func RegisterRoutes(r *gin.Engine) {
+	registerAPI(r.Group("/api/v1", middleware.APIVersion(1)))
+	registerAPI(r.Group("/api/v2", middleware.APIVersion(2)))
+
+	// 接口文档，路由变更时需同步修改 openapi.Routes
+	r.GET("/api/openapi.json", openapi.Spec)
+	r.GET("/api/docs", openapi.Docs)
+}
+
+// RegisterProbeRoutes 注册健康检查和监控指标路由，探测请求不限流
+func RegisterProbeRoutes(r *gin.Engine) {
+	r.GET("/healthz", controller.Healthz)            // 存活检查
+	r.GET("/readyz", controller.Readyz)              // 就绪检查
+	r.GET("/metrics", gin.WrapH(promhttp.Handler())) // Prometheus指标
+}
+
+// registerAPI 在指定版本的路由组下注册接口
+func registerAPI(root *gin.RouterGroup) {
 	// 公开路由组(无需认证)
-	public := r.Group("/api/v1")
+	public := root.Group("")
+	public.Use(middleware.RateLimit(ratelimit.RulePublic, middleware.ClientIPKey))
 	{
-		// 用户注册/登录
-		public.POST("/user/auth", controller.UserAuth)
+		// 用户注册/登录(同时按IP和手机号限流)
+		public.POST("/user/auth",
+			middleware.RateLimit(ratelimit.RuleAuthIP, middleware.ClientIPKey),
+			middleware.RateLimit(ratelimit.RuleAuthMobile, middleware.AuthMobileKey),
+			controller.UserAuth)
+
+		// 免登录扫码(凭签名令牌访问)
+		public.POST("/scan/detail", controller.GetScanDetail) // 扫码查看标签
+		public.POST("/scan/verify", controller.ScanVerifyTag) // 扫码核销标签
 	}
 
 	// 需要认证的路由组
-	api := r.Group("/api/v1")
-	api.Use(middleware.AuthMiddleware()) // 应用认证中间件
+	api := root.Group("")
+	api.Use(middleware.RateLimit(ratelimit.RuleAPIIP, middleware.ClientIPKey)) // 认证前按IP限流，限制无效令牌反复查询数据库
+	api.Use(middleware.AuthMiddleware())                                       // 应用认证中间件
+	api.Use(middleware.RateLimit(ratelimit.RuleUser, middleware.UserKey))      // 按用户限流，同一网络下的多个用户互不影响
 	{
 		// 搬运模块
 		move := api.Group("/move")
 		{
-			move.POST("/create", controller.CreateMove)       // 创建搬运
-			move.POST("/detail", controller.GetMoveDetail)    // 搬运详情
-			move.POST("/update", controller.UpdateMove)       // 编辑搬运
-			move.POST("/delete", controller.DeleteMove)       // 删除搬运
-			move.POST("/list", controller.GetMoveList)        // 搬运列表
+			move.POST("/create", controller.CreateMove)                        // 创建搬运
+			move.POST("/detail", controller.GetMoveDetail)                     // 搬运详情
+			move.POST("/update", controller.UpdateMove)                        // 编辑搬运
+			move.POST("/delete", controller.DeleteMove)                        // 删除搬运
+			move.POST("/list", controller.GetMoveList)                         // 搬运列表
+			move.POST("/loss-report", controller.GetLossReport)                // 丢失损坏报告
+			move.GET("/events", controller.GetMoveEvents)                      // 实时事件推送(SSE)
+			move.POST("/export", controller.ExportMove)                        // 导出搬运清单(CSV/XLSX/JSON)
+			move.POST("/attachment/upload", controller.UploadMoveAttachment)   // 上传搬运照片
+			move.POST("/attachment/list", controller.GetMoveAttachmentList)    // 搬运照片列表
+			move.POST("/attachment/delete", controller.DeleteMoveAttachment)   // 删除搬运照片
+			move.POST("/handover/create", controller.CreateHandover)           // 登记交接签收
+			move.POST("/handover/list", controller.GetHandoverList)            // 交接记录列表
+			move.POST("/handover/receipt", controller.GenerateHandoverReceipt) // 生成送达回执PDF
 		}
 
 		// 标签模块
-		 tag := api.Group("/tag")
+		tag := api.Group("/tag")
+		{
+			tag.POST("/create", controller.CreateTag)                         // 创建标签
+			tag.POST("/update", controller.UpdateTag)                         // 编辑标签
+			tag.POST("/delete", controller.DeleteTag)                         // 删除标签
+			tag.POST("/verify", controller.VerifyTag)                         // 核销标签
+			tag.POST("/sync", controller.SyncTags)                            // 离线扫码批量同步
+			tag.POST("/import", controller.ImportTags)                        // 从CSV/XLSX导入标签
+			tag.POST("/detail", controller.GetTagDetail)                      // 标签详情
+			tag.POST("/list", controller.GetTagList)                          // 标签列表
+			tag.POST("/list-by-room", controller.GetTagListByRoom)            // 按房间分组的标签列表
+			tag.POST("/generate-pdf", controller.GeneratePDF)                 // 生成PDF
+			tag.POST("/manifest-pdf", controller.GenerateManifestPDF)         // 生成A4装箱清单PDF
+			tag.POST("/label", controller.RenderTagLabel)                     // 生成单个标签图片(PNG/SVG)
+			tag.POST("/scan-link", controller.GetTagScanLink)                 // 获取标签扫码链接
+			tag.POST("/insurance-report", controller.GenerateInsuranceReport) // 生成保险申报清单(PDF/CSV)
+			tag.POST("/attachment/upload", controller.UploadTagAttachment)    // 上传标签照片
+			tag.POST("/attachment/list", controller.GetTagAttachmentList)     // 标签照片列表
+			tag.POST("/attachment/delete", controller.DeleteTagAttachment)    // 删除标签照片
+		}
+
+		// 房间模块
+		room := api.Group("/room")
+		{
+			room.POST("/create", controller.CreateRoom) // 创建房间
+			room.POST("/update", controller.UpdateRoom) // 编辑房间
+			room.POST("/delete", controller.DeleteRoom) // 删除房间
+			room.POST("/list", controller.GetRoomList)  // 房间列表
+		}
+
+		// 物品模块
+		item := api.Group("/item")
+		{
+			item.POST("/create", controller.CreateItem) // 创建物品
+			item.POST("/update", controller.UpdateItem) // 编辑物品
+			item.POST("/delete", controller.DeleteItem) // 删除物品
+			item.POST("/list", controller.GetItemList)  // 物品列表
+		}
+
+		// 丢失/损坏问题模块
+		issue := api.Group("/issue")
+		{
+			issue.POST("/create", controller.CreateIssue) // 登记问题
+			issue.POST("/update", controller.UpdateIssue) // 更新处理进度
+			issue.POST("/delete", controller.DeleteIssue) // 删除问题
+			issue.POST("/list", controller.GetIssueList)  // 问题列表
+		}
+
+		// Webhook模块
+		webhook := api.Group("/webhook")
+		{
+			webhook.POST("/create", controller.CreateWebhook)            // 创建Webhook
+			webhook.POST("/update", controller.UpdateWebhook)            // 编辑Webhook
+			webhook.POST("/delete", controller.DeleteWebhook)            // 删除Webhook
+			webhook.POST("/list", controller.GetWebhookList)             // Webhook列表
+			webhook.POST("/deliveries", controller.GetWebhookDeliveries) // 投递记录
+		}
+
+		// 账户模块
+		account := api.Group("/account")
+		{
+			account.POST("/backup", controller.BackupAccount)   // 导出账户备份
+			account.POST("/restore", controller.RestoreAccount) // 从备份恢复账户数据
+		}
+
+		// 附件模块
+		attachment := api.Group("/attachment")
 		{
-			tag.POST("/create", controller.CreateTag)         // 创建标签
-			tag.POST("/update", controller.UpdateTag)         // 编辑标签
-			tag.POST("/delete", controller.DeleteTag)         // 删除标签
-			tag.POST("/verify", controller.VerifyTag)         // 核销标签
-			tag.POST("/detail", controller.GetTagDetail)      // 标签详情
-			tag.POST("/list", controller.GetTagList)          // 标签列表
-			tag.POST("/generate-pdf", controller.GeneratePDF) // 生成PDF
+			attachment.POST("/download", controller.DownloadAttachment) // 下载附件原图或缩略图
 		}
 	}
-}
\ No newline at end of file
+}
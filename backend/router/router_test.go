@@ -0,0 +1,112 @@
+package router
+
+import (
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"movingManager/openapi"
+)
+
+// TestRoutesMatchOpenAPI 校验注册的路由与接口文档一致
+// 新增、删除路由或更换处理函数后未同步修改 openapi.Routes 时失败
+func TestRoutesMatchOpenAPI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	RegisterRoutes(r)
+
+	// 文档中的接口: 方法+路径 -> 处理函数名
+	documented := map[string]string{}
+	for _, route := range openapi.Routes() {
+		key := route.Method + " " + route.Path
+		if _, ok := documented[key]; ok {
+			t.Errorf("接口文档重复登记 %s", key)
+		}
+		documented[key] = runtime.FuncForPC(reflect.ValueOf(route.Handler).Pointer()).Name()
+	}
+
+	for _, prefix := range []string{"/api/v1", "/api/v2"} {
+		registered := map[string]string{}
+		for _, info := range r.Routes() {
+			if strings.HasPrefix(info.Path, prefix+"/") {
+				registered[info.Method+" "+strings.TrimPrefix(info.Path, prefix)] = info.Handler
+			}
+		}
+
+		for _, key := range sortedKeys(registered) {
+			handler, ok := documented[key]
+			if !ok {
+				t.Errorf("%s 下的 %s 未登记到接口文档", prefix, key)
+				continue
+			}
+			if handler != registered[key] {
+				t.Errorf("%s 下的 %s 处理函数为 %s，接口文档登记为 %s", prefix, key, registered[key], handler)
+			}
+		}
+		for _, key := range sortedKeys(documented) {
+			if _, ok := registered[key]; !ok {
+				t.Errorf("接口文档中的 %s 未在 %s 下注册", key, prefix)
+			}
+		}
+	}
+}
+
+// TestOpenAPIDocument 校验生成的文档引用的数据结构均已登记
+func TestOpenAPIDocument(t *testing.T) {
+	doc := openapi.Build()
+	if len(doc.Paths) == 0 {
+		t.Fatal("接口文档没有任何路径")
+	}
+
+	var refs []string
+	var walk func(s *openapi.Schema)
+	walk = func(s *openapi.Schema) {
+		if s == nil {
+			return
+		}
+		if s.Ref != "" {
+			refs = append(refs, strings.TrimPrefix(s.Ref, "#/components/schemas/"))
+		}
+		walk(s.Items)
+		walk(s.AdditionalProperties)
+		for _, p := range s.Properties {
+			walk(p)
+		}
+	}
+	for _, item := range doc.Paths {
+		for _, op := range item {
+			if op.RequestBody != nil {
+				for _, m := range op.RequestBody.Content {
+					walk(m.Schema)
+				}
+			}
+			for _, resp := range op.Responses {
+				for _, m := range resp.Content {
+					walk(m.Schema)
+				}
+			}
+		}
+	}
+	for _, s := range doc.Components.Schemas {
+		walk(s)
+	}
+
+	for _, name := range refs {
+		if _, ok := doc.Components.Schemas[name]; !ok {
+			t.Errorf("引用的数据结构 %s 未登记", name)
+		}
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
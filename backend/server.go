@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"movingManager/config"
+)
+
+// 服务配置未填写时的默认值
+const (
+	defaultServerAddr      = "0.0.0.0:8080"
+	defaultReadTimeout     = 60 * time.Second
+	defaultWriteTimeout    = 120 * time.Second
+	defaultIdleTimeout     = 120 * time.Second
+	defaultShutdownTimeout = 30 * time.Second
+	readHeaderTimeout      = 10 * time.Second
+)
+
+// newHTTPServer 根据配置创建HTTP服务
+func newHTTPServer(handler http.Handler, cfg config.ServerConfig) *http.Server {
+	addr := cfg.Addr
+	if addr == "" {
+		addr = defaultServerAddr
+	}
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       secondsOr(cfg.ReadTimeoutSeconds, defaultReadTimeout),
+		WriteTimeout:      secondsOr(cfg.WriteTimeoutSeconds, defaultWriteTimeout),
+		IdleTimeout:       secondsOr(cfg.IdleTimeoutSeconds, defaultIdleTimeout),
+	}
+}
+
+// runHTTPServer 启动HTTP服务，ctx 取消后停止接收新连接并等待进行中的请求完成
+// 超过退出等待时间仍未完成的连接将被强制关闭
+func runHTTPServer(ctx context.Context, srv *http.Server, cfg config.ServerConfig) error {
+	errCh := make(chan error, 1)
+	go func() {
+		var err error
+		if cfg.TLSCertFile != "" && cfg.TLSKeyFile != "" {
+			slog.Info("服务器启动成功", "addr", srv.Addr, "tls", true)
+			err = srv.ListenAndServeTLS(cfg.TLSCertFile, cfg.TLSKeyFile)
+		} else {
+			slog.Info("服务器启动成功", "addr", srv.Addr, "tls", false)
+			err = srv.ListenAndServe()
+		}
+		errCh <- err
+	}()
+
+	select {
+	case err := <-errCh:
+		// 启动失败(如端口被占用)
+		if !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	case <-ctx.Done():
+	}
+
+	slog.Info("收到退出信号，等待进行中的请求完成")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), secondsOr(cfg.ShutdownTimeoutSeconds, defaultShutdownTimeout))
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		srv.Close()
+		return err
+	}
+	return nil
+}
+
+// secondsOr 将配置的秒数转换为时长，未配置时使用默认值
+func secondsOr(seconds int, fallback time.Duration) time.Duration {
+	if seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return fallback
+}
@@ -0,0 +1,348 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	_ "image/png" // 注册PNG解码器
+	"io"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // 注册WebP解码器
+	"gorm.io/gorm"
+
+	"movingManager/config"
+	"movingManager/database"
+	"movingManager/model"
+	"movingManager/storage"
+)
+
+// 附件默认限制（配置缺省时使用）
+const (
+	defaultUserQuotaMB   = 500 // 每个用户的存储配额(MB)
+	defaultMaxFileSizeMB = 10  // 单个文件大小上限(MB)
+	defaultThumbMaxSize  = 320 // 缩略图最长边(像素)
+
+	maxImagePixels = 40_000_000 // 图片像素数上限，解码前校验，避免小文件解码出超大图片耗尽内存
+)
+
+// allowedImageTypes 允许上传的图片类型及对应扩展名
+var allowedImageTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+// AttachmentResponse 附件响应结构
+type AttachmentResponse struct {
+	AttachmentUid string `json:"attachment_uid"`
+	MoveUid       string `json:"move_uid"`
+	TagUid        string `json:"tag_uid"`
+	OwnerType     string `json:"owner_type"` // 归属类型(move,tag)
+	FileName      string `json:"file_name"`
+	ContentType   string `json:"content_type"`
+	Size          int64  `json:"size"`
+	Width         int    `json:"width"`
+	Height        int    `json:"height"`
+	CreatedAt     string `json:"created_at"`
+}
+
+// StorageUsage 用户存储空间使用情况
+type StorageUsage struct {
+	UsedBytes  int64 `json:"used_bytes"`
+	QuotaBytes int64 `json:"quota_bytes"`
+}
+
+// UploadAttachmentRequest 上传附件请求参数
+// TagUid 不为空时上传到标签，否则上传到搬运
+type UploadAttachmentRequest struct {
+	MoveUid  string    // 搬运UID
+	TagUid   string    // 标签UID
+	FileName string    // 原始文件名
+	Reader   io.Reader // 文件内容
+}
+
+// UploadAttachment 上传照片附件业务处理
+// 校验图片格式、大小和用户配额，生成缩略图后写入存储
+func UploadAttachment(userUid string, req UploadAttachmentRequest) (*AttachmentResponse, error) {
+	// 验证归属的搬运或标签
+	attachment := model.AttachmentModel{
+		UserUid:   userUid,
+		FileName:  path.Base(req.FileName),
+		OwnerType: model.AttachmentOwnerMove,
+	}
+	if req.TagUid != "" {
+		var tag model.TagModel
+		if err := tag.GetByUID(userUid, req.TagUid, true); err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil, ErrTagNotFound
+			}
+			return nil, fmt.Errorf("查询标签记录失败: %v", err)
+		}
+		attachment.MoveUid = tag.MoveUid
+		attachment.TagUid = tag.TagUid
+		attachment.OwnerType = model.AttachmentOwnerTag
+	} else {
+		var move model.MoveModel
+		if err := move.GetByUID(userUid, req.MoveUid, true); err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil, ErrMoveNotFound
+			}
+			return nil, fmt.Errorf("查询搬运记录失败: %v", err)
+		}
+		attachment.MoveUid = move.MoveUid
+	}
+
+	// 读取文件内容（多读1字节用于判断是否超限）
+	maxSize := maxFileSizeBytes()
+	data, err := io.ReadAll(io.LimitReader(req.Reader, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("读取文件失败: %v", err)
+	}
+	if int64(len(data)) > maxSize {
+		return nil, validationError("文件大小不能超过%dMB", maxSize>>20)
+	}
+
+	// 校验图片格式
+	contentType := http.DetectContentType(data)
+	ext, ok := allowedImageTypes[contentType]
+	if !ok {
+		return nil, validationError("仅支持JPEG、PNG、WebP格式的图片")
+	}
+	imgConfig, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, validationError("图片文件已损坏")
+	}
+	if int64(imgConfig.Width)*int64(imgConfig.Height) > maxImagePixels {
+		return nil, validationError("图片尺寸不能超过%d万像素", maxImagePixels/10000)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("图片解析失败: %v", err)
+	}
+
+	// 生成缩略图
+	thumb, err := generateThumbnail(img, thumbMaxSize())
+	if err != nil {
+		return nil, fmt.Errorf("生成缩略图失败: %v", err)
+	}
+
+	// 写入存储
+	fileKey := fmt.Sprintf("%s/%s", userUid, uuid.New().String())
+	attachment.ContentType = contentType
+	attachment.Size = int64(len(data))
+	attachment.ThumbSize = int64(len(thumb))
+	attachment.Width = img.Bounds().Dx()
+	attachment.Height = img.Bounds().Dy()
+	attachment.StorageKey = fileKey + ext
+	attachment.ThumbKey = fileKey + "_thumb.jpg"
+
+	if _, err := storage.Default.Save(attachment.StorageKey, bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("保存文件失败: %v", err)
+	}
+	if _, err := storage.Default.Save(attachment.ThumbKey, bytes.NewReader(thumb)); err != nil {
+		storage.Default.Delete(attachment.StorageKey)
+		return nil, fmt.Errorf("保存缩略图失败: %v", err)
+	}
+
+	// 锁定用户行后校验配额并写入记录，同一用户的并发上传不会同时通过配额校验
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var user model.UserModel
+		if err := user.LockTx(tx, userUid); err != nil {
+			return fmt.Errorf("锁定用户记录失败: %v", err)
+		}
+		usage, err := attachment.SumUsageByUserTx(tx, userUid)
+		if err != nil {
+			return fmt.Errorf("查询存储用量失败: %v", err)
+		}
+		if usage+attachment.Size+attachment.ThumbSize > userQuotaBytes() {
+			return ErrStorageQuotaExceeded
+		}
+		if err := attachment.CreateTx(tx); err != nil {
+			return fmt.Errorf("创建附件记录失败: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		// 超出配额或记录写入失败时清理已保存的文件
+		storage.Default.Delete(attachment.StorageKey)
+		storage.Default.Delete(attachment.ThumbKey)
+		return nil, err
+	}
+
+	return convertAttachmentToResponse(&attachment), nil
+}
+
+// GetAttachmentList 获取搬运或标签的附件列表业务处理
+// tagUid 不为空时返回标签附件，否则返回搬运附件
+func GetAttachmentList(userUid, moveUid, tagUid string) ([]AttachmentResponse, error) {
+	if tagUid != "" {
+		var tag model.TagModel
+		if err := tag.GetByUID(userUid, tagUid, true); err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil, ErrTagNotFound
+			}
+			return nil, fmt.Errorf("查询标签记录失败: %v", err)
+		}
+		moveUid = tag.MoveUid
+	} else {
+		var move model.MoveModel
+		if err := move.GetByUID(userUid, moveUid, true); err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil, ErrMoveNotFound
+			}
+			return nil, fmt.Errorf("查询搬运记录失败: %v", err)
+		}
+	}
+
+	var attachmentModel model.AttachmentModel
+	attachments, err := attachmentModel.ListByOwner(userUid, moveUid, tagUid)
+	if err != nil {
+		return nil, fmt.Errorf("查询附件列表失败: %v", err)
+	}
+
+	responses := make([]AttachmentResponse, 0, len(attachments))
+	for _, attachment := range attachments {
+		responses = append(responses, *convertAttachmentToResponse(&attachment))
+	}
+	return responses, nil
+}
+
+// DeleteAttachment 删除附件业务处理
+// ownerType 用于校验接口与附件归属一致，删除后立即释放存储空间
+func DeleteAttachment(userUid, attachmentUid, ownerType string) error {
+	var attachment model.AttachmentModel
+	if err := attachment.GetByUID(userUid, attachmentUid); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return ErrAttachmentNotFound
+		}
+		return fmt.Errorf("查询附件记录失败: %v", err)
+	}
+	if attachment.OwnerType != ownerType {
+		return ErrAttachmentNotFound
+	}
+
+	if err := attachment.MarkDeleted(); err != nil {
+		return fmt.Errorf("删除附件失败: %v", err)
+	}
+
+	// 文件删除失败不影响结果，记录已标记删除且不再计入配额
+	storage.Default.Delete(attachment.StorageKey)
+	storage.Default.Delete(attachment.ThumbKey)
+	return nil
+}
+
+// OpenAttachment 打开附件文件内容业务处理
+// thumb 为true时返回缩略图，调用方负责关闭返回的读取器
+func OpenAttachment(userUid, attachmentUid string, thumb bool) (io.ReadCloser, *AttachmentResponse, error) {
+	var attachment model.AttachmentModel
+	if err := attachment.GetByUID(userUid, attachmentUid); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil, ErrAttachmentNotFound
+		}
+		return nil, nil, fmt.Errorf("查询附件记录失败: %v", err)
+	}
+
+	key := attachment.StorageKey
+	response := convertAttachmentToResponse(&attachment)
+	if thumb {
+		key = attachment.ThumbKey
+		response.ContentType = "image/jpeg"
+	}
+
+	reader, err := storage.Default.Open(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("读取附件文件失败: %v", err)
+	}
+	return reader, response, nil
+}
+
+// GetStorageUsage 获取用户存储空间使用情况业务处理
+func GetStorageUsage(userUid string) (*StorageUsage, error) {
+	var attachmentModel model.AttachmentModel
+	used, err := attachmentModel.SumUsageByUser(userUid)
+	if err != nil {
+		return nil, fmt.Errorf("查询存储用量失败: %v", err)
+	}
+	return &StorageUsage{UsedBytes: used, QuotaBytes: userQuotaBytes()}, nil
+}
+
+// generateThumbnail 按最长边等比缩放生成JPEG缩略图
+func generateThumbnail(img image.Image, maxSize int) ([]byte, error) {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > maxSize || height > maxSize {
+		if width >= height {
+			height = height * maxSize / width
+			width = maxSize
+		} else {
+			width = width * maxSize / height
+			height = maxSize
+		}
+	}
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+
+	// 先铺白底，避免透明PNG转JPEG后变黑
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Over, nil)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 80}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// userQuotaBytes 每个用户的存储配额(字节)
+func userQuotaBytes() int64 {
+	quota := config.AppConfig.Storage.UserQuotaMB
+	if quota <= 0 {
+		quota = defaultUserQuotaMB
+	}
+	return quota << 20
+}
+
+// maxFileSizeBytes 单个文件大小上限(字节)
+func maxFileSizeBytes() int64 {
+	size := config.AppConfig.Storage.MaxFileSizeMB
+	if size <= 0 {
+		size = defaultMaxFileSizeMB
+	}
+	return size << 20
+}
+
+// thumbMaxSize 缩略图最长边(像素)
+func thumbMaxSize() int {
+	size := config.AppConfig.Storage.ThumbMaxSize
+	if size <= 0 {
+		size = defaultThumbMaxSize
+	}
+	return size
+}
+
+// convertAttachmentToResponse 将模型转换为响应格式
+func convertAttachmentToResponse(attachment *model.AttachmentModel) *AttachmentResponse {
+	return &AttachmentResponse{
+		AttachmentUid: attachment.AttachmentUid,
+		MoveUid:       attachment.MoveUid,
+		TagUid:        attachment.TagUid,
+		OwnerType:     attachment.OwnerType,
+		FileName:      attachment.FileName,
+		ContentType:   attachment.ContentType,
+		Size:          attachment.Size,
+		Width:         attachment.Width,
+		Height:        attachment.Height,
+		CreatedAt:     time.Unix(attachment.CreatedAt, 0).Format("2006-01-02 15:04:05"),
+	}
+}
@@ -0,0 +1,759 @@
+package service
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"movingManager/database"
+	"movingManager/model"
+	"movingManager/storage"
+)
+
+// 备份文件格式
+const (
+	backupFormatName = "moving-manager-backup" // 备份文件格式标识
+	backupVersion    = 1                       // 当前备份格式版本
+	backupManifest   = "manifest.json"         // 备份描述文件
+
+	backupMaxDataBytes = 64 << 20 // 单个数据文件解压后的大小上限
+)
+
+// 备份中各类数据的文件名
+const (
+	backupMovesFile       = "data/moves.json"
+	backupRoomsFile       = "data/rooms.json"
+	backupTagsFile        = "data/tags.json"
+	backupItemsFile       = "data/items.json"
+	backupIssuesFile      = "data/issues.json"
+	backupAttachmentsFile = "data/attachments.json"
+	backupScanEventsFile  = "data/scan_events.json"
+	backupHandoversFile   = "data/handovers.json"
+)
+
+// 恢复时允许的文件类型及对应扩展名，附件原图与上传时的限制一致
+var (
+	thumbImageTypes     = map[string]string{"image/jpeg": ".jpg"} // 缩略图统一为JPEG
+	signatureImageTypes = map[string]string{"image/png": ".png"}  // 交接签名统一为PNG
+)
+
+// BackupManifest 备份描述信息
+type BackupManifest struct {
+	Format        string         `json:"format"`
+	Version       int            `json:"version"`
+	ExportedAt    string         `json:"exported_at"`
+	SourceUserUid string         `json:"source_user_uid"`
+	Counts        map[string]int `json:"counts"`
+}
+
+// BackupAttachment 备份中的附件记录，File/ThumbFile 为文件在备份中的路径
+type BackupAttachment struct {
+	model.AttachmentModel
+	File      string `json:"file"`
+	ThumbFile string `json:"thumb_file"`
+}
+
+// BackupHandover 备份中的交接记录，SignatureFile 为签名图片在备份中的路径
+type BackupHandover struct {
+	model.HandoverModel
+	SignatureFile string `json:"signature_file"`
+}
+
+// RestoreCount 单类数据的恢复统计
+type RestoreCount struct {
+	Created int `json:"created"` // 新建数量
+	Updated int `json:"updated"` // 覆盖本账户已有记录的数量
+	Skipped int `json:"skipped"` // 已存在而跳过的数量
+}
+
+// RestoreResult 账户恢复结果
+type RestoreResult struct {
+	Version    int                      `json:"version"`
+	ExportedAt string                   `json:"exported_at"`
+	Counts     map[string]*RestoreCount `json:"counts"`
+}
+
+// AccountBackup 账户备份任务
+// 先通过 PrepareAccountBackup 读取数据记录，再调用 Write 流式输出
+type AccountBackup struct {
+	userUid     string
+	moves       []model.MoveModel
+	rooms       []model.RoomModel
+	tags        []model.TagModel
+	items       []model.ItemModel
+	issues      []model.IssueModel
+	attachments []model.AttachmentModel
+	scanEvents  []model.ScanEventModel
+	handovers   []model.HandoverModel
+}
+
+// PrepareAccountBackup 读取用户的全部数据记录
+func PrepareAccountBackup(userUid string) (*AccountBackup, error) {
+	backup := &AccountBackup{userUid: userUid}
+	var err error
+
+	var moveModel model.MoveModel
+	if backup.moves, err = moveModel.ListAllByUser(userUid); err != nil {
+		return nil, fmt.Errorf("查询搬运记录失败: %v", err)
+	}
+	var roomModel model.RoomModel
+	if backup.rooms, err = roomModel.ListAllByUser(userUid); err != nil {
+		return nil, fmt.Errorf("查询房间记录失败: %v", err)
+	}
+	var tagModel model.TagModel
+	if backup.tags, err = tagModel.ListAllByUser(userUid); err != nil {
+		return nil, fmt.Errorf("查询标签记录失败: %v", err)
+	}
+	var itemModel model.ItemModel
+	if backup.items, err = itemModel.ListAllByUser(userUid); err != nil {
+		return nil, fmt.Errorf("查询物品记录失败: %v", err)
+	}
+	var issueModel model.IssueModel
+	if backup.issues, err = issueModel.ListAllByUser(userUid); err != nil {
+		return nil, fmt.Errorf("查询问题记录失败: %v", err)
+	}
+	var attachmentModel model.AttachmentModel
+	if backup.attachments, err = attachmentModel.ListAllByUser(userUid); err != nil {
+		return nil, fmt.Errorf("查询附件记录失败: %v", err)
+	}
+	var eventModel model.ScanEventModel
+	if backup.scanEvents, err = eventModel.ListAllByUser(userUid); err != nil {
+		return nil, fmt.Errorf("查询扫码事件失败: %v", err)
+	}
+	var handoverModel model.HandoverModel
+	if backup.handovers, err = handoverModel.ListAllByUser(userUid); err != nil {
+		return nil, fmt.Errorf("查询交接记录失败: %v", err)
+	}
+	return backup, nil
+}
+
+// Write 将备份以zip格式写入w
+// 数据记录保存为 data/*.json，附件文件逐个从存储复制到 files/ 目录
+func (b *AccountBackup) Write(w io.Writer) error {
+	zw := zip.NewWriter(w)
+
+	// 复制附件文件，文件缺失时保留记录但不含文件，恢复时将跳过该附件
+	attachments := make([]BackupAttachment, 0, len(b.attachments))
+	for _, attachment := range b.attachments {
+		record := BackupAttachment{AttachmentModel: attachment}
+		record.File = "files/" + attachment.AttachmentUid + path.Ext(attachment.StorageKey)
+		if err := copyStorageToZip(zw, attachment.StorageKey, record.File); err != nil {
+			slog.Warn("备份附件文件失败", "attachment_uid", attachment.AttachmentUid, "error", err)
+			record.File = ""
+		}
+		if attachment.ThumbKey != "" {
+			record.ThumbFile = "files/" + attachment.AttachmentUid + "_thumb" + path.Ext(attachment.ThumbKey)
+			if err := copyStorageToZip(zw, attachment.ThumbKey, record.ThumbFile); err != nil {
+				slog.Warn("备份附件缩略图失败", "attachment_uid", attachment.AttachmentUid, "error", err)
+				record.ThumbFile = ""
+			}
+		}
+		attachments = append(attachments, record)
+	}
+
+	handovers := make([]BackupHandover, 0, len(b.handovers))
+	for _, handover := range b.handovers {
+		record := BackupHandover{HandoverModel: handover}
+		record.SignatureFile = "files/handover/" + handover.HandoverUid + path.Ext(handover.SignatureKey)
+		if err := copyStorageToZip(zw, handover.SignatureKey, record.SignatureFile); err != nil {
+			slog.Warn("备份交接签名失败", "handover_uid", handover.HandoverUid, "error", err)
+			record.SignatureFile = ""
+		}
+		handovers = append(handovers, record)
+	}
+
+	entries := []struct {
+		name string
+		data interface{}
+	}{
+		{backupMovesFile, b.moves},
+		{backupRoomsFile, b.rooms},
+		{backupTagsFile, b.tags},
+		{backupItemsFile, b.items},
+		{backupIssuesFile, b.issues},
+		{backupAttachmentsFile, attachments},
+		{backupScanEventsFile, b.scanEvents},
+		{backupHandoversFile, handovers},
+	}
+	for _, entry := range entries {
+		if err := writeZipJSON(zw, entry.name, entry.data); err != nil {
+			return err
+		}
+	}
+
+	manifest := BackupManifest{
+		Format:        backupFormatName,
+		Version:       backupVersion,
+		ExportedAt:    time.Now().Format("2006-01-02 15:04:05"),
+		SourceUserUid: b.userUid,
+		Counts: map[string]int{
+			"moves":       len(b.moves),
+			"rooms":       len(b.rooms),
+			"tags":        len(b.tags),
+			"items":       len(b.items),
+			"issues":      len(b.issues),
+			"attachments": len(attachments),
+			"scan_events": len(b.scanEvents),
+			"handovers":   len(handovers),
+		},
+	}
+	if err := writeZipJSON(zw, backupManifest, manifest); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// MaxBackupSize 备份文件大小上限：用户存储配额加数据记录的上限
+func MaxBackupSize() int64 {
+	return userQuotaBytes() + backupMaxDataBytes
+}
+
+// RestoreAccountBackup 从备份恢复数据到指定用户
+// 保留原有UID，使已打印的二维码继续有效；UID已属于本账户时覆盖原记录，属于其他用户时整体失败；
+// 引用的搬运、房间、标签和物品须包含在备份中或已属于本账户
+func RestoreAccountBackup(userUid string, r io.ReaderAt, size int64) (*RestoreResult, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, ErrBackupInvalid
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, file := range zr.File {
+		files[file.Name] = file
+	}
+
+	var manifest BackupManifest
+	if err := readZipJSON(files, backupManifest, &manifest); err != nil || manifest.Format != backupFormatName {
+		return nil, ErrBackupInvalid
+	}
+	if manifest.Version < 1 || manifest.Version > backupVersion {
+		return nil, ErrBackupUnsupported
+	}
+
+	var moves []model.MoveModel
+	var rooms []model.RoomModel
+	var tags []model.TagModel
+	var items []model.ItemModel
+	var issues []model.IssueModel
+	var attachments []BackupAttachment
+	var scanEvents []model.ScanEventModel
+	var handovers []BackupHandover
+	entries := []struct {
+		name string
+		data interface{}
+	}{
+		{backupMovesFile, &moves},
+		{backupRoomsFile, &rooms},
+		{backupTagsFile, &tags},
+		{backupItemsFile, &items},
+		{backupIssuesFile, &issues},
+		{backupAttachmentsFile, &attachments},
+		{backupScanEventsFile, &scanEvents},
+		{backupHandoversFile, &handovers},
+	}
+	for _, entry := range entries {
+		if err := readZipJSON(files, entry.name, entry.data); err != nil {
+			return nil, ErrBackupInvalid
+		}
+	}
+
+	result := &RestoreResult{
+		Version:    manifest.Version,
+		ExportedAt: manifest.ExportedAt,
+		Counts: map[string]*RestoreCount{
+			"moves":       {},
+			"rooms":       {},
+			"tags":        {},
+			"items":       {},
+			"issues":      {},
+			"attachments": {},
+			"scan_events": {},
+			"handovers":   {},
+		},
+	}
+
+	// 先将附件文件写入存储，数据库恢复失败时再清理
+	savedKeys, replacedKeys, err := restoreAttachmentFiles(userUid, files, attachments, result.Counts["attachments"])
+	if err != nil {
+		return nil, err
+	}
+	signatureKeys, replacedSignatureKeys, err := restoreHandoverSignatures(userUid, files, handovers, result.Counts["handovers"])
+	if err != nil {
+		for _, key := range savedKeys {
+			storage.Default.Delete(key)
+		}
+		return nil, err
+	}
+	savedKeys = append(savedKeys, signatureKeys...)
+	replacedKeys = append(replacedKeys, replacedSignatureKeys...)
+
+	// 收集各记录引用的上级记录，备份外的引用须已属于当前用户
+	parents := newRestoreParents()
+	for _, move := range moves {
+		parents.add("moves", move.MoveUid)
+	}
+	for _, room := range rooms {
+		parents.add("rooms", room.RoomUid)
+	}
+	for _, tag := range tags {
+		parents.add("tags", tag.TagUid)
+	}
+	for _, item := range items {
+		parents.add("items", item.ItemUid)
+	}
+	for _, room := range rooms {
+		parents.ref("moves", room.MoveUid)
+	}
+	for _, tag := range tags {
+		parents.ref("moves", tag.MoveUid)
+		parents.ref("rooms", tag.OriginRoomUid)
+		parents.ref("rooms", tag.DestRoomUid)
+		parents.ref("tags", tag.ParentTagUid)
+	}
+	for _, item := range items {
+		parents.ref("moves", item.MoveUid)
+		parents.ref("tags", item.TagUid)
+	}
+	for _, issue := range issues {
+		parents.ref("moves", issue.MoveUid)
+		parents.ref("tags", issue.TagUid)
+		parents.ref("items", issue.ItemUid)
+	}
+	for _, attachment := range attachments {
+		parents.ref("moves", attachment.MoveUid)
+		parents.ref("tags", attachment.TagUid)
+	}
+	for _, scan := range scanEvents {
+		parents.ref("moves", scan.MoveUid)
+		parents.ref("tags", scan.TagUid)
+	}
+	for _, handover := range handovers {
+		parents.ref("moves", handover.MoveUid)
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := parents.checkTx(tx, userUid); err != nil {
+			return err
+		}
+		for i := range moves {
+			if err := restoreRecordTx(tx, "moves", "move_uid", moves[i].MoveUid, userUid, &moves[i], &moves[i].ID, &moves[i].UserUid, result.Counts["moves"]); err != nil {
+				return err
+			}
+		}
+		for i := range rooms {
+			if err := restoreRecordTx(tx, "rooms", "room_uid", rooms[i].RoomUid, userUid, &rooms[i], &rooms[i].ID, &rooms[i].UserUid, result.Counts["rooms"]); err != nil {
+				return err
+			}
+		}
+		for i := range tags {
+			if err := restoreRecordTx(tx, "tags", "tag_uid", tags[i].TagUid, userUid, &tags[i], &tags[i].ID, &tags[i].UserUid, result.Counts["tags"]); err != nil {
+				return err
+			}
+		}
+		for i := range items {
+			if err := restoreRecordTx(tx, "items", "item_uid", items[i].ItemUid, userUid, &items[i], &items[i].ID, &items[i].UserUid, result.Counts["items"]); err != nil {
+				return err
+			}
+		}
+		for i := range issues {
+			if err := restoreRecordTx(tx, "issues", "issue_uid", issues[i].IssueUid, userUid, &issues[i], &issues[i].ID, &issues[i].UserUid, result.Counts["issues"]); err != nil {
+				return err
+			}
+		}
+		for i := range attachments {
+			attachment := &attachments[i].AttachmentModel
+			if attachment.StorageKey == "" {
+				continue
+			}
+			if err := restoreRecordTx(tx, "attachments", "attachment_uid", attachment.AttachmentUid, userUid, attachment, &attachment.ID, &attachment.UserUid, result.Counts["attachments"]); err != nil {
+				return err
+			}
+		}
+		for i := range handovers {
+			handover := &handovers[i].HandoverModel
+			if handover.SignatureKey == "" {
+				continue
+			}
+			if err := restoreRecordTx(tx, "handovers", "handover_uid", handover.HandoverUid, userUid, handover, &handover.ID, &handover.UserUid, result.Counts["handovers"]); err != nil {
+				return err
+			}
+		}
+		return restoreScanEventsTx(tx, userUid, scanEvents, result.Counts["scan_events"])
+	})
+	if err != nil {
+		for _, key := range savedKeys {
+			storage.Default.Delete(key)
+		}
+		return nil, err
+	}
+
+	// 被覆盖的附件原文件不再被引用
+	for _, key := range replacedKeys {
+		storage.Default.Delete(key)
+	}
+	return result, nil
+}
+
+// restoreAttachmentFiles 将备份中的附件文件写入存储，并为附件记录设置新的存储路径
+// 返回新写入的存储路径和被覆盖附件的原存储路径
+func restoreAttachmentFiles(userUid string, files map[string]*zip.File, attachments []BackupAttachment, count *RestoreCount) ([]string, []string, error) {
+	var attachmentModel model.AttachmentModel
+	existing, err := attachmentModel.ListAllByUser(userUid)
+	if err != nil {
+		return nil, nil, fmt.Errorf("查询附件记录失败: %v", err)
+	}
+	existingByUid := make(map[string]model.AttachmentModel, len(existing))
+	for _, attachment := range existing {
+		existingByUid[attachment.AttachmentUid] = attachment
+	}
+
+	// 写入时按实际字节数累计存储用量，超出配额立即中止；覆盖的附件不重复计算
+	usage, err := attachmentModel.SumUsageByUser(userUid)
+	if err != nil {
+		return nil, nil, fmt.Errorf("查询存储用量失败: %v", err)
+	}
+	for _, attachment := range attachments {
+		if old, ok := existingByUid[attachment.AttachmentUid]; ok {
+			usage -= old.Size + old.ThumbSize
+		}
+	}
+
+	quota := userQuotaBytes()
+	// fileLimit 单个文件可写入的字节数，超过文件大小上限或剩余配额时中止写入
+	fileLimit := func(remaining int64) int64 {
+		return min(maxFileSizeBytes(), remaining)
+	}
+	quotaErr := func(remaining int64) error {
+		if remaining < maxFileSizeBytes() {
+			return ErrStorageQuotaExceeded
+		}
+		return ErrBackupInvalid
+	}
+
+	var savedKeys, replacedKeys []string
+	cleanup := func() {
+		for _, key := range savedKeys {
+			storage.Default.Delete(key)
+		}
+	}
+	for i := range attachments {
+		attachment := &attachments[i]
+		if attachment.AttachmentUid == "" {
+			cleanup()
+			return nil, nil, ErrBackupInvalid
+		}
+		// 存储路径、文件类型和大小以实际写入的文件为准，不信任备份记录中的值；
+		// 文件缺失时路径保持为空，该记录不会被恢复
+		attachment.StorageKey = ""
+		attachment.ContentType = ""
+		attachment.Size = 0
+		attachment.ThumbKey = ""
+		attachment.ThumbSize = 0
+		file, ok := files[attachment.File]
+		if attachment.File == "" || !ok {
+			count.Skipped++
+			continue
+		}
+
+		fileKey := fmt.Sprintf("%s/%s", userUid, uuid.New().String())
+		saved, err := copyZipToStorage(file, fileKey, allowedImageTypes, fileLimit(quota-usage), quotaErr(quota-usage))
+		if err != nil {
+			cleanup()
+			return nil, nil, restoreFileError("保存文件失败", err)
+		}
+		savedKeys = append(savedKeys, saved.Key)
+		attachment.StorageKey = saved.Key
+		attachment.ContentType = saved.ContentType
+		attachment.Size = saved.Size
+
+		if thumb, ok := files[attachment.ThumbFile]; ok && attachment.ThumbFile != "" {
+			remaining := quota - usage - attachment.Size
+			saved, err := copyZipToStorage(thumb, fileKey+"_thumb", thumbImageTypes, fileLimit(remaining), quotaErr(remaining))
+			if err != nil {
+				cleanup()
+				return nil, nil, restoreFileError("保存缩略图失败", err)
+			}
+			savedKeys = append(savedKeys, saved.Key)
+			attachment.ThumbKey = saved.Key
+			attachment.ThumbSize = saved.Size
+		}
+
+		usage += attachment.Size + attachment.ThumbSize
+
+		if old, ok := existingByUid[attachment.AttachmentUid]; ok {
+			replacedKeys = append(replacedKeys, old.StorageKey, old.ThumbKey)
+		}
+	}
+	return savedKeys, replacedKeys, nil
+}
+
+// restoreHandoverSignatures 将备份中的交接签名写入存储，并为交接记录设置新的存储路径
+// 返回新写入的存储路径和被覆盖交接记录的原签名路径
+func restoreHandoverSignatures(userUid string, files map[string]*zip.File, handovers []BackupHandover, count *RestoreCount) ([]string, []string, error) {
+	var handoverModel model.HandoverModel
+	existing, err := handoverModel.ListAllByUser(userUid)
+	if err != nil {
+		return nil, nil, fmt.Errorf("查询交接记录失败: %v", err)
+	}
+	existingKeys := make(map[string]string, len(existing))
+	for _, handover := range existing {
+		existingKeys[handover.HandoverUid] = handover.SignatureKey
+	}
+
+	var savedKeys, replacedKeys []string
+	for i := range handovers {
+		handover := &handovers[i]
+		// 签名路径以实际写入的文件为准，文件缺失时保持为空，该记录不会被恢复
+		handover.SignatureKey = ""
+		file, ok := files[handover.SignatureFile]
+		if handover.SignatureFile == "" || !ok {
+			count.Skipped++
+			continue
+		}
+
+		saved, err := copyZipToStorage(file, fmt.Sprintf("%s/handover/%s", userUid, uuid.New().String()), signatureImageTypes, maxFileSizeBytes(), ErrBackupInvalid)
+		if err != nil {
+			for _, key := range savedKeys {
+				storage.Default.Delete(key)
+			}
+			return nil, nil, restoreFileError("保存签名图片失败", err)
+		}
+		handover.SignatureKey = saved.Key
+		savedKeys = append(savedKeys, handover.SignatureKey)
+
+		if key, ok := existingKeys[handover.HandoverUid]; ok {
+			replacedKeys = append(replacedKeys, key)
+		}
+	}
+	return savedKeys, replacedKeys, nil
+}
+
+// restoreParentColumns 可被引用的上级记录表及其UID列
+var restoreParentColumns = map[string]string{
+	"moves": "move_uid",
+	"rooms": "room_uid",
+	"tags":  "tag_uid",
+	"items": "item_uid",
+}
+
+// restoreParents 恢复数据引用的上级记录
+// 上级记录须包含在本备份中或已属于当前用户，避免将数据挂到其他用户的搬运或标签下
+type restoreParents struct {
+	archived map[string]map[string]bool // 表名 -> 备份中包含的UID
+	external map[string]map[string]bool // 表名 -> 备份外引用的UID
+}
+
+func newRestoreParents() *restoreParents {
+	return &restoreParents{archived: map[string]map[string]bool{}, external: map[string]map[string]bool{}}
+}
+
+// add 登记备份中包含的记录
+func (p *restoreParents) add(table, uid string) {
+	if p.archived[table] == nil {
+		p.archived[table] = map[string]bool{}
+	}
+	p.archived[table][uid] = true
+}
+
+// ref 登记对上级记录的引用，须在全部 add 之后调用
+func (p *restoreParents) ref(table, uid string) {
+	if uid == "" || p.archived[table][uid] {
+		return
+	}
+	if p.external[table] == nil {
+		p.external[table] = map[string]bool{}
+	}
+	p.external[table][uid] = true
+}
+
+// checkTx 事务中校验备份外引用的上级记录均属于当前用户
+func (p *restoreParents) checkTx(tx *gorm.DB, userUid string) error {
+	for table, refs := range p.external {
+		column := restoreParentColumns[table]
+		uids := make([]string, 0, len(refs))
+		for uid := range refs {
+			uids = append(uids, uid)
+		}
+		var owned []string
+		if err := tx.Table(table).Where("user_uid = ? AND "+column+" IN ?", userUid, uids).Pluck(column, &owned).Error; err != nil {
+			return fmt.Errorf("查询已有数据失败: %v", err)
+		}
+		if len(owned) != len(uids) {
+			return ErrBackupConflict
+		}
+	}
+	return nil
+}
+
+// restoreRecordTx 事务中按UID恢复单条记录
+// UID不存在时新建，属于本账户时覆盖，属于其他用户时返回冲突错误；
+// 跳过模型钩子以保留备份中的UID和时间戳
+func restoreRecordTx(tx *gorm.DB, table, uidColumn, uid, userUid string, record interface{}, id *uint, recordUserUid *string, count *RestoreCount) error {
+	if uid == "" {
+		return ErrBackupInvalid
+	}
+	*id = 0
+	*recordUserUid = userUid
+
+	var existing struct {
+		ID      uint
+		UserUid string
+	}
+	if err := tx.Table(table).Select("id, user_uid").Where(uidColumn+" = ?", uid).Limit(1).Scan(&existing).Error; err != nil {
+		return fmt.Errorf("查询已有数据失败: %v", err)
+	}
+
+	session := tx.Session(&gorm.Session{SkipHooks: true})
+	if existing.ID == 0 {
+		if err := session.Create(record).Error; err != nil {
+			return fmt.Errorf("恢复数据失败: %v", err)
+		}
+		count.Created++
+		return nil
+	}
+	if existing.UserUid != userUid {
+		return ErrBackupConflict
+	}
+	if err := session.Model(record).Where("id = ?", existing.ID).Select("*").Omit("id").UpdateColumns(record).Error; err != nil {
+		return fmt.Errorf("恢复数据失败: %v", err)
+	}
+	*id = existing.ID
+	count.Updated++
+	return nil
+}
+
+// restoreScanEventsTx 事务中恢复扫码事件，已存在的事件跳过
+func restoreScanEventsTx(tx *gorm.DB, userUid string, scanEvents []model.ScanEventModel, count *RestoreCount) error {
+	eventUids := make([]string, 0, len(scanEvents))
+	for _, scan := range scanEvents {
+		eventUids = append(eventUids, scan.EventUid)
+	}
+	var eventModel model.ScanEventModel
+	processed, err := eventModel.MapByEventUidsTx(tx, userUid, eventUids)
+	if err != nil {
+		return fmt.Errorf("查询扫码事件失败: %v", err)
+	}
+
+	session := tx.Session(&gorm.Session{SkipHooks: true})
+	for _, scan := range scanEvents {
+		if scan.EventUid == "" {
+			return ErrBackupInvalid
+		}
+		if _, ok := processed[scan.EventUid]; ok {
+			count.Skipped++
+			continue
+		}
+		scan.ID = 0
+		scan.UserUid = userUid
+		if err := session.Create(&scan).Error; err != nil {
+			return fmt.Errorf("恢复扫码事件失败: %v", err)
+		}
+		processed[scan.EventUid] = scan
+		count.Created++
+	}
+	return nil
+}
+
+// writeZipJSON 将数据编码为JSON写入zip
+func writeZipJSON(zw *zip.Writer, name string, data interface{}) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(w).Encode(data)
+}
+
+// readZipJSON 从zip读取JSON数据，文件不存在时保持空值
+func readZipJSON(files map[string]*zip.File, name string, data interface{}) error {
+	file, ok := files[name]
+	if !ok {
+		if name == backupManifest {
+			return ErrBackupInvalid
+		}
+		return nil
+	}
+	r, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	// 按解压后的实际字节数限制，不信任zip中声明的大小
+	return json.NewDecoder(io.LimitReader(r, backupMaxDataBytes)).Decode(data)
+}
+
+// copyStorageToZip 将存储中的文件复制到zip
+func copyStorageToZip(zw *zip.Writer, key, name string) error {
+	r, err := storage.Default.Open(key)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store, Modified: time.Now()})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, r)
+	return err
+}
+
+// restoredFile 从备份写入存储的文件
+type restoredFile struct {
+	Key         string // 存储路径
+	Size        int64  // 实际写入的字节数
+	ContentType string // 按内容识别的类型
+}
+
+// copyZipToStorage 将zip中的文件写入存储，存储路径为 keyPrefix 加类型对应的扩展名
+// 按文件内容重新识别类型，不在允许范围内的文件不写入；
+// 解压后超过 limit 字节时删除已写入的文件并返回 limitErr
+func copyZipToStorage(file *zip.File, keyPrefix string, allowedTypes map[string]string, limit int64, limitErr error) (*restoredFile, error) {
+	if limit <= 0 {
+		return nil, limitErr
+	}
+	r, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	// 多读1字节用于判断是否超限
+	br := bufio.NewReaderSize(io.LimitReader(r, limit+1), 512)
+	head, err := br.Peek(512)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	contentType := http.DetectContentType(head)
+	ext, ok := allowedTypes[contentType]
+	if !ok {
+		return nil, ErrBackupInvalid
+	}
+
+	key := keyPrefix + ext
+	size, err := storage.Default.Save(key, br)
+	if err != nil {
+		return nil, err
+	}
+	if size > limit {
+		storage.Default.Delete(key)
+		return nil, limitErr
+	}
+	return &restoredFile{Key: key, Size: size, ContentType: contentType}, nil
+}
+
+// restoreFileError 包装写入备份文件的错误，备份内容无效或超出配额时原样返回
+func restoreFileError(message string, err error) error {
+	if errors.Is(err, ErrBackupInvalid) || errors.Is(err, ErrStorageQuotaExceeded) {
+		return err
+	}
+	return fmt.Errorf("%s: %v", message, err)
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"movingManager/database"
+	"movingManager/model"
+	"movingManager/storage"
+)
+
+// useBackupTestDB 为备份测试准备SQLite数据库和本地存储，测试结束后恢复
+func useBackupTestDB(t *testing.T) {
+	t.Helper()
+	useTestDB(t)
+
+	local, err := storage.NewLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("storage.NewLocalStorage() error = %v", err)
+	}
+	previous := storage.Default
+	storage.Default = local
+	t.Cleanup(func() { storage.Default = previous })
+}
+
+// writeTestBackup 导出用户备份并返回zip内容
+func writeTestBackup(t *testing.T, userUid string) []byte {
+	t.Helper()
+	backup, err := PrepareAccountBackup(userUid)
+	if err != nil {
+		t.Fatalf("PrepareAccountBackup() error = %v", err)
+	}
+	var buf bytes.Buffer
+	if err := backup.Write(&buf); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+// TestBackupRestoreRoundTrip 备份恢复到空库后数据一致，再次恢复覆盖本账户记录，其他用户恢复时冲突
+func TestBackupRestoreRoundTrip(t *testing.T) {
+	useBackupTestDB(t)
+
+	move := model.MoveModel{MoveUid: "move-1", UserUid: testUserUid, StartLocation: "旧家", EndLocation: "新家", TagCount: 1, UnverifiedTagCount: 1}
+	room := model.RoomModel{RoomUid: "room-1", UserUid: testUserUid, MoveUid: move.MoveUid, RoomName: "厨房"}
+	tag := model.TagModel{TagUid: "tag-1", UserUid: testUserUid, MoveUid: move.MoveUid, TagName: "厨房1", DestRoomUid: room.RoomUid}
+	item := model.ItemModel{ItemUid: "item-1", UserUid: testUserUid, MoveUid: move.MoveUid, TagUid: tag.TagUid, ItemName: "碗", Quantity: 6}
+	for _, create := range []func() error{move.Create, room.Create, tag.Create, item.Create} {
+		if err := create(); err != nil {
+			t.Fatalf("创建测试数据失败: %v", err)
+		}
+	}
+	data := writeTestBackup(t, testUserUid)
+
+	// 清空数据后恢复
+	for _, table := range []string{"items", "tags", "rooms", "moves"} {
+		if err := database.DB.Exec("DELETE FROM " + table).Error; err != nil {
+			t.Fatalf("清空%s失败: %v", table, err)
+		}
+	}
+	result, err := RestoreAccountBackup(testUserUid, bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("RestoreAccountBackup() error = %v", err)
+	}
+	for _, name := range []string{"moves", "rooms", "tags", "items"} {
+		if got := result.Counts[name].Created; got != 1 {
+			t.Errorf("%s 新建数量 = %d, want 1", name, got)
+		}
+	}
+
+	var restored model.ItemModel
+	if err := restored.GetByUID(testUserUid, item.ItemUid, true); err != nil {
+		t.Fatalf("恢复后查询物品失败: %v", err)
+	}
+	if restored.TagUid != tag.TagUid || restored.ItemName != "碗" || restored.Quantity != 6 {
+		t.Errorf("恢复后的物品 = %+v", restored)
+	}
+	var restoredTag model.TagModel
+	if err := restoredTag.GetByUserAndTagUidTx(database.DB, testUserUid, tag.TagUid, true); err != nil {
+		t.Fatalf("恢复后查询标签失败: %v", err)
+	}
+	if restoredTag.DestRoomUid != room.RoomUid || restoredTag.CreatedAt != tag.CreatedAt {
+		t.Errorf("恢复后的标签 = %+v, want 目的地房间 %s 创建时间 %d", restoredTag, room.RoomUid, tag.CreatedAt)
+	}
+
+	// 再次恢复覆盖本账户已有记录
+	result, err = RestoreAccountBackup(testUserUid, bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("再次恢复 error = %v", err)
+	}
+	if got := result.Counts["tags"]; got.Created != 0 || got.Updated != 1 {
+		t.Errorf("再次恢复标签统计 = %+v, want 覆盖1条", *got)
+	}
+
+	// 其他用户不能通过恢复接管已有记录
+	_, err = RestoreAccountBackup(otherUserUid, bytes.NewReader(data), int64(len(data)))
+	if !errors.Is(err, ErrBackupConflict) {
+		t.Fatalf("其他用户恢复 error = %v, want ErrBackupConflict", err)
+	}
+	var owned model.TagModel
+	if err := owned.GetByUserAndTagUidTx(database.DB, testUserUid, tag.TagUid, true); err != nil {
+		t.Errorf("冲突后原用户的标签应保留: %v", err)
+	}
+}
+
+func TestRestoreAccountBackupInvalid(t *testing.T) {
+	useBackupTestDB(t)
+	data := []byte("not a zip")
+	if _, err := RestoreAccountBackup(testUserUid, bytes.NewReader(data), int64(len(data))); !errors.Is(err, ErrBackupInvalid) {
+		t.Errorf("RestoreAccountBackup() error = %v, want ErrBackupInvalid", err)
+	}
+}
+
+// TestRestoreSkipsMissingFiles 备份中缺少文件的附件和交接记录不恢复，也不计入存储用量
+func TestRestoreSkipsMissingFiles(t *testing.T) {
+	useBackupTestDB(t)
+
+	move := model.MoveModel{MoveUid: "move-1", UserUid: testUserUid}
+	attachment := model.AttachmentModel{AttachmentUid: "attachment-1", UserUid: testUserUid, MoveUid: move.MoveUid, OwnerType: model.AttachmentOwnerMove,
+		ContentType: "image/jpeg", Size: 1 << 20, ThumbSize: 1 << 10, StorageKey: otherUserUid + "/photo.jpg", ThumbKey: otherUserUid + "/photo.jpg_thumb"}
+	handover := model.HandoverModel{HandoverUid: "handover-1", UserUid: testUserUid, MoveUid: move.MoveUid, ReceiverName: "张三", SignatureKey: otherUserUid + "/handover/sign.png"}
+	for _, create := range []func() error{move.Create, attachment.Create, handover.Create} {
+		if err := create(); err != nil {
+			t.Fatalf("创建测试数据失败: %v", err)
+		}
+	}
+	// 存储中没有对应文件，备份中的记录不含文件
+	data := writeTestBackup(t, testUserUid)
+	for _, table := range []string{"attachments", "handovers", "moves"} {
+		if err := database.DB.Exec("DELETE FROM " + table).Error; err != nil {
+			t.Fatalf("清空%s失败: %v", table, err)
+		}
+	}
+
+	result, err := RestoreAccountBackup(testUserUid, bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("RestoreAccountBackup() error = %v", err)
+	}
+	for _, name := range []string{"attachments", "handovers"} {
+		if got := *result.Counts[name]; got != (RestoreCount{Skipped: 1}) {
+			t.Errorf("%s 恢复统计 = %+v, want 仅跳过1条", name, got)
+		}
+	}
+
+	var restoredAttachment model.AttachmentModel
+	if err := restoredAttachment.GetByUID(testUserUid, attachment.AttachmentUid); err == nil {
+		t.Errorf("缺少文件的附件不应恢复: %+v", restoredAttachment)
+	}
+	var restoredHandover model.HandoverModel
+	if err := restoredHandover.GetByUID(testUserUid, handover.HandoverUid); err == nil {
+		t.Errorf("缺少签名的交接记录不应恢复: %+v", restoredHandover)
+	}
+	var attachmentModel model.AttachmentModel
+	if usage, err := attachmentModel.SumUsageByUser(testUserUid); err != nil || usage != 0 {
+		t.Errorf("存储用量 = %d, %v, want 0", usage, err)
+	}
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+
+	"movingManager/common"
+)
+
+// DomainError 业务错误
+// Code 为响应码，Status 为对应的HTTP状态码，Message 为直接展示给用户的提示信息，
+// Kind 为错误类别，使 errors.Is(err, ErrValidation) 等判断生效
+type DomainError struct {
+	Kind    error
+	Code    int
+	Status  int
+	Message string
+}
+
+// Error 返回提示信息
+func (e *DomainError) Error() string {
+	return e.Message
+}
+
+// Unwrap 返回错误类别
+func (e *DomainError) Unwrap() error {
+	return e.Kind
+}
+
+// 业务错误类别
+var (
+	ErrNotFound   = &DomainError{Code: common.CodeNotFound, Status: http.StatusNotFound, Message: "记录不存在"}
+	ErrValidation = &DomainError{Code: common.CodeInvalidParams, Status: http.StatusBadRequest, Message: "参数校验失败"}
+	ErrConflict   = &DomainError{Code: common.CodeConflict, Status: http.StatusConflict, Message: "数据冲突"}
+	ErrPermission = &DomainError{Code: common.CodePermissionDenied, Status: http.StatusForbidden, Message: "无权限操作"}
+)
+
+// 记录不存在错误
+var (
+	ErrMoveNotFound       = newDomainError(ErrNotFound, common.CodeMoveNotFound)
+	ErrTagNotFound        = newDomainError(ErrNotFound, common.CodeTagNotFound)
+	ErrRoomNotFound       = newDomainError(ErrNotFound, common.CodeRoomNotFound)
+	ErrItemNotFound       = newDomainError(ErrNotFound, common.CodeItemNotFound)
+	ErrIssueNotFound      = newDomainError(ErrNotFound, common.CodeIssueNotFound)
+	ErrAttachmentNotFound = newDomainError(ErrNotFound, common.CodeAttachmentNotFound)
+	ErrWebhookNotFound    = newDomainError(ErrNotFound, common.CodeWebhookNotFound)
+	ErrHandoverNotFound   = newDomainError(ErrNotFound, common.CodeHandoverNotFound)
+)
+
+// 其他有专用响应码的业务错误
+var (
+	ErrStorageQuotaExceeded    = newDomainError(ErrConflict, common.CodeStorageQuotaExceeded)
+	ErrScanLinkInvalid         = newDomainError(ErrPermission, common.CodeScanLinkInvalid)
+	ErrAnonymousVerifyDisabled = newDomainError(ErrPermission, common.CodeAnonymousVerifyDisabled)
+	ErrBackupInvalid           = newDomainError(ErrValidation, common.CodeBackupInvalid)
+	ErrBackupUnsupported       = newDomainError(ErrValidation, common.CodeBackupUnsupported)
+	ErrBackupConflict          = newDomainError(ErrConflict, common.CodeBackupConflict)
+)
+
+// 使用通用响应码的业务错误
+var (
+	ErrNoTags           = validationError("该搬运下没有标签")
+	ErrSignatureInvalid = validationError("签名图片仅支持PNG格式")
+)
+
+// newDomainError 创建带专用响应码的业务错误，提示信息取自响应码表
+func newDomainError(kind *DomainError, code int) *DomainError {
+	return &DomainError{Kind: kind, Code: code, Status: kind.Status, Message: common.CodeMessage[code]}
+}
+
+// validationError 创建参数校验错误
+func validationError(format string, args ...interface{}) error {
+	return &DomainError{Kind: ErrValidation, Code: ErrValidation.Code, Status: ErrValidation.Status, Message: fmt.Sprintf(format, args...)}
+}
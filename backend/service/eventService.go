@@ -0,0 +1,135 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"movingManager/event"
+	"movingManager/model"
+	"movingManager/repository"
+)
+
+// MoveProgress 搬运标签统计
+type MoveProgress struct {
+	MoveUid            string `json:"move_uid"`
+	TagCount           int    `json:"tag_count"`
+	VerifiedTagCount   int    `json:"verified_tag_count"`
+	UnverifiedTagCount int    `json:"unverified_tag_count"`
+	IsCompleted        int    `json:"is_completed"`
+}
+
+// MoveEventData 搬运事件数据
+type MoveEventData struct {
+	MoveAt               int64  `json:"move_at"`
+	StartLocation        string `json:"start_location"`
+	EndLocation          string `json:"end_location"`
+	Remark               string `json:"remark"`
+	AllowAnonymousVerify int    `json:"allow_anonymous_verify"`
+	IsDeleted            int    `json:"is_deleted"`
+	MoveProgress                // 搬运UID和标签统计
+}
+
+// recordMoveEvent 在事务中生成搬运事件并写入Webhook投递队列
+// 返回的事件在事务提交后通过 publishEvents 发布
+func recordMoveEvent(s repository.Store, eventType string, move *model.MoveModel) ([]event.Event, error) {
+	events := []event.Event{{
+		Type:    eventType,
+		UserUid: move.UserUid,
+		MoveUid: move.MoveUid,
+		Data: MoveEventData{
+			MoveAt:               move.MoveAt,
+			StartLocation:        move.StartLocation,
+			EndLocation:          move.EndLocation,
+			Remark:               move.Remark,
+			AllowAnonymousVerify: move.AllowAnonymousVerify,
+			IsDeleted:            move.IsDeleted,
+			MoveProgress:         convertMoveToProgress(move),
+		},
+	}}
+	return recordEvents(s, events)
+}
+
+// recordTagEvents 在事务中生成标签事件，并附带所属搬运的最新统计事件，一并写入Webhook投递队列
+// tags 需属于同一用户，可跨搬运；返回的事件在事务提交后通过 publishEvents 发布
+func recordTagEvents(s repository.Store, eventType string, tags []model.TagModel) ([]event.Event, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+
+	var events []event.Event
+	var moveUids []string
+	seen := make(map[string]bool)
+	for i := range tags {
+		tag := &tags[i]
+		events = append(events, event.Event{
+			Type:    eventType,
+			UserUid: tag.UserUid,
+			MoveUid: tag.MoveUid,
+			Data:    convertTagToResponse(tag),
+		})
+		if !seen[tag.MoveUid] {
+			seen[tag.MoveUid] = true
+			moveUids = append(moveUids, tag.MoveUid)
+		}
+	}
+
+	// 统计在同一事务中读取，与本次修改一致
+	userUid := tags[0].UserUid
+	for _, moveUid := range moveUids {
+		move, err := s.Moves().GetByUID(userUid, moveUid, false)
+		if err != nil {
+			return nil, fmt.Errorf("查询搬运标签统计失败: %v", err)
+		}
+		events = append(events, event.Event{
+			Type:    event.TypeMoveProgress,
+			UserUid: userUid,
+			MoveUid: moveUid,
+			Data:    convertMoveToProgress(move),
+		})
+	}
+	return recordEvents(s, events)
+}
+
+// recordEvents 设置事件时间并在当前事务中创建Webhook投递记录
+func recordEvents(s repository.Store, events []event.Event) ([]event.Event, error) {
+	now := time.Now().UnixMilli()
+	for i := range events {
+		events[i].CreatedAt = now
+	}
+	if err := enqueueWebhookDeliveries(s, events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// publishEvents 事务提交后向实时订阅者发布事件
+func publishEvents(events []event.Event) {
+	for _, e := range events {
+		event.Default.Publish(e)
+	}
+}
+
+// convertMoveToProgress 提取搬运标签统计
+func convertMoveToProgress(move *model.MoveModel) MoveProgress {
+	return MoveProgress{
+		MoveUid:            move.MoveUid,
+		TagCount:           move.TagCount,
+		VerifiedTagCount:   move.VerifiedTagCount,
+		UnverifiedTagCount: move.UnverifiedTagCount,
+		IsCompleted:        move.IsCompleted,
+	}
+}
+
+// SubscribeMoveEvents 订阅搬运实时事件业务处理
+// 返回事件通道和取消订阅函数，调用方断开连接时必须调用取消函数
+func SubscribeMoveEvents(userUid, moveUid string) (<-chan event.Event, func(), error) {
+	if _, err := store.Moves().GetByUID(userUid, moveUid, true); err != nil {
+		if err == repository.ErrNotFound {
+			return nil, nil, ErrMoveNotFound
+		}
+		return nil, nil, fmt.Errorf("查询搬运记录失败: %v", err)
+	}
+
+	ch, cancel := event.Default.Subscribe(moveUid)
+	return ch, cancel, nil
+}
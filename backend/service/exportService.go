@@ -0,0 +1,387 @@
+package service
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+
+	"movingManager/model"
+)
+
+// 搬运导出格式
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatXLSX = "xlsx"
+	ExportFormatJSON = "json"
+)
+
+// exportBatchSize 导出时每批读取的标签数量
+const exportBatchSize = 200
+
+// exportContentTypes 导出格式对应的Content-Type
+var exportContentTypes = map[string]string{
+	ExportFormatCSV:  "text/csv; charset=utf-8",
+	ExportFormatXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	ExportFormatJSON: "application/json; charset=utf-8",
+}
+
+// exportTagHeader 表格导出的列名（每个物品一行，没有物品的标签单独一行）
+var exportTagHeader = []string{
+	"标签编号", "标签UID", "标签名称", "标签备注", "出发地房间", "目的地房间", "外层容器",
+	"核销状态", "核销人", "核销时间", "申报价值", "币种", "创建时间", "更新时间",
+	"物品名称", "数量", "物品申报价值", "物品币种", "物品备注",
+}
+
+// MoveExport 搬运导出任务
+// 先通过 PrepareMoveExport 校验权限，再调用 Write 流式输出
+type MoveExport struct {
+	userUid string
+	move    model.MoveModel
+	rooms   map[string]model.RoomModel
+	Move    ExportMove
+}
+
+// ExportMove 导出的搬运信息
+type ExportMove struct {
+	MoveUid            string `json:"move_uid"`
+	MoveAt             string `json:"move_at"`
+	StartLocation      string `json:"start_location"`
+	EndLocation        string `json:"end_location"`
+	Remark             string `json:"remark"`
+	TagCount           int    `json:"tag_count"`
+	VerifiedTagCount   int    `json:"verified_tag_count"`
+	UnverifiedTagCount int    `json:"unverified_tag_count"`
+	IsCompleted        int    `json:"is_completed"`
+	CreatedAt          string `json:"created_at"`
+	UpdatedAt          string `json:"updated_at"`
+	ExportedAt         string `json:"exported_at"`
+}
+
+// ExportTag 导出的标签信息
+type ExportTag struct {
+	TagNumber     int          `json:"tag_number"`
+	TagUid        string       `json:"tag_uid"`
+	TagName       string       `json:"tag_name"`
+	Remark        string       `json:"remark"`
+	OriginRoom    string       `json:"origin_room"`
+	DestRoom      string       `json:"dest_room"`
+	ParentTagUid  string       `json:"parent_tag_uid"`
+	ParentTagName string       `json:"parent_tag_name"`
+	IsVerified    int          `json:"is_verified"`
+	VerifiedBy    string       `json:"verified_by"`
+	VerifiedAt    string       `json:"verified_at"`
+	DeclaredValue int64        `json:"declared_value"` // 申报价值(分)
+	Currency      string       `json:"currency"`
+	CreatedAt     string       `json:"created_at"`
+	UpdatedAt     string       `json:"updated_at"`
+	Items         []ExportItem `json:"items"`
+}
+
+// ExportItem 导出的物品信息
+type ExportItem struct {
+	ItemUid       string `json:"item_uid"`
+	ItemName      string `json:"item_name"`
+	Quantity      int    `json:"quantity"`
+	DeclaredValue int64  `json:"declared_value"` // 申报价值(分)
+	Currency      string `json:"currency"`
+	Remark        string `json:"remark"`
+}
+
+// PrepareMoveExport 校验搬运权限并准备导出任务
+func PrepareMoveExport(userUid, moveUid string) (*MoveExport, error) {
+	var move model.MoveModel
+	if err := move.GetByUID(userUid, moveUid, true); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrMoveNotFound
+		}
+		return nil, fmt.Errorf("查询搬运记录失败: %v", err)
+	}
+
+	var roomModel model.RoomModel
+	rooms, err := roomModel.MapByMove(userUid, moveUid)
+	if err != nil {
+		return nil, fmt.Errorf("查询房间列表失败: %v", err)
+	}
+
+	return &MoveExport{
+		userUid: userUid,
+		move:    move,
+		rooms:   rooms,
+		Move: ExportMove{
+			MoveUid:            move.MoveUid,
+			MoveAt:             formatUnixTime(move.MoveAt),
+			StartLocation:      move.StartLocation,
+			EndLocation:        move.EndLocation,
+			Remark:             move.Remark,
+			TagCount:           move.TagCount,
+			VerifiedTagCount:   move.VerifiedTagCount,
+			UnverifiedTagCount: move.UnverifiedTagCount,
+			IsCompleted:        move.IsCompleted,
+			CreatedAt:          formatUnixTime(move.CreatedAt),
+			UpdatedAt:          formatUnixTime(move.UpdatedAt),
+			ExportedAt:         time.Now().Format("2006-01-02 15:04:05"),
+		},
+	}, nil
+}
+
+// ExportContentType 获取导出格式对应的Content-Type
+func ExportContentType(format string) string {
+	return exportContentTypes[format]
+}
+
+// Write 按指定格式将搬运清单流式写入w
+// 标签按批读取，内存占用与搬运规模无关
+func (e *MoveExport) Write(format string, w io.Writer) error {
+	switch format {
+	case ExportFormatCSV:
+		return e.writeCSV(w)
+	case ExportFormatXLSX:
+		return e.writeXLSX(w)
+	case ExportFormatJSON:
+		return e.writeJSON(w)
+	default:
+		return validationError("不支持的导出格式: %s", format)
+	}
+}
+
+// writeJSON 输出JSON格式: {"move": {...}, "tags": [...]}
+func (e *MoveExport) writeJSON(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	moveJSON, err := json.Marshal(e.Move)
+	if err != nil {
+		return err
+	}
+	bw.WriteString(`{"move":`)
+	bw.Write(moveJSON)
+	bw.WriteString(`,"tags":[`)
+
+	first := true
+	err = e.eachTag(func(tag ExportTag) error {
+		data, err := json.Marshal(tag)
+		if err != nil {
+			return err
+		}
+		if !first {
+			bw.WriteByte(',')
+		}
+		first = false
+		_, err = bw.Write(data)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	bw.WriteString("]}")
+	return bw.Flush()
+}
+
+// writeCSV 输出CSV格式，每行包含搬运信息列以便单独使用
+func (e *MoveExport) writeCSV(w io.Writer) error {
+	if _, err := io.WriteString(w, "\xEF\xBB\xBF"); err != nil {
+		return err
+	}
+	writer := csv.NewWriter(w)
+
+	moveColumns := []string{e.Move.MoveUid, e.Move.MoveAt, spreadsheetText(e.Move.StartLocation), spreadsheetText(e.Move.EndLocation)}
+	header := append([]string{"搬运UID", "搬运时间", "出发地", "目的地"}, exportTagHeader...)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	err := e.eachTag(func(tag ExportTag) error {
+		for _, row := range exportTagRows(tag) {
+			record := make([]string, 0, len(header))
+			record = append(record, moveColumns...)
+			for _, value := range row {
+				record = append(record, spreadsheetText(fmt.Sprint(value)))
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeXLSX 输出Excel格式，包含"搬运信息"和"标签清单"两个工作表
+// 标签清单使用流式写入，超出内存阈值的数据由excelize暂存到临时文件
+func (e *MoveExport) writeXLSX(w io.Writer) error {
+	file := excelize.NewFile()
+	defer file.Close()
+
+	infoSheet := "搬运信息"
+	if err := file.SetSheetName(file.GetSheetName(0), infoSheet); err != nil {
+		return err
+	}
+	info := [][]interface{}{
+		{"搬运UID", e.Move.MoveUid},
+		{"搬运时间", e.Move.MoveAt},
+		{"出发地", e.Move.StartLocation},
+		{"目的地", e.Move.EndLocation},
+		{"备注", e.Move.Remark},
+		{"标签数", e.Move.TagCount},
+		{"已核销", e.Move.VerifiedTagCount},
+		{"未核销", e.Move.UnverifiedTagCount},
+		{"是否完成", e.Move.IsCompleted == 1},
+		{"导出时间", e.Move.ExportedAt},
+	}
+	for i, row := range info {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := file.SetSheetRow(infoSheet, cell, &row); err != nil {
+			return err
+		}
+	}
+
+	tagSheet := "标签清单"
+	if _, err := file.NewSheet(tagSheet); err != nil {
+		return err
+	}
+	stream, err := file.NewStreamWriter(tagSheet)
+	if err != nil {
+		return err
+	}
+
+	header := make([]interface{}, len(exportTagHeader))
+	for i, name := range exportTagHeader {
+		header[i] = name
+	}
+	if err := stream.SetRow("A1", header); err != nil {
+		return err
+	}
+
+	rowIndex := 2
+	err = e.eachTag(func(tag ExportTag) error {
+		for _, row := range exportTagRows(tag) {
+			cell, _ := excelize.CoordinatesToCellName(1, rowIndex)
+			if err := stream.SetRow(cell, row); err != nil {
+				return err
+			}
+			rowIndex++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := stream.Flush(); err != nil {
+		return err
+	}
+
+	return file.Write(w)
+}
+
+// eachTag 按编号顺序分批读取标签及其物品
+func (e *MoveExport) eachTag(fn func(tag ExportTag) error) error {
+	number := 0
+	var tagModel model.TagModel
+	return tagModel.EachByMove(e.userUid, e.move.MoveUid, exportBatchSize, func(tags []model.TagModel) error {
+		tagUids := make([]string, 0, len(tags))
+		var parentUids []string
+		for _, tag := range tags {
+			tagUids = append(tagUids, tag.TagUid)
+			if tag.ParentTagUid != "" {
+				parentUids = append(parentUids, tag.ParentTagUid)
+			}
+		}
+
+		var itemModel model.ItemModel
+		items, err := itemModel.ListByTagUids(e.userUid, tagUids)
+		if err != nil {
+			return fmt.Errorf("查询物品列表失败: %v", err)
+		}
+		itemsByTag := make(map[string][]ExportItem)
+		for _, item := range items {
+			itemsByTag[item.TagUid] = append(itemsByTag[item.TagUid], ExportItem{
+				ItemUid:       item.ItemUid,
+				ItemName:      item.ItemName,
+				Quantity:      item.Quantity,
+				DeclaredValue: item.DeclaredValue,
+				Currency:      item.Currency,
+				Remark:        item.Remark,
+			})
+		}
+
+		parents, err := tagModel.MapByUIDs(e.userUid, parentUids)
+		if err != nil {
+			return fmt.Errorf("查询外层容器失败: %v", err)
+		}
+
+		for _, tag := range tags {
+			number++
+			tagItems := itemsByTag[tag.TagUid]
+			if tagItems == nil {
+				tagItems = []ExportItem{}
+			}
+			if err := fn(ExportTag{
+				TagNumber:     number,
+				TagUid:        tag.TagUid,
+				TagName:       tag.TagName,
+				Remark:        tag.Remark,
+				OriginRoom:    e.rooms[tag.OriginRoomUid].RoomName,
+				DestRoom:      e.rooms[tag.DestRoomUid].RoomName,
+				ParentTagUid:  tag.ParentTagUid,
+				ParentTagName: parents[tag.ParentTagUid].TagName,
+				IsVerified:    tag.IsVerified,
+				VerifiedBy:    tag.VerifiedBy,
+				VerifiedAt:    formatUnixTime(tag.VerifiedAt),
+				DeclaredValue: tag.DeclaredValue,
+				Currency:      tag.Currency,
+				CreatedAt:     formatUnixTime(tag.CreatedAt),
+				UpdatedAt:     formatUnixTime(tag.UpdatedAt),
+				Items:         tagItems,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// exportTagRows 将标签展开为表格行，列顺序与 exportTagHeader 一致
+func exportTagRows(tag ExportTag) [][]interface{} {
+	base := []interface{}{
+		tag.TagNumber, tag.TagUid, tag.TagName, tag.Remark, tag.OriginRoom, tag.DestRoom, tag.ParentTagName,
+		verifiedText(tag.IsVerified == 1), tag.VerifiedBy, tag.VerifiedAt,
+		formatAmount(tag.DeclaredValue), tag.Currency, tag.CreatedAt, tag.UpdatedAt,
+	}
+	if len(tag.Items) == 0 {
+		return [][]interface{}{append(base, "", "", "", "", "")}
+	}
+
+	rows := make([][]interface{}, 0, len(tag.Items))
+	for _, item := range tag.Items {
+		row := make([]interface{}, 0, len(exportTagHeader))
+		row = append(row, base...)
+		row = append(row, item.ItemName, strconv.Itoa(item.Quantity), formatAmount(item.DeclaredValue), item.Currency, item.Remark)
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// spreadsheetText 防止CSV公式注入
+// 以 = + - @ 或制表符、回车开头的文本前加单引号，Excel等软件打开时按文本显示而不是执行公式
+// XLSX单元格按字符串类型写入，不会被当作公式，无需处理
+func spreadsheetText(s string) string {
+	if s == "" {
+		return s
+	}
+	switch s[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+		return "'" + s
+	}
+	return s
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+
+	"movingManager/repository"
+)
+
+func TestSpreadsheetText(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{"", ""},
+		{"厨房", "厨房"},
+		{"=HYPERLINK(\"http://x\")", "'=HYPERLINK(\"http://x\")"},
+		{"+1", "'+1"},
+		{"-1", "'-1"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\tx", "'\tx"},
+		{"\rx", "'\rx"},
+		{"a=b", "a=b"},
+	}
+	for _, tc := range cases {
+		if got := spreadsheetText(tc.in); got != tc.want {
+			t.Errorf("spreadsheetText(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestExportFormulaEscapedOnlyInCSV(t *testing.T) {
+	previous := store
+	SetStore(repository.NewGormStore(useTestDB(t)))
+	t.Cleanup(func() { SetStore(previous) })
+
+	move := newTestMove(t)
+	newTestTag(t, move.MoveUid, "=1+1", "")
+	export, err := PrepareMoveExport(testUserUid, move.MoveUid)
+	if err != nil {
+		t.Fatalf("PrepareMoveExport() error = %v", err)
+	}
+
+	var csvBuf bytes.Buffer
+	if err := export.Write(ExportFormatCSV, &csvBuf); err != nil {
+		t.Fatalf("Write(csv) error = %v", err)
+	}
+	if !strings.Contains(csvBuf.String(), ",'=1+1,") {
+		t.Errorf("CSV未做公式注入处理: %s", csvBuf.String())
+	}
+
+	// XLSX按字符串单元格写入，保留原文
+	var xlsxBuf bytes.Buffer
+	if err := export.Write(ExportFormatXLSX, &xlsxBuf); err != nil {
+		t.Fatalf("Write(xlsx) error = %v", err)
+	}
+	file, err := excelize.OpenReader(&xlsxBuf)
+	if err != nil {
+		t.Fatalf("excelize.OpenReader() error = %v", err)
+	}
+	defer file.Close()
+	if got, _ := file.GetCellValue("标签清单", "C2"); got != "=1+1" {
+		t.Errorf("XLSX标签名称 = %q, want %q", got, "=1+1")
+	}
+	if formula, _ := file.GetCellFormula("标签清单", "C2"); formula != "" {
+		t.Errorf("XLSX标签名称被写为公式 %q", formula)
+	}
+}
@@ -0,0 +1,255 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/png" // 注册PNG解码器
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jung-kurt/gofpdf"
+	"gorm.io/gorm"
+
+	"movingManager/metrics"
+	"movingManager/model"
+	"movingManager/storage"
+)
+
+// 签名图片尺寸上限(像素)
+const (
+	maxSignatureWidth  = 2000
+	maxSignatureHeight = 1000
+)
+
+// CreateHandoverRequest 创建交接记录请求参数
+type CreateHandoverRequest struct {
+	MoveUid      string    // 搬运UID
+	ReceiverName string    // 收货人姓名
+	Remark       string    // 备注
+	Signature    io.Reader // 签名图片(PNG)
+}
+
+// HandoverResponse 交接记录响应结构
+type HandoverResponse struct {
+	HandoverUid   string                     `json:"handover_uid"`
+	MoveUid       string                     `json:"move_uid"`
+	ReceiverName  string                     `json:"receiver_name"`
+	HandedAt      string                     `json:"handed_at"`
+	TagCount      int                        `json:"tag_count"`
+	ReceivedCount int                        `json:"received_count"`
+	MissingCount  int                        `json:"missing_count"`
+	MissingTags   []model.HandoverMissingTag `json:"missing_tags"`
+	Remark        string                     `json:"remark"`
+}
+
+// CreateHandover 创建交接记录业务处理
+// 记录签收时刻的标签数量：已核销的标签视为已送达，其余列为未送达
+func CreateHandover(userUid string, req CreateHandoverRequest) (*HandoverResponse, error) {
+	var move model.MoveModel
+	if err := move.GetByUID(userUid, req.MoveUid, true); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrMoveNotFound
+		}
+		return nil, fmt.Errorf("查询搬运记录失败: %v", err)
+	}
+
+	// 读取并校验签名图片（多读1字节用于判断是否超限）
+	maxSize := maxFileSizeBytes()
+	data, err := io.ReadAll(io.LimitReader(req.Signature, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("读取签名图片失败: %v", err)
+	}
+	if int64(len(data)) > maxSize {
+		return nil, validationError("文件大小不能超过%dMB", maxSize>>20)
+	}
+	if http.DetectContentType(data) != "image/png" {
+		return nil, ErrSignatureInvalid
+	}
+	imgConfig, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrSignatureInvalid
+	}
+	// 签名图片会在导出PDF时完整解码，限制尺寸避免超大图片耗尽内存
+	if imgConfig.Width > maxSignatureWidth || imgConfig.Height > maxSignatureHeight {
+		return nil, validationError("签名图片尺寸不能超过%d×%d像素", maxSignatureWidth, maxSignatureHeight)
+	}
+
+	// 统计交接时刻的标签状态
+	var tagModel model.TagModel
+	tags, err := tagModel.GetTagsByMove(userUid, req.MoveUid, true)
+	if err != nil {
+		return nil, fmt.Errorf("查询标签失败: %v", err)
+	}
+	numbers := buildTagNumbers(tags)
+
+	handover := model.HandoverModel{
+		UserUid:      userUid,
+		MoveUid:      move.MoveUid,
+		ReceiverName: req.ReceiverName,
+		HandedAt:     time.Now().Unix(),
+		TagCount:     len(tags),
+		MissingTags:  []model.HandoverMissingTag{},
+		Remark:       req.Remark,
+	}
+	for _, tag := range tags {
+		if tag.IsVerified == 1 {
+			handover.ReceivedCount++
+			continue
+		}
+		handover.MissingTags = append(handover.MissingTags, model.HandoverMissingTag{
+			TagUid:    tag.TagUid,
+			TagNumber: numbers[tag.TagUid],
+			TagName:   tag.TagName,
+		})
+	}
+
+	// 写入签名图片
+	handover.SignatureKey = fmt.Sprintf("%s/handover/%s.png", userUid, uuid.New().String())
+	if _, err := storage.Default.Save(handover.SignatureKey, bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("保存签名图片失败: %v", err)
+	}
+	if err := handover.Create(); err != nil {
+		storage.Default.Delete(handover.SignatureKey)
+		return nil, fmt.Errorf("创建交接记录失败: %v", err)
+	}
+
+	return convertHandoverToResponse(&handover), nil
+}
+
+// GetHandoverList 获取搬运的交接记录列表业务处理
+func GetHandoverList(userUid, moveUid string) ([]HandoverResponse, error) {
+	var move model.MoveModel
+	if err := move.GetByUID(userUid, moveUid, true); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrMoveNotFound
+		}
+		return nil, fmt.Errorf("查询搬运记录失败: %v", err)
+	}
+
+	var handoverModel model.HandoverModel
+	handovers, err := handoverModel.ListByMove(userUid, moveUid)
+	if err != nil {
+		return nil, fmt.Errorf("查询交接记录失败: %v", err)
+	}
+
+	responses := make([]HandoverResponse, 0, len(handovers))
+	for _, handover := range handovers {
+		responses = append(responses, *convertHandoverToResponse(&handover))
+	}
+	return responses, nil
+}
+
+// GenerateHandoverReceiptPDF 生成送达回执PDF业务处理
+func GenerateHandoverReceiptPDF(userUid, handoverUid string) ([]byte, error) {
+	var handover model.HandoverModel
+	if err := handover.GetByUID(userUid, handoverUid); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrHandoverNotFound
+		}
+		return nil, fmt.Errorf("查询交接记录失败: %v", err)
+	}
+
+	var move model.MoveModel
+	if err := move.GetByUID(userUid, handover.MoveUid, false); err != nil {
+		return nil, fmt.Errorf("查询搬运记录失败: %v", err)
+	}
+
+	reader, err := storage.Default.Open(handover.SignatureKey)
+	if err != nil {
+		return nil, fmt.Errorf("读取签名图片失败: %v", err)
+	}
+	defer reader.Close()
+
+	return renderHandoverReceiptPDF(&move, &handover, reader)
+}
+
+// renderHandoverReceiptPDF 输出A4送达回执：搬运信息、签收数量、未送达标签和收货人签名
+func renderHandoverReceiptPDF(move *model.MoveModel, handover *model.HandoverModel, signature io.Reader) ([]byte, error) {
+	defer metrics.ObservePDF("handover_receipt", time.Now())
+	pdf := newReportPDF()
+	pdf.SetAutoPageBreak(true, 15) // 未送达标签列表较长时自动分页
+	pdf.AddPage()
+
+	// 标题和搬运信息
+	pdf.SetFont("Alibaba", "", 16)
+	pdf.CellFormat(0, 10, "送达回执", "", 1, "C", false, 0, "")
+	pdf.SetFont("Alibaba", "", 10)
+	pdf.CellFormat(0, 6, fmt.Sprintf("搬运时间：%s", time.Unix(move.MoveAt, 0).Format("2006-01-02 15:04")), "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 6, fmt.Sprintf("出发地：%s    目的地：%s", move.StartLocation, move.EndLocation), "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 6, "交接时间："+formatUnixTime(handover.HandedAt), "", 1, "L", false, 0, "")
+	pdf.Ln(4)
+
+	// 签收数量
+	pdf.SetFont("Alibaba", "", 13)
+	pdf.CellFormat(0, 9, fmt.Sprintf("收货人 %s 确认已收到 %d / %d 件", handover.ReceiverName, handover.ReceivedCount, handover.TagCount), "", 1, "L", false, 0, "")
+	pdf.SetFont("Alibaba", "", 10)
+	if handover.Remark != "" {
+		pdf.MultiCell(0, 6, "备注："+handover.Remark, "", "L", false)
+	}
+	pdf.Ln(2)
+
+	// 未送达标签
+	if len(handover.MissingTags) == 0 {
+		pdf.CellFormat(0, 7, "全部标签均已送达。", "", 1, "L", false, 0, "")
+	} else {
+		pdf.CellFormat(0, 7, fmt.Sprintf("未送达标签（%d 件）：", len(handover.MissingTags)), "", 1, "L", false, 0, "")
+		pdf.SetFillColor(235, 235, 235)
+		pdf.CellFormat(20, 7, "编号", "1", 0, "C", true, 0, "")
+		pdf.CellFormat(170, 7, "标签名称", "1", 1, "C", true, 0, "")
+		for _, tag := range handover.MissingTags {
+			pdf.CellFormat(20, 7, fmt.Sprintf("%d", tag.TagNumber), "1", 0, "C", false, 0, "")
+			pdf.CellFormat(170, 7, tag.TagName, "1", 1, "L", false, 0, "")
+		}
+	}
+
+	// 签名
+	const signatureHeight = 35.0
+	if pdf.GetY()+signatureHeight+20 > 282 {
+		pdf.AddPage()
+	}
+	pdf.Ln(8)
+	pdf.CellFormat(0, 7, "收货人签名：", "", 1, "L", false, 0, "")
+	options := gofpdf.ImageOptions{ImageType: "PNG", ReadDpi: false}
+	pdf.RegisterImageOptionsReader("signature", options, signature)
+	if pdf.Err() {
+		return nil, fmt.Errorf("读取签名图片失败: %v", pdf.Error())
+	}
+	y := pdf.GetY()
+	pdf.ImageOptions("signature", 10, y, 0, signatureHeight, false, options, 0, "")
+	pdf.SetY(y + signatureHeight + 2)
+	pdf.Line(10, pdf.GetY(), 90, pdf.GetY())
+	pdf.Ln(2)
+	pdf.CellFormat(0, 6, handover.ReceiverName+"    "+formatUnixTime(handover.HandedAt), "", 1, "L", false, 0, "")
+
+	pdf.Ln(4)
+	pdf.SetFont("Alibaba", "", 9)
+	pdf.CellFormat(0, 6, "生成时间："+time.Now().Format("2006-01-02 15:04:05"), "", 1, "L", false, 0, "")
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("生成PDF失败: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// convertHandoverToResponse 将交接模型转换为响应结构
+func convertHandoverToResponse(handover *model.HandoverModel) *HandoverResponse {
+	missingTags := handover.MissingTags
+	if missingTags == nil {
+		missingTags = []model.HandoverMissingTag{}
+	}
+	return &HandoverResponse{
+		HandoverUid:   handover.HandoverUid,
+		MoveUid:       handover.MoveUid,
+		ReceiverName:  handover.ReceiverName,
+		HandedAt:      formatUnixTime(handover.HandedAt),
+		TagCount:      handover.TagCount,
+		ReceivedCount: handover.ReceivedCount,
+		MissingCount:  len(missingTags),
+		MissingTags:   missingTags,
+		Remark:        handover.Remark,
+	}
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"movingManager/database"
+	"movingManager/migrate"
+)
+
+// readinessTimeout 就绪检查中数据库探测的超时时间
+const readinessTimeout = 2 * time.Second
+
+// CheckReadiness 检查服务是否可以接收请求
+// 数据库可连接且表结构已迁移到最新版本时返回nil
+func CheckReadiness(ctx context.Context) error {
+	sqlDB, err := database.DB.DB()
+	if err != nil {
+		return fmt.Errorf("获取数据库连接池失败: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+	if err := sqlDB.PingContext(ctx); err != nil {
+		return fmt.Errorf("数据库不可用: %v", err)
+	}
+
+	pending, err := migrate.Pending(database.DB.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("检查数据库迁移失败: %v", err)
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("存在%d个未执行的数据库迁移", len(pending))
+	}
+	return nil
+}
@@ -0,0 +1,442 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+
+	"movingManager/common"
+	"movingManager/database"
+	"movingManager/event"
+	"movingManager/model"
+	"movingManager/repository"
+)
+
+// 导入限制
+const (
+	maxImportFileSize = 5 << 20 // 导入文件大小上限(字节)
+	maxImportRows     = 1000    // 单次导入的最大行数
+)
+
+// 导入文件列名（支持中英文表头，不区分大小写）
+const (
+	importColumnName     = "name"
+	importColumnRemark   = "remark"
+	importColumnRoom     = "room"
+	importColumnItems    = "items"
+	importColumnValue    = "value"
+	importColumnCurrency = "currency"
+)
+
+var importColumnAliases = map[string]string{
+	"name":     importColumnName,
+	"tag_name": importColumnName,
+	"标签名称":     importColumnName,
+	"名称":       importColumnName,
+	"remark":   importColumnRemark,
+	"备注":       importColumnRemark,
+	"room":     importColumnRoom,
+	"目的地房间":    importColumnRoom,
+	"房间":       importColumnRoom,
+	"items":    importColumnItems,
+	"物品":       importColumnItems,
+	"物品清单":     importColumnItems,
+	"value":    importColumnValue,
+	"申报价值":     importColumnValue,
+	"价值":       importColumnValue,
+	"currency": importColumnCurrency,
+	"币种":       importColumnCurrency,
+}
+
+var (
+	itemQuantityPattern = regexp.MustCompile(`^(.+?)\s*[*xX×]\s*(\d+)$`)
+	importValidator     = validator.New() // 与接口参数使用相同的字段校验规则
+)
+
+// ImportTagsRequest 导入标签请求参数
+type ImportTagsRequest struct {
+	MoveUid  string    // 搬运UID
+	FileName string    // 原始文件名(根据扩展名判断CSV或XLSX)
+	Reader   io.Reader // 文件内容
+	DryRun   bool      // 是否仅校验不写入
+}
+
+// ImportRowError 导入行错误
+type ImportRowError struct {
+	Row     int    `json:"row"`    // 行号(含表头，从1开始，与表格软件一致)
+	Column  string `json:"column"` // 列名
+	Message string `json:"message"`
+}
+
+// ImportResult 导入结果
+type ImportResult struct {
+	DryRun       bool             `json:"dry_run"`
+	TotalRows    int              `json:"total_rows"`
+	ValidRows    int              `json:"valid_rows"`
+	CreatedCount int              `json:"created_count"`
+	NewRooms     []string         `json:"new_rooms"` // 将自动创建的目的地房间
+	Errors       []ImportRowError `json:"errors"`
+}
+
+// importTag 校验通过的导入行
+type importTag struct {
+	tagName       string
+	remark        string
+	roomName      string
+	items         []importItem
+	declaredValue int64
+	currency      string
+}
+
+// importItem 导入行中的物品
+type importItem struct {
+	name     string
+	quantity int
+}
+
+// ImportTags 从CSV或XLSX文件批量导入标签业务处理
+// 逐行校验并返回全部行错误；非试运行且没有错误时在一个事务中创建全部标签、物品和缺少的目的地房间
+func ImportTags(userUid string, req ImportTagsRequest) (*ImportResult, error) {
+	var move model.MoveModel
+	if err := move.GetByUID(userUid, req.MoveUid, true); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrMoveNotFound
+		}
+		return nil, fmt.Errorf("查询搬运记录失败: %v", err)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(req.Reader, maxImportFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("读取文件失败: %v", err)
+	}
+	if len(data) > maxImportFileSize {
+		return nil, validationError("文件大小不能超过%dMB", maxImportFileSize>>20)
+	}
+
+	records, err := readImportRecords(req.FileName, data)
+	if err != nil {
+		return nil, err
+	}
+
+	// 查询已有的目的地房间（按名称匹配，不区分大小写）
+	var roomModel model.RoomModel
+	rooms, err := roomModel.ListByMove(userUid, req.MoveUid, model.RoomSideDestination)
+	if err != nil {
+		return nil, fmt.Errorf("查询房间列表失败: %v", err)
+	}
+	roomsByName := make(map[string]model.RoomModel, len(rooms))
+	for _, room := range rooms {
+		roomsByName[strings.ToLower(room.RoomName)] = room
+	}
+
+	tags, rowErrors, newRooms, err := parseImportRows(records, roomsByName)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{
+		DryRun:    req.DryRun,
+		TotalRows: len(tags) + countErrorRows(rowErrors),
+		ValidRows: len(tags),
+		NewRooms:  newRooms,
+		Errors:    rowErrors,
+	}
+	if req.DryRun || len(rowErrors) > 0 {
+		return result, nil
+	}
+
+	created, events, err := createImportedTags(userUid, &move, tags)
+	if err != nil {
+		return nil, err
+	}
+	result.CreatedCount = len(created)
+
+	publishEvents(events)
+	return result, nil
+}
+
+// readImportRecords 读取CSV或XLSX文件的全部行（第一行为表头）
+func readImportRecords(fileName string, data []byte) ([][]string, error) {
+	var records [][]string
+	switch strings.ToLower(path.Ext(fileName)) {
+	case ".csv":
+		reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+		reader.FieldsPerRecord = -1
+		rows, err := reader.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("CSV文件解析失败: %v", err)
+		}
+		records = rows
+	case ".xlsx":
+		file, err := excelize.OpenReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("Excel文件解析失败: %v", err)
+		}
+		defer file.Close()
+		rows, err := file.GetRows(file.GetSheetName(0))
+		if err != nil {
+			return nil, fmt.Errorf("Excel文件解析失败: %v", err)
+		}
+		records = rows
+	default:
+		return nil, validationError("仅支持CSV和XLSX格式的文件")
+	}
+
+	// 去掉末尾的空行
+	for len(records) > 0 && isBlankRecord(records[len(records)-1]) {
+		records = records[:len(records)-1]
+	}
+	if len(records) < 2 {
+		return nil, validationError("文件中没有可导入的数据")
+	}
+	if len(records)-1 > maxImportRows {
+		return nil, validationError("单次最多导入%d行", maxImportRows)
+	}
+	return records, nil
+}
+
+// parseImportRows 解析并校验导入行
+// 返回校验通过的标签、行错误和需要新建的目的地房间名称
+func parseImportRows(records [][]string, roomsByName map[string]model.RoomModel) ([]importTag, []ImportRowError, []string, error) {
+	// 解析表头
+	columns := make(map[string]int)
+	for i, header := range records[0] {
+		key := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(header, "\ufeff")))
+		if column, ok := importColumnAliases[key]; ok {
+			if _, exists := columns[column]; !exists {
+				columns[column] = i
+			}
+		}
+	}
+	if _, ok := columns[importColumnName]; !ok {
+		return nil, nil, nil, validationError("缺少标签名称列(name)")
+	}
+
+	tags := make([]importTag, 0, len(records)-1)
+	rowErrors := []ImportRowError{}
+	newRooms := []string{}
+	newRoomSet := make(map[string]bool)
+
+	for i, record := range records[1:] {
+		rowNumber := i + 2
+		if isBlankRecord(record) {
+			continue
+		}
+		cell := func(column string) string {
+			idx, ok := columns[column]
+			if !ok || idx >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[idx])
+		}
+		addError := func(column, message string) {
+			rowErrors = append(rowErrors, ImportRowError{Row: rowNumber, Column: column, Message: message})
+		}
+		errorCount := len(rowErrors)
+
+		tag := importTag{
+			tagName:  cell(importColumnName),
+			remark:   cell(importColumnRemark),
+			roomName: cell(importColumnRoom),
+			currency: normalizeCurrency(cell(importColumnCurrency)),
+		}
+
+		if tag.tagName == "" {
+			addError(importColumnName, "标签名称不能为空")
+		} else if len([]rune(tag.tagName)) > common.MaxTagNameLength {
+			addError(importColumnName, fmt.Sprintf("标签名称不能超过%d个字符", common.MaxTagNameLength))
+		}
+		if len([]rune(tag.remark)) > common.MaxRemarkLength {
+			addError(importColumnRemark, fmt.Sprintf("备注不能超过%d个字符", common.MaxRemarkLength))
+		}
+		if len([]rune(tag.roomName)) > common.MaxRoomNameLength {
+			addError(importColumnRoom, fmt.Sprintf("房间名称不能超过%d个字符", common.MaxRoomNameLength))
+		}
+		if importValidator.Var(tag.currency, common.CurrencyRule) != nil {
+			addError(importColumnCurrency, "币种需为ISO 4217货币代码，如CNY")
+		}
+
+		if value := cell(importColumnValue); value != "" {
+			amount, err := parseImportAmount(value)
+			if err != nil {
+				addError(importColumnValue, err.Error())
+			}
+			tag.declaredValue = amount
+		}
+
+		items, err := parseImportItems(cell(importColumnItems))
+		if err != nil {
+			addError(importColumnItems, err.Error())
+		}
+		tag.items = items
+
+		if len(rowErrors) > errorCount {
+			continue
+		}
+
+		// 记录需要新建的目的地房间
+		if tag.roomName != "" {
+			key := strings.ToLower(tag.roomName)
+			if _, ok := roomsByName[key]; !ok && !newRoomSet[key] {
+				newRoomSet[key] = true
+				newRooms = append(newRooms, tag.roomName)
+			}
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, rowErrors, newRooms, nil
+}
+
+// parseImportItems 解析物品清单，多个物品以分号或换行分隔，"名称*数量"表示数量
+func parseImportItems(value string) ([]importItem, error) {
+	var items []importItem
+	for _, part := range strings.FieldsFunc(value, func(r rune) bool {
+		return r == ';' || r == '；' || r == '\n'
+	}) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		item := importItem{name: part, quantity: 1}
+		if match := itemQuantityPattern.FindStringSubmatch(part); match != nil {
+			quantity, err := strconv.Atoi(match[2])
+			if err != nil || quantity <= 0 {
+				return nil, validationError("物品%q的数量无效", part)
+			}
+			item.name = strings.TrimSpace(match[1])
+			item.quantity = quantity
+		}
+		if len([]rune(item.name)) > common.MaxItemNameLength {
+			return nil, validationError("物品名称不能超过%d个字符", common.MaxItemNameLength)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// parseImportAmount 将以元为单位的金额转换为分
+func parseImportAmount(value string) (int64, error) {
+	value = strings.ReplaceAll(value, ",", "")
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil || amount < 0 || math.IsInf(amount, 0) || math.IsNaN(amount) {
+		return 0, validationError("申报价值需为不小于0的数字")
+	}
+	if amount > float64(math.MaxInt64/100) {
+		return 0, validationError("申报价值过大")
+	}
+	return int64(math.Round(amount * 100)), nil
+}
+
+// createImportedTags 在一个事务中创建导入的标签、物品和缺少的目的地房间
+func createImportedTags(userUid string, move *model.MoveModel, tags []importTag) ([]model.TagModel, []event.Event, error) {
+	created := make([]model.TagModel, 0, len(tags))
+	var events []event.Event
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// 事务中重新查询房间，避免与并发创建的房间重名
+		var rooms []model.RoomModel
+		if err := tx.Where("user_uid = ? AND move_uid = ? AND side = ? AND is_deleted = 0", userUid, move.MoveUid, model.RoomSideDestination).
+			Find(&rooms).Error; err != nil {
+			return fmt.Errorf("查询房间列表失败: %v", err)
+		}
+		roomsByName := make(map[string]string, len(rooms))
+		for _, room := range rooms {
+			roomsByName[strings.ToLower(room.RoomName)] = room.RoomUid
+		}
+
+		for _, row := range tags {
+			tag := model.TagModel{
+				UserUid:       userUid,
+				MoveUid:       move.MoveUid,
+				TagName:       row.tagName,
+				Remark:        row.remark,
+				IsVerified:    0, // 默认未核销
+				DeclaredValue: row.declaredValue,
+				Currency:      row.currency,
+			}
+
+			if row.roomName != "" {
+				key := strings.ToLower(row.roomName)
+				roomUid, ok := roomsByName[key]
+				if !ok {
+					room := model.RoomModel{
+						UserUid:  userUid,
+						MoveUid:  move.MoveUid,
+						Side:     model.RoomSideDestination,
+						RoomName: row.roomName,
+						Sort:     len(roomsByName),
+					}
+					if err := tx.Create(&room).Error; err != nil {
+						return fmt.Errorf("创建房间失败: %v", err)
+					}
+					roomUid = room.RoomUid
+					roomsByName[key] = roomUid
+				}
+				tag.DestRoomUid = roomUid
+			}
+
+			if err := tag.CreateTx(tx); err != nil {
+				return fmt.Errorf("创建标签失败: %v", err)
+			}
+
+			for _, it := range row.items {
+				item := model.ItemModel{
+					UserUid:  userUid,
+					MoveUid:  move.MoveUid,
+					TagUid:   tag.TagUid,
+					ItemName: it.name,
+					Quantity: it.quantity,
+					Currency: DefaultCurrency,
+				}
+				if err := tx.Create(&item).Error; err != nil {
+					return fmt.Errorf("创建物品失败: %v", err)
+				}
+			}
+			created = append(created, tag)
+		}
+
+		// 更新搬运记录的标签统计，新增未核销标签后已完成的搬运恢复为未完成
+		s := repository.NewGormStore(tx)
+		if err := s.Moves().AdjustTagCounts(move, len(created), 0, len(created)); err != nil {
+			return fmt.Errorf("更新搬运标签统计失败: %v", err)
+		}
+
+		var err error
+		events, err = recordTagEvents(s, event.TypeTagCreated, created)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return created, events, nil
+}
+
+// countErrorRows 统计存在错误的行数
+func countErrorRows(rowErrors []ImportRowError) int {
+	rows := make(map[int]bool)
+	for _, e := range rowErrors {
+		rows[e.Row] = true
+	}
+	return len(rows)
+}
+
+// isBlankRecord 判断是否为空行
+func isBlankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
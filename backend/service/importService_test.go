@@ -0,0 +1,142 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"movingManager/common"
+	"movingManager/model"
+	"movingManager/repository"
+)
+
+func TestParseImportRows(t *testing.T) {
+	existingRooms := map[string]model.RoomModel{"客厅": {RoomName: "客厅"}}
+	header := []string{"\ufeff标签名称", "remark", "Room", "items", "value", "currency"}
+
+	cases := []struct {
+		name       string
+		rows       [][]string
+		wantTags   int
+		wantErrors []string // 出错的列
+		wantRooms  []string
+	}{
+		{
+			name:      "有效行",
+			rows:      [][]string{{"厨房1", "易碎", "厨房", "碗*6;锅", "1,234.5", "usd"}},
+			wantTags:  1,
+			wantRooms: []string{"厨房"},
+		},
+		{
+			name:     "已有房间不重复创建，空行跳过",
+			rows:     [][]string{{"客厅1", "", "客厅", "", "", ""}, {"", " ", "", "", "", ""}},
+			wantTags: 1,
+		},
+		{
+			name:       "名称为空",
+			rows:       [][]string{{"", "备注", "", "", "", ""}},
+			wantErrors: []string{importColumnName},
+		},
+		{
+			name:       "字段超长",
+			rows:       [][]string{{strings.Repeat("箱", common.MaxTagNameLength+1), strings.Repeat("备", common.MaxRemarkLength+1), strings.Repeat("房", common.MaxRoomNameLength+1), "", "", ""}},
+			wantErrors: []string{importColumnName, importColumnRemark, importColumnRoom},
+		},
+		{
+			name:       "金额、币种和物品数量无效",
+			rows:       [][]string{{"书房1", "", "", "书*0", "-1", "RMB1"}},
+			wantErrors: []string{importColumnCurrency, importColumnValue, importColumnItems},
+		},
+		{
+			name:       "只有出错的行被排除",
+			rows:       [][]string{{"卧室1", "", "卧室", "", "", ""}, {"", "", "卧室", "", "", ""}},
+			wantTags:   1,
+			wantErrors: []string{importColumnName},
+			wantRooms:  []string{"卧室"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			records := append([][]string{header}, tc.rows...)
+			tags, rowErrors, newRooms, err := parseImportRows(records, existingRooms)
+			if err != nil {
+				t.Fatalf("parseImportRows() error = %v", err)
+			}
+			if len(tags) != tc.wantTags {
+				t.Errorf("有效行 = %d, want %d", len(tags), tc.wantTags)
+			}
+			var columns []string
+			for _, e := range rowErrors {
+				columns = append(columns, e.Column)
+			}
+			if !reflect.DeepEqual(columns, tc.wantErrors) {
+				t.Errorf("出错列 = %v, want %v (%+v)", columns, tc.wantErrors, rowErrors)
+			}
+			if len(newRooms) != len(tc.wantRooms) || (len(newRooms) > 0 && !reflect.DeepEqual(newRooms, tc.wantRooms)) {
+				t.Errorf("新建房间 = %v, want %v", newRooms, tc.wantRooms)
+			}
+		})
+	}
+}
+
+func TestParseImportRowsValues(t *testing.T) {
+	records := [][]string{{"name", "items", "value", "currency"}, {"厨房1", "碗*6；锅", "1,234.5", "usd"}}
+	tags, _, _, err := parseImportRows(records, nil)
+	if err != nil || len(tags) != 1 {
+		t.Fatalf("parseImportRows() = %v, %v", tags, err)
+	}
+	tag := tags[0]
+	if tag.declaredValue != 123450 || tag.currency != "USD" {
+		t.Errorf("申报价值 = %d %s, want 123450 USD", tag.declaredValue, tag.currency)
+	}
+	want := []importItem{{name: "碗", quantity: 6}, {name: "锅", quantity: 1}}
+	if !reflect.DeepEqual(tag.items, want) {
+		t.Errorf("物品 = %+v, want %+v", tag.items, want)
+	}
+}
+
+func TestParseImportRowsCurrency(t *testing.T) {
+	// 与接口的 iso4217 校验一致，格式正确但不存在的代码同样拒绝
+	records := [][]string{{"name", "currency"}, {"一", "CNY"}, {"二", "ABC"}}
+	tags, rowErrors, _, err := parseImportRows(records, nil)
+	if err != nil {
+		t.Fatalf("parseImportRows() error = %v", err)
+	}
+	if len(tags) != 1 || tags[0].currency != "CNY" {
+		t.Errorf("有效行 = %+v, want CNY", tags)
+	}
+	if len(rowErrors) != 1 || rowErrors[0].Row != 3 || rowErrors[0].Column != importColumnCurrency {
+		t.Errorf("行错误 = %+v, want 第3行币种错误", rowErrors)
+	}
+}
+
+func TestImportTagsResetsCompletion(t *testing.T) {
+	previous := store
+	SetStore(repository.NewGormStore(useTestDB(t)))
+	t.Cleanup(func() { SetStore(previous) })
+
+	move := newTestMove(t)
+	tag := newTestTag(t, move.MoveUid, "已核销", "")
+	if _, err := VerifyTag(testUserUid, tag.TagUid, 1, false); err != nil {
+		t.Fatalf("VerifyTag() error = %v", err)
+	}
+	assertMoveCounts(t, move.MoveUid, 1, 1, 0, 1)
+
+	result, err := ImportTags(testUserUid, ImportTagsRequest{
+		MoveUid:  move.MoveUid,
+		FileName: "tags.csv",
+		Reader:   strings.NewReader("name\n新增\n"),
+	})
+	if err != nil || result.CreatedCount != 1 {
+		t.Fatalf("ImportTags() = %+v, %v", result, err)
+	}
+	assertMoveCounts(t, move.MoveUid, 2, 1, 1, 0)
+}
+
+func TestParseImportRowsMissingNameColumn(t *testing.T) {
+	_, _, _, err := parseImportRows([][]string{{"remark"}, {"x"}}, nil)
+	if !errors.Is(err, ErrValidation) {
+		t.Errorf("parseImportRows() error = %v, want ErrValidation", err)
+	}
+}
@@ -0,0 +1,335 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"movingManager/model"
+)
+
+// IssueResponse 丢失/损坏问题响应结构
+type IssueResponse struct {
+	IssueUid      string   `json:"issue_uid"`
+	MoveUid       string   `json:"move_uid"`
+	TagUid        string   `json:"tag_uid"`
+	TagNumber     int      `json:"tag_number"` // 标签编号(与打印的"标签 n"一致)
+	TagName       string   `json:"tag_name"`
+	ItemUid       string   `json:"item_uid"`
+	ItemName      string   `json:"item_name"`
+	IssueType     int      `json:"issue_type"` // 问题类型(1-丢失,2-损坏)
+	Status        int      `json:"status"`     // 处理状态(0-待处理,1-已找回,2-已处理)
+	DeclaredValue int64    `json:"declared_value"`
+	Remark        string   `json:"remark"`
+	PhotoRefs     []string `json:"photo_refs"`
+	CreatedAt     string   `json:"created_at"`
+	ResolvedAt    string   `json:"resolved_at,omitempty"`
+}
+
+// CreateIssueRequest 登记问题请求参数
+type CreateIssueRequest struct {
+	TagUid        string   `json:"tag_uid"`        // 标签UID
+	ItemUid       string   `json:"item_uid"`       // 物品UID(为空表示整个标签)
+	IssueType     int      `json:"issue_type"`     // 问题类型(1-丢失,2-损坏)
+	DeclaredValue int64    `json:"declared_value"` // 申报损失金额(分)
+	Remark        string   `json:"remark"`         // 问题说明
+	PhotoRefs     []string `json:"photo_refs"`     // 照片引用
+}
+
+// CreateIssue 登记丢失/损坏问题业务处理
+func CreateIssue(userUid string, req CreateIssueRequest) (*IssueResponse, error) {
+	// 验证标签是否存在且属于当前用户
+	var tag model.TagModel
+	if err := tag.GetByUID(userUid, req.TagUid, true); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrTagNotFound
+		}
+		return nil, fmt.Errorf("查询标签记录失败: %v", err)
+	}
+
+	// 指定物品时验证物品属于该标签
+	var item model.ItemModel
+	if req.ItemUid != "" {
+		if err := item.GetByUID(userUid, req.ItemUid, true); err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil, ErrItemNotFound
+			}
+			return nil, fmt.Errorf("查询物品记录失败: %v", err)
+		}
+		if item.TagUid != tag.TagUid {
+			return nil, validationError("物品不属于该标签")
+		}
+	}
+
+	issue := model.IssueModel{
+		UserUid:       userUid,
+		MoveUid:       tag.MoveUid,
+		TagUid:        tag.TagUid,
+		ItemUid:       req.ItemUid,
+		IssueType:     req.IssueType,
+		Status:        model.IssueStatusOpen,
+		DeclaredValue: req.DeclaredValue,
+		Remark:        req.Remark,
+		PhotoRefs:     req.PhotoRefs,
+	}
+	if err := issue.Create(); err != nil {
+		return nil, fmt.Errorf("登记问题失败: %v", err)
+	}
+
+	response := convertIssueToResponse(&issue)
+	response.TagName = tag.TagName
+	response.ItemName = item.ItemName
+	return response, nil
+}
+
+// UpdateIssueRequest 更新问题请求参数
+type UpdateIssueRequest struct {
+	IssueUid      string   `json:"issue_uid"`      // 问题UID
+	Status        int      `json:"status"`         // 处理状态(0-待处理,1-已找回,2-已处理)
+	DeclaredValue int64    `json:"declared_value"` // 申报损失金额(分)
+	Remark        string   `json:"remark"`         // 问题说明
+	PhotoRefs     []string `json:"photo_refs"`     // 照片引用
+}
+
+// UpdateIssue 更新问题处理进度业务处理
+func UpdateIssue(userUid string, req UpdateIssueRequest) (*IssueResponse, error) {
+	var issue model.IssueModel
+	if err := issue.GetByUID(userUid, req.IssueUid, true); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrIssueNotFound
+		}
+		return nil, fmt.Errorf("查询问题记录失败: %v", err)
+	}
+
+	// 状态变更时记录找回/处理时间，重新打开时清空
+	if issue.Status != req.Status {
+		if req.Status == model.IssueStatusOpen {
+			issue.ResolvedAt = 0
+		} else {
+			issue.ResolvedAt = time.Now().Unix()
+		}
+	}
+	issue.Status = req.Status
+	issue.DeclaredValue = req.DeclaredValue
+	issue.Remark = req.Remark
+	issue.PhotoRefs = req.PhotoRefs
+	if err := issue.Update(); err != nil {
+		return nil, fmt.Errorf("更新问题失败: %v", err)
+	}
+
+	return convertIssueToResponse(&issue), nil
+}
+
+// DeleteIssue 删除问题业务处理
+func DeleteIssue(userUid, issueUid string, isDeleted int) error {
+	var issue model.IssueModel
+
+	// 恢复操作时需要查找已删除记录（isDeleted=0表示恢复）
+	onlyUndeleted := isDeleted != 0
+	if err := issue.GetByUID(userUid, issueUid, onlyUndeleted); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return ErrIssueNotFound
+		}
+		return fmt.Errorf("查询问题记录失败: %v", err)
+	}
+
+	return issue.UpdateDeleteStatus(isDeleted)
+}
+
+// GetIssueList 获取问题列表业务处理
+// 指定标签UID时只返回该标签的问题，否则返回整个搬运的问题
+func GetIssueList(userUid, moveUid, tagUid string) ([]IssueResponse, error) {
+	var issueModel model.IssueModel
+	var issues []model.IssueModel
+	if tagUid != "" {
+		var tag model.TagModel
+		if err := tag.GetByUID(userUid, tagUid, true); err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil, ErrTagNotFound
+			}
+			return nil, fmt.Errorf("查询标签记录失败: %v", err)
+		}
+		moveUid = tag.MoveUid
+
+		list, err := issueModel.ListByTag(userUid, tagUid)
+		if err != nil {
+			return nil, fmt.Errorf("查询问题列表失败: %v", err)
+		}
+		issues = list
+	} else {
+		var move model.MoveModel
+		if err := move.GetByUID(userUid, moveUid, true); err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil, ErrMoveNotFound
+			}
+			return nil, fmt.Errorf("查询搬运记录失败: %v", err)
+		}
+
+		list, err := issueModel.ListByMove(userUid, moveUid, false)
+		if err != nil {
+			return nil, fmt.Errorf("查询问题列表失败: %v", err)
+		}
+		issues = list
+	}
+
+	return buildIssueResponses(userUid, moveUid, issues)
+}
+
+// LossReportTag 损失报告中未核销的标签
+type LossReportTag struct {
+	TagUid    string `json:"tag_uid"`
+	TagNumber int    `json:"tag_number"`
+	TagName   string `json:"tag_name"`
+}
+
+// LossReport 搬运丢失/损坏报告
+type LossReport struct {
+	MoveUid            string          `json:"move_uid"`
+	MoveAt             string          `json:"move_at"`
+	StartLocation      string          `json:"start_location"`
+	EndLocation        string          `json:"end_location"`
+	TagCount           int             `json:"tag_count"`
+	VerifiedTagCount   int             `json:"verified_tag_count"`
+	UnverifiedTagCount int             `json:"unverified_tag_count"`
+	MissingCount       int             `json:"missing_count"`        // 待处理的丢失问题数
+	DamagedCount       int             `json:"damaged_count"`        // 待处理的损坏问题数
+	FoundLaterCount    int             `json:"found_later_count"`    // 已找回的问题数
+	TotalDeclaredValue int64           `json:"total_declared_value"` // 待处理问题申报金额合计(分)
+	Issues             []IssueResponse `json:"issues"`               // 待处理的问题
+	UnverifiedTags     []LossReportTag `json:"unverified_tags"`      // 尚未核销的标签
+}
+
+// GetLossReport 获取搬运丢失/损坏报告业务处理
+func GetLossReport(userUid, moveUid string) (*LossReport, error) {
+	var move model.MoveModel
+	if err := move.GetByUID(userUid, moveUid, true); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrMoveNotFound
+		}
+		return nil, fmt.Errorf("查询搬运记录失败: %v", err)
+	}
+
+	var issueModel model.IssueModel
+	issues, err := issueModel.ListByMove(userUid, moveUid, false)
+	if err != nil {
+		return nil, fmt.Errorf("查询问题列表失败: %v", err)
+	}
+
+	report := &LossReport{
+		MoveUid:            move.MoveUid,
+		MoveAt:             time.Unix(move.MoveAt, 0).Format("2006-01-02 15:04:05"),
+		StartLocation:      move.StartLocation,
+		EndLocation:        move.EndLocation,
+		TagCount:           move.TagCount,
+		VerifiedTagCount:   move.VerifiedTagCount,
+		UnverifiedTagCount: move.UnverifiedTagCount,
+		Issues:             []IssueResponse{},
+		UnverifiedTags:     []LossReportTag{},
+	}
+
+	// 统计问题并筛选待处理的问题
+	var openIssues []model.IssueModel
+	for _, issue := range issues {
+		switch issue.Status {
+		case model.IssueStatusOpen:
+			openIssues = append(openIssues, issue)
+			report.TotalDeclaredValue += issue.DeclaredValue
+			if issue.IssueType == model.IssueTypeMissing {
+				report.MissingCount++
+			} else {
+				report.DamagedCount++
+			}
+		case model.IssueStatusFoundLater:
+			report.FoundLaterCount++
+		}
+	}
+
+	if len(openIssues) > 0 {
+		responses, err := buildIssueResponses(userUid, moveUid, openIssues)
+		if err != nil {
+			return nil, err
+		}
+		report.Issues = responses
+	}
+
+	// 列出尚未核销的标签，便于排查未登记的丢失
+	var tagModel model.TagModel
+	tags, err := tagModel.GetTagsByMove(userUid, moveUid, true)
+	if err != nil {
+		return nil, fmt.Errorf("查询标签失败: %v", err)
+	}
+	numbers := buildTagNumbers(tags)
+	for _, tag := range tags {
+		if tag.IsVerified == 0 {
+			report.UnverifiedTags = append(report.UnverifiedTags, LossReportTag{
+				TagUid:    tag.TagUid,
+				TagNumber: numbers[tag.TagUid],
+				TagName:   tag.TagName,
+			})
+		}
+	}
+
+	return report, nil
+}
+
+// buildIssueResponses 批量转换问题记录并补全标签编号、标签名称和物品名称
+func buildIssueResponses(userUid, moveUid string, issues []model.IssueModel) ([]IssueResponse, error) {
+	var tagModel model.TagModel
+	tags, err := tagModel.GetTagsByMove(userUid, moveUid, true)
+	if err != nil {
+		return nil, fmt.Errorf("查询标签失败: %v", err)
+	}
+	numbers := buildTagNumbers(tags)
+	tagNames := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		tagNames[tag.TagUid] = tag.TagName
+	}
+
+	var itemModel model.ItemModel
+	items, err := itemModel.ListByMove(userUid, moveUid)
+	if err != nil {
+		return nil, fmt.Errorf("查询物品失败: %v", err)
+	}
+	itemNames := make(map[string]string, len(items))
+	for _, item := range items {
+		itemNames[item.ItemUid] = item.ItemName
+	}
+
+	responses := make([]IssueResponse, 0, len(issues))
+	for _, issue := range issues {
+		response := convertIssueToResponse(&issue)
+		response.TagNumber = numbers[issue.TagUid]
+		response.TagName = tagNames[issue.TagUid]
+		response.ItemName = itemNames[issue.ItemUid]
+		responses = append(responses, *response)
+	}
+	return responses, nil
+}
+
+// convertIssueToResponse 将模型转换为响应格式
+func convertIssueToResponse(issue *model.IssueModel) *IssueResponse {
+	resolvedAt := ""
+	if issue.ResolvedAt > 0 {
+		resolvedAt = time.Unix(issue.ResolvedAt, 0).Format("2006-01-02 15:04:05")
+	}
+
+	photoRefs := issue.PhotoRefs
+	if photoRefs == nil {
+		photoRefs = []string{}
+	}
+
+	return &IssueResponse{
+		IssueUid:      issue.IssueUid,
+		MoveUid:       issue.MoveUid,
+		TagUid:        issue.TagUid,
+		ItemUid:       issue.ItemUid,
+		IssueType:     issue.IssueType,
+		Status:        issue.Status,
+		DeclaredValue: issue.DeclaredValue,
+		Remark:        issue.Remark,
+		PhotoRefs:     photoRefs,
+		CreatedAt:     time.Unix(issue.CreatedAt, 0).Format("2006-01-02 15:04:05"),
+		ResolvedAt:    resolvedAt,
+	}
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"movingManager/model"
+	"movingManager/repository"
+)
+
+// ItemResponse 物品响应结构
+type ItemResponse struct {
+	ItemUid       string `json:"item_uid"`
+	TagUid        string `json:"tag_uid"`
+	MoveUid       string `json:"move_uid"`
+	ItemName      string `json:"item_name"`
+	Quantity      int    `json:"quantity"`
+	Remark        string `json:"remark"`
+	DeclaredValue int64  `json:"declared_value"`
+	Currency      string `json:"currency"`
+}
+
+// CreateItemRequest 创建物品请求参数
+type CreateItemRequest struct {
+	TagUid        string `json:"tag_uid"`        // 标签UID
+	ItemName      string `json:"item_name"`      // 物品名称
+	Quantity      int    `json:"quantity"`       // 数量
+	Remark        string `json:"remark"`         // 物品备注
+	DeclaredValue int64  `json:"declared_value"` // 申报价值(分，整行合计)
+	Currency      string `json:"currency"`       // 币种(为空默认CNY)
+}
+
+// CreateItem 创建物品业务处理
+func CreateItem(userUid string, req CreateItemRequest) (*ItemResponse, error) {
+	// 验证标签是否存在且属于当前用户
+	tag, err := store.Tags().GetByUID(userUid, req.TagUid, true)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, ErrTagNotFound
+		}
+		return nil, fmt.Errorf("查询标签记录失败: %v", err)
+	}
+
+	item := model.ItemModel{
+		UserUid:       userUid,
+		MoveUid:       tag.MoveUid,
+		TagUid:        tag.TagUid,
+		ItemName:      req.ItemName,
+		Quantity:      req.Quantity,
+		Remark:        req.Remark,
+		DeclaredValue: req.DeclaredValue,
+		Currency:      normalizeCurrency(req.Currency),
+	}
+	if item.Quantity <= 0 {
+		item.Quantity = 1
+	}
+	if err := store.Items().Create(&item); err != nil {
+		return nil, fmt.Errorf("创建物品失败: %v", err)
+	}
+
+	return convertItemToResponse(&item), nil
+}
+
+// UpdateItemRequest 编辑物品请求参数
+type UpdateItemRequest struct {
+	ItemUid       string `json:"item_uid"`       // 物品UID
+	ItemName      string `json:"item_name"`      // 物品名称
+	Quantity      int    `json:"quantity"`       // 数量
+	Remark        string `json:"remark"`         // 物品备注
+	DeclaredValue int64  `json:"declared_value"` // 申报价值(分，整行合计)
+	Currency      string `json:"currency"`       // 币种(为空默认CNY)
+}
+
+// UpdateItem 编辑物品业务处理
+func UpdateItem(userUid string, req UpdateItemRequest) (*ItemResponse, error) {
+	item, err := store.Items().GetByUID(userUid, req.ItemUid, true)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, ErrItemNotFound
+		}
+		return nil, fmt.Errorf("查询物品记录失败: %v", err)
+	}
+
+	item.ItemName = req.ItemName
+	item.Remark = req.Remark
+	item.DeclaredValue = req.DeclaredValue
+	item.Currency = normalizeCurrency(req.Currency)
+	if req.Quantity > 0 {
+		item.Quantity = req.Quantity
+	}
+	if err := store.Items().Update(item); err != nil {
+		return nil, fmt.Errorf("更新物品失败: %v", err)
+	}
+
+	return convertItemToResponse(item), nil
+}
+
+// DeleteItem 删除物品业务处理
+func DeleteItem(userUid, itemUid string, isDeleted int) error {
+	// 恢复操作时需要查找已删除记录（isDeleted=0表示恢复）
+	onlyUndeleted := isDeleted != 0
+	item, err := store.Items().GetByUID(userUid, itemUid, onlyUndeleted)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return ErrItemNotFound
+		}
+		return fmt.Errorf("查询物品记录失败: %v", err)
+	}
+
+	item.IsDeleted = isDeleted
+	if isDeleted == 1 {
+		item.DeletedAt = time.Now().Unix()
+	}
+	return store.Items().Update(item)
+}
+
+// GetItemList 获取标签下的物品列表业务处理
+func GetItemList(userUid, tagUid string) ([]ItemResponse, error) {
+	if _, err := store.Tags().GetByUID(userUid, tagUid, true); err != nil {
+		if err == repository.ErrNotFound {
+			return nil, ErrTagNotFound
+		}
+		return nil, fmt.Errorf("查询标签记录失败: %v", err)
+	}
+
+	items, err := store.Items().ListByTag(userUid, tagUid)
+	if err != nil {
+		return nil, fmt.Errorf("查询物品列表失败: %v", err)
+	}
+
+	responses := make([]ItemResponse, 0, len(items))
+	for _, item := range items {
+		responses = append(responses, *convertItemToResponse(&item))
+	}
+	return responses, nil
+}
+
+// convertItemToResponse 将模型转换为响应格式
+func convertItemToResponse(item *model.ItemModel) *ItemResponse {
+	return &ItemResponse{
+		ItemUid:       item.ItemUid,
+		TagUid:        item.TagUid,
+		MoveUid:       item.MoveUid,
+		ItemName:      item.ItemName,
+		Quantity:      item.Quantity,
+		Remark:        item.Remark,
+		DeclaredValue: item.DeclaredValue,
+		Currency:      item.Currency,
+	}
+}
@@ -0,0 +1,323 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strings"
+
+	"github.com/golang/freetype"
+	"github.com/golang/freetype/truetype"
+	"github.com/skip2/go-qrcode"
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+	"gorm.io/gorm"
+
+	"movingManager/model"
+)
+
+// 单个标签图片格式
+const (
+	LabelFormatPNG = "png"
+	LabelFormatSVG = "svg"
+)
+
+// 标签图片分辨率限制
+const (
+	DefaultLabelDPI = 300
+	MinLabelDPI     = 72
+	MaxLabelDPI     = 600
+)
+
+// 标签版面尺寸(mm)，与标签PDF中单个标签的宽度一致
+const (
+	labelWidthMM    = 50.0
+	labelHeightMM   = 75.0
+	labelMarginMM   = 2.0
+	labelBandMM     = 10.0 // 目的地房间色带高度
+	labelNumberMM   = 7.0  // "标签 n" 行高
+	labelNameLineMM = 5.0  // 标签名称行高
+	labelNameLines  = 2    // 标签名称最多显示行数
+)
+
+// labelContentTypes 标签图片格式对应的Content-Type
+var labelContentTypes = map[string]string{
+	LabelFormatPNG: "image/png",
+	LabelFormatSVG: "image/svg+xml",
+}
+
+// labelText 标签上的一行文本，坐标单位为像素
+type labelText struct {
+	Text     string
+	Top      float64 // 行框顶部
+	Height   float64 // 行框高度
+	FontSize float64 // 字号(磅)
+	Color    color.RGBA
+}
+
+// labelLayout 单个标签的版面，PNG和SVG共用
+type labelLayout struct {
+	Width, Height int
+	DPI           float64
+	BandColor     *color.RGBA // 目的地房间色带颜色，无房间时为空
+	BandHeight    float64
+	Texts         []labelText
+	QR            *qrcode.QRCode
+	QRLeft        float64
+	QRTop         float64
+	QRSize        float64
+}
+
+// LabelContentType 获取标签图片格式对应的Content-Type
+func LabelContentType(format string) string {
+	return labelContentTypes[format]
+}
+
+// RenderTagLabel 渲染单个标签图片业务处理
+// 版面与标签PDF一致：目的地房间色带、标签编号、名称和扫码二维码；dpi 决定PNG像素尺寸
+func RenderTagLabel(userUid, tagUid, format string, dpi int) ([]byte, error) {
+	if dpi == 0 {
+		dpi = DefaultLabelDPI
+	}
+	if dpi < MinLabelDPI || dpi > MaxLabelDPI {
+		return nil, validationError("分辨率需在%d到%d之间", MinLabelDPI, MaxLabelDPI)
+	}
+
+	var tag model.TagModel
+	if err := tag.GetByUID(userUid, tagUid, true); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrTagNotFound
+		}
+		return nil, fmt.Errorf("查询标签记录失败: %v", err)
+	}
+
+	var tagModel model.TagModel
+	tags, err := tagModel.GetTagsByMove(userUid, tag.MoveUid, true)
+	if err != nil {
+		return nil, fmt.Errorf("查询标签列表失败: %v", err)
+	}
+
+	var destRoom *model.RoomModel
+	if tag.DestRoomUid != "" {
+		var room model.RoomModel
+		if err := room.GetByUID(userUid, tag.DestRoomUid, true); err == nil {
+			destRoom = &room
+		} else if err != gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("查询房间记录失败: %v", err)
+		}
+	}
+
+	textFont, err := loadTextFont()
+	if err != nil {
+		return nil, err
+	}
+	layout, err := buildLabelLayout(textFont, float64(dpi), buildTagNumbers(tags)[tag.TagUid], tag.TagName, destRoom, BuildTagScanURL(tag.TagUid))
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case LabelFormatPNG:
+		return renderLabelPNG(layout, textFont)
+	case LabelFormatSVG:
+		return renderLabelSVG(layout), nil
+	default:
+		return nil, validationError("不支持的图片格式: %s", format)
+	}
+}
+
+// buildLabelLayout 计算标签版面，文本按字体实际宽度换行和缩放
+func buildLabelLayout(textFont *truetype.Font, dpi float64, number int, tagName string, destRoom *model.RoomModel, scanURL string) (*labelLayout, error) {
+	px := func(mm float64) float64 { return mm / 25.4 * dpi }
+	layout := &labelLayout{
+		Width:  int(px(labelWidthMM) + 0.5),
+		Height: int(px(labelHeightMM) + 0.5),
+		DPI:    dpi,
+	}
+	contentWidth := px(labelWidthMM - labelMarginMM*2)
+	top := 0.0
+
+	// 目的地房间色带，文字颜色根据底色亮度选择黑或白
+	if destRoom != nil {
+		r, g, b := parseHexColor(destRoom.Color)
+		band := color.RGBA{uint8(r), uint8(g), uint8(b), 255}
+		textColor := color.RGBA{0, 0, 0, 255}
+		if float64(r)*0.299+float64(g)*0.587+float64(b)*0.114 < 140 {
+			textColor = color.RGBA{255, 255, 255, 255}
+		}
+		layout.BandColor = &band
+		layout.BandHeight = px(labelBandMM)
+		layout.Texts = append(layout.Texts, labelText{
+			Text:     destRoom.RoomName,
+			Top:      0,
+			Height:   layout.BandHeight,
+			FontSize: fitLabelFontSize(textFont, destRoom.RoomName, contentWidth, dpi, 16, 9),
+			Color:    textColor,
+		})
+		top = layout.BandHeight
+	}
+
+	// 标签编号和名称
+	black := color.RGBA{0, 0, 0, 255}
+	top += px(labelMarginMM / 2)
+	layout.Texts = append(layout.Texts, labelText{
+		Text:     fmt.Sprintf("标签 %d", number),
+		Top:      top,
+		Height:   px(labelNumberMM),
+		FontSize: 14,
+		Color:    black,
+	})
+	top += px(labelNumberMM)
+	for _, line := range wrapLabelText(textFont, tagName, contentWidth, dpi, 11, labelNameLines) {
+		layout.Texts = append(layout.Texts, labelText{
+			Text:     line,
+			Top:      top,
+			Height:   px(labelNameLineMM),
+			FontSize: 11,
+			Color:    black,
+		})
+		top += px(labelNameLineMM)
+	}
+
+	// 二维码占满剩余高度，底部对齐
+	qr, err := qrcode.New(scanURL, qrcode.Medium)
+	if err != nil {
+		return nil, fmt.Errorf("生成二维码失败: %v", err)
+	}
+	qr.DisableBorder = true
+	layout.QR = qr
+	layout.QRSize = px(labelWidthMM - labelMarginMM*4)
+	if available := float64(layout.Height) - top - px(labelMarginMM*2); available < layout.QRSize {
+		layout.QRSize = available
+	}
+	layout.QRLeft = (float64(layout.Width) - layout.QRSize) / 2
+	layout.QRTop = float64(layout.Height) - px(labelMarginMM*2) - layout.QRSize
+	return layout, nil
+}
+
+// renderLabelPNG 使用freetype将版面绘制为PNG
+func renderLabelPNG(layout *labelLayout, textFont *truetype.Font) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, layout.Width, layout.Height))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	if layout.BandColor != nil {
+		band := image.Rect(0, 0, layout.Width, int(layout.BandHeight+0.5))
+		draw.Draw(img, band, image.NewUniform(*layout.BandColor), image.Point{}, draw.Src)
+	}
+
+	for _, text := range layout.Texts {
+		c := newTextContext(img, textFont, text.FontSize, layout.DPI, image.NewUniform(text.Color))
+		width := measureLabelText(textFont, text.Text, layout.DPI, text.FontSize)
+		x := (float64(layout.Width) - width) / 2
+		if _, err := c.DrawString(text.Text, freetype.Pt(int(x), int(labelBaseline(text, layout.DPI)))); err != nil {
+			return nil, fmt.Errorf("绘制文本失败: %v", err)
+		}
+	}
+
+	// 二维码按整数倍模块绘制后缩放到目标尺寸，最近邻插值保持边缘清晰
+	qrImage := layout.QR.Image(len(layout.QR.Bitmap()) * 8)
+	qrRect := image.Rect(int(layout.QRLeft), int(layout.QRTop), int(layout.QRLeft+layout.QRSize), int(layout.QRTop+layout.QRSize))
+	xdraw.NearestNeighbor.Scale(img, qrRect, qrImage, qrImage.Bounds(), xdraw.Src, nil)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("编码图片失败: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// renderLabelSVG 将版面输出为SVG，二维码以矢量方块绘制
+// 物理尺寸以毫米标注，便于标签打印应用按实际大小输出
+func renderLabelSVG(layout *labelLayout) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%gmm" height="%gmm" viewBox="0 0 %d %d">`,
+		labelWidthMM, labelHeightMM, layout.Width, layout.Height)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="#ffffff"/>`, layout.Width, layout.Height)
+	if layout.BandColor != nil {
+		fmt.Fprintf(&b, `<rect width="%d" height="%.2f" fill="%s"/>`, layout.Width, layout.BandHeight, svgColor(*layout.BandColor))
+	}
+
+	for _, text := range layout.Texts {
+		fmt.Fprintf(&b, `<text x="%.2f" y="%.2f" font-size="%.2f" font-family="Alibaba PuHuiTi, sans-serif" font-weight="bold" text-anchor="middle" fill="%s">%s</text>`,
+			float64(layout.Width)/2, labelBaseline(text, layout.DPI), text.FontSize*layout.DPI/72, svgColor(text.Color), html.EscapeString(text.Text))
+	}
+
+	bitmap := layout.QR.Bitmap()
+	module := layout.QRSize / float64(len(bitmap))
+	fmt.Fprintf(&b, `<g fill="#000000" shape-rendering="crispEdges">`)
+	for y, row := range bitmap {
+		for x, dark := range row {
+			if dark {
+				fmt.Fprintf(&b, `<rect x="%.2f" y="%.2f" width="%.2f" height="%.2f"/>`,
+					layout.QRLeft+float64(x)*module, layout.QRTop+float64(y)*module, module, module)
+			}
+		}
+	}
+	b.WriteString(`</g></svg>`)
+	return []byte(b.String())
+}
+
+// wrapLabelText 按字体实际宽度逐字换行，超出行数时末行以省略号结尾
+func wrapLabelText(textFont *truetype.Font, text string, maxWidth, dpi, fontSize float64, maxLines int) []string {
+	var lines []string
+	var current []rune
+	for _, r := range strings.TrimSpace(text) {
+		next := append(current, r)
+		if len(current) > 0 && measureLabelText(textFont, string(next), dpi, fontSize) > maxWidth {
+			lines = append(lines, string(current))
+			current = []rune{r}
+			continue
+		}
+		current = next
+	}
+	if len(current) > 0 {
+		lines = append(lines, string(current))
+	}
+
+	if len(lines) > maxLines {
+		last := []rune(lines[maxLines-1])
+		for len(last) > 0 && measureLabelText(textFont, string(last)+"…", dpi, fontSize) > maxWidth {
+			last = last[:len(last)-1]
+		}
+		lines = append(lines[:maxLines-1], string(last)+"…")
+	}
+	return lines
+}
+
+// fitLabelFontSize 从最大字号开始递减，返回能在宽度内完整显示的字号
+func fitLabelFontSize(textFont *truetype.Font, text string, maxWidth, dpi, maxSize, minSize float64) float64 {
+	for size := maxSize; size > minSize; size -= 0.5 {
+		if measureLabelText(textFont, text, dpi, size) <= maxWidth {
+			return size
+		}
+	}
+	return minSize
+}
+
+// measureLabelText 测量文本宽度(像素)
+func measureLabelText(textFont *truetype.Font, text string, dpi, fontSize float64) float64 {
+	face := truetype.NewFace(textFont, &truetype.Options{Size: fontSize, DPI: dpi})
+	defer face.Close()
+	return fixedToFloat(font.MeasureString(face, text))
+}
+
+// labelBaseline 计算文本在行框内垂直居中时的基线位置(像素)
+func labelBaseline(text labelText, dpi float64) float64 {
+	fontPx := text.FontSize * dpi / 72
+	return text.Top + (text.Height+fontPx*0.7)/2
+}
+
+// fixedToFloat 26.6定点数转浮点数
+func fixedToFloat(v fixed.Int26_6) float64 {
+	return float64(v) / 64
+}
+
+// svgColor 颜色转为#RRGGBB
+func svgColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"movingManager/metrics"
+	"movingManager/model"
+)
+
+// manifestRow 装箱清单中的一行（对应一个标签）
+type manifestRow struct {
+	TagNumber int
+	TagName   string
+	Room      string // 出发地房间 → 目的地房间
+	Contents  string // 物品清单，无物品时使用标签备注
+	Verified  bool
+}
+
+// GenerateManifestPDF 生成A4装箱清单业务处理
+// 供搬家司机随车使用：包含搬运信息、数量统计、标签清单表格（带装车/卸车勾选栏）和签字栏
+func GenerateManifestPDF(userUid, moveUid string) ([]byte, error) {
+	var move model.MoveModel
+	if err := move.GetByUID(userUid, moveUid, true); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrMoveNotFound
+		}
+		return nil, fmt.Errorf("查询搬运记录失败: %v", err)
+	}
+
+	var tagModel model.TagModel
+	tags, err := tagModel.GetTagsByMove(userUid, moveUid, true)
+	if err != nil {
+		return nil, fmt.Errorf("查询标签失败: %v", err)
+	}
+	if len(tags) == 0 {
+		return nil, ErrNoTags
+	}
+	numbers := buildTagNumbers(tags)
+
+	var roomModel model.RoomModel
+	rooms, err := roomModel.MapByMove(userUid, moveUid)
+	if err != nil {
+		return nil, fmt.Errorf("查询房间列表失败: %v", err)
+	}
+
+	var itemModel model.ItemModel
+	items, err := itemModel.ListByMove(userUid, moveUid)
+	if err != nil {
+		return nil, fmt.Errorf("查询物品失败: %v", err)
+	}
+	itemsByTag := make(map[string][]model.ItemModel)
+	itemCount := 0
+	for _, item := range items {
+		itemsByTag[item.TagUid] = append(itemsByTag[item.TagUid], item)
+		itemCount += item.Quantity
+	}
+
+	rows := make([]manifestRow, 0, len(tags))
+	for _, tag := range tags {
+		var contents []string
+		if number, ok := numbers[tag.ParentTagUid]; ok {
+			contents = append(contents, fmt.Sprintf("[装于#%d]", number))
+		}
+		if text := tagContentsText(&tag, itemsByTag[tag.TagUid]); text != "" {
+			contents = append(contents, text)
+		}
+
+		rows = append(rows, manifestRow{
+			TagNumber: numbers[tag.TagUid],
+			TagName:   tag.TagName,
+			Room:      manifestRoomText(rooms[tag.OriginRoomUid].RoomName, rooms[tag.DestRoomUid].RoomName),
+			Contents:  strings.Join(contents, "、"),
+			Verified:  tag.IsVerified == 1,
+		})
+	}
+
+	return renderManifestPDF(&move, rows, itemCount)
+}
+
+// renderManifestPDF 输出A4表格形式的装箱清单，每页底部带页码
+func renderManifestPDF(move *model.MoveModel, rows []manifestRow, itemCount int) ([]byte, error) {
+	defer metrics.ObservePDF("manifest", time.Now())
+	pdf := newReportPDF()
+	pdf.AddPage()
+
+	// 标题和搬运信息
+	pdf.SetFont("Alibaba", "", 16)
+	pdf.CellFormat(0, 10, "搬运装箱清单", "", 1, "C", false, 0, "")
+	pdf.SetFont("Alibaba", "", 10)
+	pdf.CellFormat(0, 6, fmt.Sprintf("搬运时间：%s", time.Unix(move.MoveAt, 0).Format("2006-01-02 15:04")), "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 6, fmt.Sprintf("出发地：%s    目的地：%s", move.StartLocation, move.EndLocation), "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 6, fmt.Sprintf("标签总数：%d    物品总数：%d    已核销：%d    未核销：%d", len(rows), itemCount, move.VerifiedTagCount, move.UnverifiedTagCount), "", 1, "L", false, 0, "")
+	if move.Remark != "" {
+		pdf.MultiCell(0, 6, "备注："+move.Remark, "", "L", false)
+	}
+	pdf.Ln(2)
+
+	// 表格列定义，最后两列为装车/卸车勾选栏
+	headers := []string{"编号", "标签名称", "房间", "内容", "状态", "装车", "卸车"}
+	widths := []float64{14, 36, 34, 62, 16, 14, 14}
+	const lineHeight = 5.0
+	const pageBottom = 282.0
+	const checkboxSize = 4.0
+
+	drawHeader := func() {
+		pdf.SetFont("Alibaba", "", 10)
+		pdf.SetFillColor(235, 235, 235)
+		for i, header := range headers {
+			pdf.CellFormat(widths[i], 7, header, "1", 0, "C", true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFillColor(255, 255, 255)
+		pdf.SetFont("Alibaba", "", 9)
+	}
+	drawHeader()
+
+	for _, row := range rows {
+		cells := []string{
+			fmt.Sprintf("%d", row.TagNumber),
+			row.TagName,
+			row.Room,
+			row.Contents,
+			verifiedText(row.Verified),
+		}
+
+		// 计算行高（按最多行数的单元格）
+		lines := 1
+		for i, cell := range cells {
+			if n := len(pdf.SplitText(cell, widths[i]-2)); n > lines {
+				lines = n
+			}
+		}
+		rowHeight := float64(lines)*lineHeight + 1
+
+		// 分页并重复表头
+		if pdf.GetY()+rowHeight > pageBottom {
+			pdf.AddPage()
+			drawHeader()
+		}
+
+		x, y := pdf.GetXY()
+		for i, cell := range cells {
+			pdf.Rect(x, y, widths[i], rowHeight, "D")
+			pdf.SetXY(x+1, y+0.5)
+			align := "L"
+			if i == 0 || i == 4 {
+				align = "C"
+			}
+			pdf.MultiCell(widths[i]-2, lineHeight, cell, "", align, false)
+			x += widths[i]
+		}
+
+		// 勾选栏：绘制空白方框供手工打勾
+		for i := len(cells); i < len(headers); i++ {
+			pdf.Rect(x, y, widths[i], rowHeight, "D")
+			pdf.Rect(x+(widths[i]-checkboxSize)/2, y+(rowHeight-checkboxSize)/2, checkboxSize, checkboxSize, "D")
+			x += widths[i]
+		}
+		pdf.SetXY(10, y+rowHeight)
+	}
+
+	// 签字栏
+	if pdf.GetY()+40 > pageBottom {
+		pdf.AddPage()
+	}
+	pdf.Ln(8)
+	pdf.SetFont("Alibaba", "", 11)
+	pdf.CellFormat(95, 8, "装车确认（司机）：________________", "", 0, "L", false, 0, "")
+	pdf.CellFormat(95, 8, "日期：________________", "", 1, "L", false, 0, "")
+	pdf.Ln(4)
+	pdf.CellFormat(95, 8, "收货确认（客户）：________________", "", 0, "L", false, 0, "")
+	pdf.CellFormat(95, 8, "日期：________________", "", 1, "L", false, 0, "")
+	pdf.Ln(4)
+	pdf.SetFont("Alibaba", "", 9)
+	pdf.CellFormat(0, 6, "生成时间："+time.Now().Format("2006-01-02 15:04:05"), "", 1, "L", false, 0, "")
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("生成PDF失败: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// manifestRoomText 组合出发地和目的地房间名称
+func manifestRoomText(origin, dest string) string {
+	switch {
+	case origin != "" && dest != "":
+		return origin + " → " + dest
+	case dest != "":
+		return "→ " + dest
+	default:
+		return origin
+	}
+}
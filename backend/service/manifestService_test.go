@@ -0,0 +1,43 @@
+package service
+
+import (
+	"testing"
+
+	"movingManager/model"
+)
+
+func TestManifestRoomText(t *testing.T) {
+	cases := []struct {
+		origin, dest string
+		want         string
+	}{
+		{"客厅", "主卧", "客厅 → 主卧"},
+		{"", "主卧", "→ 主卧"},
+		{"客厅", "", "客厅"},
+		{"", "", ""},
+	}
+	for _, tc := range cases {
+		if got := manifestRoomText(tc.origin, tc.dest); got != tc.want {
+			t.Errorf("manifestRoomText(%q, %q) = %q, want %q", tc.origin, tc.dest, got, tc.want)
+		}
+	}
+}
+
+func TestTagContentsText(t *testing.T) {
+	cases := []struct {
+		name   string
+		remark string
+		items  []model.ItemModel
+		want   string
+	}{
+		{"物品带数量", "备注", []model.ItemModel{{ItemName: "碗", Quantity: 6}, {ItemName: "锅", Quantity: 1}}, "碗×6、锅"},
+		{"无物品使用备注", "易碎", nil, "易碎"},
+		{"无物品无备注", "", nil, ""},
+	}
+	for _, tc := range cases {
+		tag := model.TagModel{Remark: tc.remark}
+		if got := tagContentsText(&tag, tc.items); got != tc.want {
+			t.Errorf("%s: tagContentsText() = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
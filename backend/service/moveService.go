@@ -2,10 +2,11 @@ package service
 
 import (
 	"fmt"
+	"time"
 
-	"gorm.io/gorm"
-
+	"movingManager/event"
 	"movingManager/model"
+	"movingManager/repository"
 )
 
 // 定义业务常量
@@ -31,26 +32,35 @@ func CreateMove(userUid string, moveTime int64, startLocation, endLocation, rema
 		IsCompleted:         DefaultNotCompleted,
 	}
 
-	if err := move.Create(); err != nil {
-		return nil, fmt.Errorf("创建搬运记录失败: %v", err)
+	var events []event.Event
+	err := store.Transaction(func(s repository.Store) error {
+		if err := s.Moves().Create(&move); err != nil {
+			return fmt.Errorf("创建搬运记录失败: %v", err)
+		}
+		var err error
+		events, err = recordMoveEvent(s, event.TypeMoveCreated, &move)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
+	publishEvents(events)
 	return &move, nil
 }
 
 // GetMoveDetail 获取搬运详情业务处理
 func GetMoveDetail(userUid, moveUid string, onlyUndeleted bool) (*model.MoveModel, error) {
-	var move model.MoveModel
-
-	// 调用model层查询方法，根据参数决定是否包含已删除记录
-	if err := move.GetByUID(userUid, moveUid, onlyUndeleted); err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("用户无此搬运记录")
+	// 根据参数决定是否包含已删除记录
+	move, err := store.Moves().GetByUID(userUid, moveUid, onlyUndeleted)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, ErrMoveNotFound
 		}
 		return nil, fmt.Errorf("查询搬运记录失败: %v", err)
 	}
 
-	return &move, nil
+	return move, nil
 }
 
 // UpdateMoveRequest 更新搬运请求参数
@@ -61,15 +71,14 @@ type UpdateMoveRequest struct {
 	EndLocation   string `json:"end_location"`   // 目的地
 	Remark        string `json:"remark"`         // 备注
 	IsCompleted   int    `json:"is_completed"`   // 是否完成(0-未完成,1-已完成)
+	AllowAnonymousVerify *int `json:"allow_anonymous_verify"` // 是否允许免登录扫码核销(0-不允许,1-允许)(nil表示不修改)
 }
 
 func UpdateMove(userUid string, req UpdateMoveRequest) (*model.MoveModel, error) {
-	var move model.MoveModel
-
-	// 调用model层查询方法
-	if err := move.GetByUID(userUid, req.MoveUid, true); err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("用户无此搬运记录")
+	move, err := store.Moves().GetByUID(userUid, req.MoveUid, true)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, ErrMoveNotFound
 		}
 		return nil, fmt.Errorf("查询搬运记录失败: %v", err)
 	}
@@ -80,37 +89,62 @@ func UpdateMove(userUid string, req UpdateMoveRequest) (*model.MoveModel, error)
 	move.EndLocation = req.EndLocation
 	move.Remark = req.Remark
 	move.IsCompleted = req.IsCompleted
+	if req.AllowAnonymousVerify != nil {
+		move.AllowAnonymousVerify = *req.AllowAnonymousVerify
+	}
 
-	// 调用model层更新方法
-	if err := move.Update(); err != nil {
-		return nil, fmt.Errorf("更新搬运记录失败: %v", err)
+	var events []event.Event
+	err = store.Transaction(func(s repository.Store) error {
+		if err := s.Moves().Update(move); err != nil {
+			return fmt.Errorf("更新搬运记录失败: %v", err)
+		}
+		events, err = recordMoveEvent(s, event.TypeMoveUpdated, move)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return &move, nil
+	publishEvents(events)
+	return move, nil
 }
 
 // DeleteMove 删除搬运业务处理
 func DeleteMove(userUid, moveUid string, isDeleted int) error {
-	var move model.MoveModel
-
 	// 恢复操作时需要查找已删除记录（isDeleted=0表示恢复）
 	onlyUndeleted := isDeleted != 0
-	// 调用model层查询方法
-	if err := move.GetByUID(userUid, moveUid, onlyUndeleted); err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return fmt.Errorf("用户无此搬运记录")
+	move, err := store.Moves().GetByUID(userUid, moveUid, onlyUndeleted)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return ErrMoveNotFound
 		}
 		return fmt.Errorf("查询搬运记录失败: %v", err)
 	}
 
-	// 调用model层更新删除状态方法
-	return move.UpdateDeleteStatus(isDeleted)
+	// 更新删除状态
+	move.IsDeleted = isDeleted
+	if isDeleted == 1 {
+		move.DeletedAt = time.Now().Unix()
+	}
+	var events []event.Event
+	err = store.Transaction(func(s repository.Store) error {
+		if err := s.Moves().Update(move); err != nil {
+			return fmt.Errorf("更新搬运记录失败: %v", err)
+		}
+		events, err = recordMoveEvent(s, event.TypeMoveDeleted, move)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	publishEvents(events)
+	return nil
 }
 
 // GetMoveList 获取搬运列表业务处理
 func GetMoveList(userUid string, page, pageSize int, onlyUndeleted bool) ([]model.MoveModel, int64, error) {
-	var move model.MoveModel
-	moves, total, err := move.ListByUser(userUid, page, pageSize, onlyUndeleted)
+	moves, total, err := store.Moves().ListByUser(userUid, page, pageSize, onlyUndeleted)
 	if err != nil {
 		return nil, 0, fmt.Errorf("查询搬运列表失败: %v", err)
 	}
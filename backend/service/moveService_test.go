@@ -0,0 +1,149 @@
+package service
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCreateAndGetMove(t *testing.T) {
+	forEachStore(t, func(t *testing.T) {
+
+		move, err := CreateMove(testUserUid, 1700000000, "旧家", "新家", "周末搬")
+		if err != nil {
+			t.Fatalf("CreateMove() error = %v", err)
+		}
+		if move.MoveUid == "" {
+			t.Fatal("CreateMove() 未生成搬运UID")
+		}
+
+		got, err := GetMoveDetail(testUserUid, move.MoveUid, true)
+		if err != nil {
+			t.Fatalf("GetMoveDetail() error = %v", err)
+		}
+		if got.StartLocation != "旧家" || got.EndLocation != "新家" || got.TagCount != 0 {
+			t.Errorf("GetMoveDetail() = %+v", got)
+		}
+
+		if _, err := GetMoveDetail(otherUserUid, move.MoveUid, true); !errors.Is(err, ErrMoveNotFound) {
+			t.Errorf("其他用户查询 error = %v, want ErrMoveNotFound", err)
+		}
+	})
+}
+
+func TestUpdateMove(t *testing.T) {
+	forEachStore(t, func(t *testing.T) {
+		move, _ := CreateMove(testUserUid, 1700000000, "旧家", "新家", "")
+
+		cases := []struct {
+			name    string
+			userUid string
+			moveUid string
+			wantErr error
+		}{
+			{"本人更新", testUserUid, move.MoveUid, nil},
+			{"其他用户", otherUserUid, move.MoveUid, ErrMoveNotFound},
+			{"记录不存在", testUserUid, "33333333-3333-3333-3333-333333333333", ErrMoveNotFound},
+		}
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				updated, err := UpdateMove(tc.userUid, UpdateMoveRequest{
+					MoveUid:       tc.moveUid,
+					MoveAt:        1700086400,
+					StartLocation: "A",
+					EndLocation:   "B",
+					IsCompleted:   1,
+				})
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("UpdateMove() error = %v, want %v", err, tc.wantErr)
+				}
+				if err == nil && (updated.StartLocation != "A" || updated.IsCompleted != 1) {
+					t.Errorf("UpdateMove() = %+v", updated)
+				}
+			})
+		}
+	})
+}
+
+func TestUpdateMoveAnonymousVerifyOptional(t *testing.T) {
+	forEachStore(t, func(t *testing.T) {
+		move := newTestMove(t)
+		enable, disable := 1, 0
+
+		cases := []struct {
+			name  string
+			value *int
+			want  int
+		}{
+			{"开启", &enable, 1},
+			{"不传保持开启", nil, 1},
+			{"关闭", &disable, 0},
+			{"不传保持关闭", nil, 0},
+		}
+		for _, tc := range cases {
+			updated, err := UpdateMove(testUserUid, UpdateMoveRequest{
+				MoveUid:              move.MoveUid,
+				MoveAt:               move.MoveAt,
+				StartLocation:        move.StartLocation,
+				EndLocation:          move.EndLocation,
+				AllowAnonymousVerify: tc.value,
+			})
+			if err != nil {
+				t.Fatalf("%s: UpdateMove() error = %v", tc.name, err)
+			}
+			if updated.AllowAnonymousVerify != tc.want {
+				t.Errorf("%s: allow_anonymous_verify = %d, want %d", tc.name, updated.AllowAnonymousVerify, tc.want)
+			}
+		}
+	})
+}
+
+func TestDeleteAndRestoreMove(t *testing.T) {
+	forEachStore(t, func(t *testing.T) {
+		move, _ := CreateMove(testUserUid, 1700000000, "旧家", "新家", "")
+
+		if err := DeleteMove(testUserUid, move.MoveUid, 1); err != nil {
+			t.Fatalf("DeleteMove() error = %v", err)
+		}
+		if _, err := GetMoveDetail(testUserUid, move.MoveUid, true); !errors.Is(err, ErrMoveNotFound) {
+			t.Errorf("删除后查询 error = %v, want ErrMoveNotFound", err)
+		}
+		deleted, err := GetMoveDetail(testUserUid, move.MoveUid, false)
+		if err != nil || deleted.DeletedAt == 0 {
+			t.Fatalf("查询已删除记录 = %+v, %v", deleted, err)
+		}
+
+		if err := DeleteMove(testUserUid, move.MoveUid, 0); err != nil {
+			t.Fatalf("恢复 DeleteMove() error = %v", err)
+		}
+		if _, err := GetMoveDetail(testUserUid, move.MoveUid, true); err != nil {
+			t.Errorf("恢复后查询 error = %v", err)
+		}
+	})
+}
+
+func TestGetMoveListOrderAndPagination(t *testing.T) {
+	forEachStore(t, func(t *testing.T) {
+		late, _ := CreateMove(testUserUid, 1700200000, "A", "B", "")
+		early, _ := CreateMove(testUserUid, 1700100000, "A", "B", "")
+		done, _ := CreateMove(testUserUid, 1700000000, "A", "B", "")
+		UpdateMove(testUserUid, UpdateMoveRequest{MoveUid: done.MoveUid, MoveAt: done.MoveAt, StartLocation: "A", EndLocation: "B", IsCompleted: 1})
+		CreateMove(otherUserUid, 1700000000, "A", "B", "")
+
+		moves, total, err := GetMoveList(testUserUid, 1, 2, true)
+		if err != nil {
+			t.Fatalf("GetMoveList() error = %v", err)
+		}
+		if total != 3 || len(moves) != 2 {
+			t.Fatalf("GetMoveList() total = %d, len = %d", total, len(moves))
+		}
+		// 未完成的在前，再按搬运时间正序
+		if moves[0].MoveUid != early.MoveUid || moves[1].MoveUid != late.MoveUid {
+			t.Errorf("第一页顺序错误: %s, %s", moves[0].MoveUid, moves[1].MoveUid)
+		}
+
+		moves, _, _ = GetMoveList(testUserUid, 2, 2, true)
+		if len(moves) != 1 || moves[0].MoveUid != done.MoveUid {
+			t.Errorf("第二页 = %+v", moves)
+		}
+	})
+}
@@ -0,0 +1,398 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+	"gorm.io/gorm"
+
+	"movingManager/metrics"
+	"movingManager/model"
+)
+
+// 报告格式
+const (
+	ReportFormatPDF = "pdf"
+	ReportFormatCSV = "csv"
+)
+
+// DefaultCurrency 未指定币种时使用的默认币种
+const DefaultCurrency = "CNY"
+
+// reportFontPath 报告使用的中文字体，与标签PDF一致
+const reportFontPath = "fonts/AlibabaPuHuiTi-3-95-ExtraBold.ttf"
+
+// insuranceRow 保险清单中的一行（对应一个标签）
+type insuranceRow struct {
+	TagNumber int
+	TagName   string
+	Contents  string                // 物品清单，无物品时使用标签备注
+	Values    []model.CurrencyTotal // 标签及其物品的申报价值合计
+	Verified  bool
+	Issues    string // 待处理/已找回的问题摘要
+}
+
+// insuranceReport 保险申报清单数据
+type insuranceReport struct {
+	Move            model.MoveModel
+	Rows            []insuranceRow
+	Totals          []model.CurrencyTotal // 全部标签申报价值合计
+	UnverifiedTotal []model.CurrencyTotal // 未核销标签申报价值合计
+}
+
+// GetMoveDeclaredTotals 按币种汇总搬运的申报价值（标签价值 + 物品价值）
+func GetMoveDeclaredTotals(userUid, moveUid string) ([]model.CurrencyTotal, error) {
+	var tagModel model.TagModel
+	tagTotals, err := tagModel.SumDeclaredValueByMove(userUid, moveUid)
+	if err != nil {
+		return nil, fmt.Errorf("统计标签申报价值失败: %v", err)
+	}
+
+	var itemModel model.ItemModel
+	itemTotals, err := itemModel.SumDeclaredValueByMove(userUid, moveUid)
+	if err != nil {
+		return nil, fmt.Errorf("统计物品申报价值失败: %v", err)
+	}
+
+	totals := make(map[string]int64)
+	addCurrencyTotals(totals, tagTotals)
+	addCurrencyTotals(totals, itemTotals)
+	return sortCurrencyTotals(totals), nil
+}
+
+// GenerateInsuranceReport 生成保险申报清单业务处理
+// format 支持 pdf 和 csv，列出每个标签的编号、内容、申报价值和最终核销状态
+func GenerateInsuranceReport(userUid, moveUid, format string) ([]byte, error) {
+	report, err := buildInsuranceReport(userUid, moveUid)
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case ReportFormatCSV:
+		return renderInsuranceCSV(report)
+	case ReportFormatPDF:
+		return renderInsurancePDF(report)
+	default:
+		return nil, validationError("不支持的报告格式: %s", format)
+	}
+}
+
+// buildInsuranceReport 汇总搬运下标签、物品和问题，生成清单数据
+func buildInsuranceReport(userUid, moveUid string) (*insuranceReport, error) {
+	var move model.MoveModel
+	if err := move.GetByUID(userUid, moveUid, true); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrMoveNotFound
+		}
+		return nil, fmt.Errorf("查询搬运记录失败: %v", err)
+	}
+
+	var tagModel model.TagModel
+	tags, err := tagModel.GetTagsByMove(userUid, moveUid, true)
+	if err != nil {
+		return nil, fmt.Errorf("查询标签失败: %v", err)
+	}
+	if len(tags) == 0 {
+		return nil, ErrNoTags
+	}
+	numbers := buildTagNumbers(tags)
+
+	var itemModel model.ItemModel
+	items, err := itemModel.ListByMove(userUid, moveUid)
+	if err != nil {
+		return nil, fmt.Errorf("查询物品失败: %v", err)
+	}
+	itemsByTag := make(map[string][]model.ItemModel)
+	for _, item := range items {
+		itemsByTag[item.TagUid] = append(itemsByTag[item.TagUid], item)
+	}
+
+	var issueModel model.IssueModel
+	issues, err := issueModel.ListByMove(userUid, moveUid, false)
+	if err != nil {
+		return nil, fmt.Errorf("查询问题列表失败: %v", err)
+	}
+	issuesByTag := make(map[string][]model.IssueModel)
+	for _, issue := range issues {
+		issuesByTag[issue.TagUid] = append(issuesByTag[issue.TagUid], issue)
+	}
+
+	report := &insuranceReport{Move: move}
+	totals := make(map[string]int64)
+	unverifiedTotals := make(map[string]int64)
+	for _, tag := range tags {
+		values := make(map[string]int64)
+		if tag.DeclaredValue > 0 {
+			values[normalizeCurrency(tag.Currency)] += tag.DeclaredValue
+		}
+
+		for _, item := range itemsByTag[tag.TagUid] {
+			if item.DeclaredValue > 0 {
+				values[normalizeCurrency(item.Currency)] += item.DeclaredValue
+			}
+		}
+
+		row := insuranceRow{
+			TagNumber: numbers[tag.TagUid],
+			TagName:   tag.TagName,
+			Contents:  tagContentsText(&tag, itemsByTag[tag.TagUid]),
+			Values:    sortCurrencyTotals(values),
+			Verified:  tag.IsVerified == 1,
+			Issues:    summarizeIssues(issuesByTag[tag.TagUid]),
+		}
+		report.Rows = append(report.Rows, row)
+
+		addCurrencyTotals(totals, row.Values)
+		if !row.Verified {
+			addCurrencyTotals(unverifiedTotals, row.Values)
+		}
+	}
+	report.Totals = sortCurrencyTotals(totals)
+	report.UnverifiedTotal = sortCurrencyTotals(unverifiedTotals)
+
+	return report, nil
+}
+
+// renderInsuranceCSV 输出CSV格式清单（带UTF-8 BOM，便于Excel直接打开）
+// 多币种的标签在价值和币种列中以分号分隔
+func renderInsuranceCSV(report *insuranceReport) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("\xEF\xBB\xBF")
+	writer := csv.NewWriter(&buf)
+
+	records := [][]string{{"标签编号", "标签名称", "内容", "申报价值", "币种", "核销状态", "问题"}}
+	for _, row := range report.Rows {
+		var amounts, currencies []string
+		for _, value := range row.Values {
+			amounts = append(amounts, formatAmount(value.Amount))
+			currencies = append(currencies, value.Currency)
+		}
+		records = append(records, []string{
+			fmt.Sprintf("%d", row.TagNumber),
+			spreadsheetText(row.TagName),
+			spreadsheetText(row.Contents),
+			strings.Join(amounts, ";"),
+			strings.Join(currencies, ";"),
+			verifiedText(row.Verified),
+			spreadsheetText(row.Issues),
+		})
+	}
+
+	// 汇总行
+	for _, total := range report.Totals {
+		records = append(records, []string{"", "合计", "", formatAmount(total.Amount), total.Currency, "", ""})
+	}
+	for _, total := range report.UnverifiedTotal {
+		records = append(records, []string{"", "未核销合计", "", formatAmount(total.Amount), total.Currency, "", ""})
+	}
+
+	if err := writer.WriteAll(records); err != nil {
+		return nil, fmt.Errorf("生成CSV失败: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// renderInsurancePDF 输出A4表格形式的PDF清单
+func renderInsurancePDF(report *insuranceReport) ([]byte, error) {
+	defer metrics.ObservePDF("insurance_report", time.Now())
+	pdf := newReportPDF()
+	pdf.AddPage()
+
+	// 标题和搬运信息
+	pdf.SetFont("Alibaba", "", 16)
+	pdf.CellFormat(0, 10, "保险申报清单", "", 1, "C", false, 0, "")
+	pdf.SetFont("Alibaba", "", 10)
+	pdf.CellFormat(0, 6, fmt.Sprintf("搬运时间：%s", time.Unix(report.Move.MoveAt, 0).Format("2006-01-02 15:04")), "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 6, fmt.Sprintf("出发地：%s    目的地：%s", report.Move.StartLocation, report.Move.EndLocation), "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 6, fmt.Sprintf("标签总数：%d    已核销：%d    未核销：%d", report.Move.TagCount, report.Move.VerifiedTagCount, report.Move.UnverifiedTagCount), "", 1, "L", false, 0, "")
+	pdf.Ln(2)
+
+	// 表格列定义
+	headers := []string{"编号", "标签名称", "内容", "申报价值", "状态", "问题"}
+	widths := []float64{14, 36, 62, 30, 18, 30}
+	const lineHeight = 5.0
+	const pageBottom = 282.0 // 底部留出页码
+
+	drawHeader := func() {
+		pdf.SetFillColor(235, 235, 235)
+		for i, header := range headers {
+			pdf.CellFormat(widths[i], 7, header, "1", 0, "C", true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFillColor(255, 255, 255)
+	}
+	drawHeader()
+
+	for _, row := range report.Rows {
+		cells := []string{
+			fmt.Sprintf("%d", row.TagNumber),
+			row.TagName,
+			row.Contents,
+			formatCurrencyTotals(row.Values),
+			verifiedText(row.Verified),
+			row.Issues,
+		}
+
+		// 计算行高（按最多行数的单元格）
+		lines := 1
+		for i, cell := range cells {
+			if n := len(pdf.SplitText(cell, widths[i]-2)); n > lines {
+				lines = n
+			}
+		}
+		rowHeight := float64(lines) * lineHeight
+
+		// 分页并重复表头
+		if pdf.GetY()+rowHeight > pageBottom {
+			pdf.AddPage()
+			drawHeader()
+		}
+
+		x, y := pdf.GetXY()
+		for i, cell := range cells {
+			pdf.Rect(x, y, widths[i], rowHeight, "D")
+			pdf.SetXY(x+1, y)
+			align := "L"
+			if i == 0 || i == 4 {
+				align = "C"
+			}
+			pdf.MultiCell(widths[i]-2, lineHeight, cell, "", align, false)
+			x += widths[i]
+		}
+		pdf.SetXY(10, y+rowHeight)
+	}
+
+	// 汇总
+	if pdf.GetY()+20 > pageBottom {
+		pdf.AddPage()
+	}
+	pdf.Ln(4)
+	pdf.SetFont("Alibaba", "", 11)
+	pdf.CellFormat(0, 7, "申报价值合计："+formatCurrencyTotals(report.Totals), "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 7, "未核销标签价值合计："+formatCurrencyTotals(report.UnverifiedTotal), "", 1, "L", false, 0, "")
+	pdf.SetFont("Alibaba", "", 9)
+	pdf.CellFormat(0, 6, "生成时间："+time.Now().Format("2006-01-02 15:04:05"), "", 1, "L", false, 0, "")
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("生成PDF失败: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// summarizeIssues 生成标签问题摘要，已处理和已删除的问题不展示
+func summarizeIssues(issues []model.IssueModel) string {
+	var parts []string
+	for _, issue := range issues {
+		name := "损坏"
+		if issue.IssueType == model.IssueTypeMissing {
+			name = "丢失"
+		}
+		switch issue.Status {
+		case model.IssueStatusOpen:
+			parts = append(parts, name+"(待处理)")
+		case model.IssueStatusFoundLater:
+			parts = append(parts, name+"(已找回)")
+		}
+	}
+	return strings.Join(parts, "、")
+}
+
+// newReportPDF 创建导出报表使用的A4纵向PDF
+// 使用内置中文字体，边距10mm，关闭自动分页(表格自行分页)，每页底部带页码
+func newReportPDF() *gofpdf.Fpdf {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddUTF8Font("Alibaba", "", reportFontPath)
+	pdf.SetMargins(10, 10, 10)
+	pdf.SetAutoPageBreak(false, 10)
+	pdf.AliasNbPages("{nb}")
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-10)
+		pdf.SetFont("Alibaba", "", 8)
+		pdf.CellFormat(0, 5, fmt.Sprintf("第 %d 页 / 共 {nb} 页", pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+	return pdf
+}
+
+// tagContentsText 标签内容描述：物品按"名称×数量"列出，没有物品时使用标签备注
+func tagContentsText(tag *model.TagModel, items []model.ItemModel) string {
+	contents := make([]string, 0, len(items))
+	for _, item := range items {
+		if item.Quantity > 1 {
+			contents = append(contents, fmt.Sprintf("%s×%d", item.ItemName, item.Quantity))
+		} else {
+			contents = append(contents, item.ItemName)
+		}
+	}
+	if len(contents) == 0 {
+		return tag.Remark
+	}
+	return strings.Join(contents, "、")
+}
+
+// verifiedText 核销状态文本
+func verifiedText(verified bool) string {
+	if verified {
+		return "已核销"
+	}
+	return "未核销"
+}
+
+// normalizeCurrency 规范化币种代码，为空时使用默认币种
+func normalizeCurrency(currency string) string {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		return DefaultCurrency
+	}
+	return currency
+}
+
+// formatAmount 将以分为单位的金额格式化为两位小数
+func formatAmount(amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, amount/100, amount%100)
+}
+
+// formatCurrencyTotals 格式化多币种金额，如"1200.00 CNY + 30.00 USD"
+func formatCurrencyTotals(totals []model.CurrencyTotal) string {
+	if len(totals) == 0 {
+		return "-"
+	}
+	parts := make([]string, 0, len(totals))
+	for _, total := range totals {
+		parts = append(parts, formatAmount(total.Amount)+" "+total.Currency)
+	}
+	return strings.Join(parts, " + ")
+}
+
+// addCurrencyTotals 将金额累加到按币种索引的合计中
+func addCurrencyTotals(totals map[string]int64, values []model.CurrencyTotal) {
+	for _, value := range values {
+		totals[normalizeCurrency(value.Currency)] += value.Amount
+	}
+}
+
+// sortCurrencyTotals 将按币种索引的合计转换为按币种排序的列表，默认币种排在最前
+func sortCurrencyTotals(totals map[string]int64) []model.CurrencyTotal {
+	result := make([]model.CurrencyTotal, 0, len(totals))
+	for currency, amount := range totals {
+		result = append(result, model.CurrencyTotal{Currency: currency, Amount: amount})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Currency == DefaultCurrency || result[j].Currency == DefaultCurrency {
+			return result[i].Currency == DefaultCurrency
+		}
+		return result[i].Currency < result[j].Currency
+	})
+	return result
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"movingManager/model"
+	"movingManager/repository"
+)
+
+// RoomResponse 房间响应结构
+type RoomResponse struct {
+	RoomUid  string `json:"room_uid"`
+	MoveUid  string `json:"move_uid"`
+	Side     int    `json:"side"` // 所在位置(1-出发地,2-目的地)
+	RoomName string `json:"room_name"`
+	Color    string `json:"color"`
+	Sort     int    `json:"sort"`
+}
+
+// CreateRoomRequest 创建房间请求参数
+type CreateRoomRequest struct {
+	MoveUid  string `json:"move_uid"`  // 搬运UID
+	Side     int    `json:"side"`      // 所在位置(1-出发地,2-目的地)
+	RoomName string `json:"room_name"` // 房间名称
+	Color    string `json:"color"`     // 标识颜色
+	Sort     int    `json:"sort"`      // 排序值
+}
+
+// CreateRoom 创建房间业务处理
+func CreateRoom(userUid string, req CreateRoomRequest) (*RoomResponse, error) {
+	// 验证搬运记录是否存在且属于当前用户
+	if _, err := store.Moves().GetByUID(userUid, req.MoveUid, true); err != nil {
+		if err == repository.ErrNotFound {
+			return nil, ErrMoveNotFound
+		}
+		return nil, fmt.Errorf("查询搬运记录失败: %v", err)
+	}
+
+	room := model.RoomModel{
+		UserUid:  userUid,
+		MoveUid:  req.MoveUid,
+		Side:     req.Side,
+		RoomName: req.RoomName,
+		Color:    strings.ToUpper(req.Color),
+		Sort:     req.Sort,
+	}
+	if err := store.Rooms().Create(&room); err != nil {
+		return nil, fmt.Errorf("创建房间失败: %v", err)
+	}
+
+	return convertRoomToResponse(&room), nil
+}
+
+// UpdateRoomRequest 编辑房间请求参数
+type UpdateRoomRequest struct {
+	RoomUid  string `json:"room_uid"`  // 房间UID
+	RoomName string `json:"room_name"` // 房间名称
+	Color    string `json:"color"`     // 标识颜色
+	Sort     int    `json:"sort"`      // 排序值
+}
+
+// UpdateRoom 编辑房间业务处理
+// 房间所在位置创建后不可修改，避免已分配的标签出现出发地/目的地错位
+func UpdateRoom(userUid string, req UpdateRoomRequest) (*RoomResponse, error) {
+	room, err := store.Rooms().GetByUID(userUid, req.RoomUid, true)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, ErrRoomNotFound
+		}
+		return nil, fmt.Errorf("查询房间记录失败: %v", err)
+	}
+
+	room.RoomName = req.RoomName
+	room.Color = strings.ToUpper(req.Color)
+	room.Sort = req.Sort
+	if err := store.Rooms().Update(room); err != nil {
+		return nil, fmt.Errorf("更新房间失败: %v", err)
+	}
+
+	return convertRoomToResponse(room), nil
+}
+
+// DeleteRoom 删除房间业务处理
+// 已分配该房间的标签保留房间UID，恢复房间后标签自动重新归入
+func DeleteRoom(userUid, roomUid string, isDeleted int) error {
+	// 恢复操作时需要查找已删除记录（isDeleted=0表示恢复）
+	onlyUndeleted := isDeleted != 0
+	room, err := store.Rooms().GetByUID(userUid, roomUid, onlyUndeleted)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return ErrRoomNotFound
+		}
+		return fmt.Errorf("查询房间记录失败: %v", err)
+	}
+
+	room.IsDeleted = isDeleted
+	if isDeleted == 1 {
+		room.DeletedAt = time.Now().Unix()
+	}
+	return store.Rooms().Update(room)
+}
+
+// GetRoomList 获取搬运下的房间列表业务处理
+func GetRoomList(userUid, moveUid string, side int) ([]RoomResponse, error) {
+	if _, err := store.Moves().GetByUID(userUid, moveUid, true); err != nil {
+		if err == repository.ErrNotFound {
+			return nil, ErrMoveNotFound
+		}
+		return nil, fmt.Errorf("查询搬运记录失败: %v", err)
+	}
+
+	rooms, err := store.Rooms().ListByMove(userUid, moveUid, side)
+	if err != nil {
+		return nil, fmt.Errorf("查询房间列表失败: %v", err)
+	}
+
+	responses := make([]RoomResponse, 0, len(rooms))
+	for _, room := range rooms {
+		responses = append(responses, *convertRoomToResponse(&room))
+	}
+	return responses, nil
+}
+
+// validateTagRooms 事务中校验标签分配的房间
+// 房间必须属于同一搬运，且出发地/目的地位置与字段一致；空UID表示不分配
+func validateTagRooms(s repository.Store, userUid, moveUid, originRoomUid, destRoomUid string) (map[string]model.RoomModel, error) {
+	rooms := make(map[string]model.RoomModel)
+	check := func(roomUid string, side int) error {
+		if roomUid == "" {
+			return nil
+		}
+		room, err := s.Rooms().GetByUID(userUid, roomUid, true)
+		if err != nil {
+			if err == repository.ErrNotFound {
+				return ErrRoomNotFound
+			}
+			return fmt.Errorf("查询房间记录失败: %v", err)
+		}
+		if room.MoveUid != moveUid {
+			return validationError("房间不属于该搬运")
+		}
+		if room.Side != side {
+			if side == model.RoomSideOrigin {
+				return validationError("出发地房间不能选择目的地房间")
+			}
+			return validationError("目的地房间不能选择出发地房间")
+		}
+		rooms[room.RoomUid] = *room
+		return nil
+	}
+
+	if err := check(originRoomUid, model.RoomSideOrigin); err != nil {
+		return nil, err
+	}
+	if err := check(destRoomUid, model.RoomSideDestination); err != nil {
+		return nil, err
+	}
+	return rooms, nil
+}
+
+// fillTagRoomInfo 根据房间映射补全标签响应中的房间名称和颜色
+func fillTagRoomInfo(resp *TagResponse, rooms map[string]model.RoomModel) {
+	if room, ok := rooms[resp.OriginRoomUid]; ok {
+		resp.OriginRoomName = room.RoomName
+	}
+	if room, ok := rooms[resp.DestRoomUid]; ok {
+		resp.DestRoomName = room.RoomName
+		resp.DestRoomColor = room.Color
+	}
+}
+
+// convertRoomToResponse 将模型转换为响应格式
+func convertRoomToResponse(room *model.RoomModel) *RoomResponse {
+	return &RoomResponse{
+		RoomUid:  room.RoomUid,
+		MoveUid:  room.MoveUid,
+		Side:     room.Side,
+		RoomName: room.RoomName,
+		Color:    room.Color,
+		Sort:     room.Sort,
+	}
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"movingManager/model"
+)
+
+func TestRoomLifecycle(t *testing.T) {
+	forEachStore(t, func(t *testing.T) {
+		move := newTestMove(t)
+
+		kitchen, err := CreateRoom(testUserUid, CreateRoomRequest{MoveUid: move.MoveUid, Side: model.RoomSideDestination, RoomName: "厨房", Color: "#ff0000", Sort: 2})
+		if err != nil {
+			t.Fatalf("CreateRoom() error = %v", err)
+		}
+		if kitchen.Color != "#FF0000" {
+			t.Errorf("颜色 = %s, want #FF0000", kitchen.Color)
+		}
+		if _, err := CreateRoom(testUserUid, CreateRoomRequest{MoveUid: move.MoveUid, Side: model.RoomSideDestination, RoomName: "客厅", Sort: 1}); err != nil {
+			t.Fatalf("CreateRoom() error = %v", err)
+		}
+		if _, err := CreateRoom(testUserUid, CreateRoomRequest{MoveUid: move.MoveUid, Side: model.RoomSideOrigin, RoomName: "旧书房"}); err != nil {
+			t.Fatalf("CreateRoom() error = %v", err)
+		}
+		if _, err := CreateRoom(otherUserUid, CreateRoomRequest{MoveUid: move.MoveUid, Side: model.RoomSideOrigin, RoomName: "x"}); !errors.Is(err, ErrMoveNotFound) {
+			t.Errorf("其他用户创建房间 error = %v, want ErrMoveNotFound", err)
+		}
+
+		// 按位置、排序值排列
+		rooms, err := GetRoomList(testUserUid, move.MoveUid, 0)
+		if err != nil {
+			t.Fatalf("GetRoomList() error = %v", err)
+		}
+		var names []string
+		for _, room := range rooms {
+			names = append(names, room.RoomName)
+		}
+		if len(names) != 3 || names[0] != "旧书房" || names[1] != "客厅" || names[2] != "厨房" {
+			t.Errorf("房间顺序 = %v, want [旧书房 客厅 厨房]", names)
+		}
+		if rooms, _ := GetRoomList(testUserUid, move.MoveUid, model.RoomSideOrigin); len(rooms) != 1 {
+			t.Errorf("出发地房间数 = %d, want 1", len(rooms))
+		}
+
+		if _, err := UpdateRoom(otherUserUid, UpdateRoomRequest{RoomUid: kitchen.RoomUid, RoomName: "x"}); !errors.Is(err, ErrRoomNotFound) {
+			t.Errorf("其他用户编辑房间 error = %v, want ErrRoomNotFound", err)
+		}
+		updated, err := UpdateRoom(testUserUid, UpdateRoomRequest{RoomUid: kitchen.RoomUid, RoomName: "新厨房", Sort: 3})
+		if err != nil || updated.RoomName != "新厨房" || updated.Side != model.RoomSideDestination {
+			t.Fatalf("UpdateRoom() = %+v, %v", updated, err)
+		}
+
+		// 删除后不在列表中，可以恢复
+		if err := DeleteRoom(testUserUid, kitchen.RoomUid, 1); err != nil {
+			t.Fatalf("DeleteRoom() error = %v", err)
+		}
+		if rooms, _ := GetRoomList(testUserUid, move.MoveUid, 0); len(rooms) != 2 {
+			t.Errorf("删除后房间数 = %d, want 2", len(rooms))
+		}
+		if err := DeleteRoom(testUserUid, kitchen.RoomUid, 1); !errors.Is(err, ErrRoomNotFound) {
+			t.Errorf("重复删除 error = %v, want ErrRoomNotFound", err)
+		}
+		if err := DeleteRoom(testUserUid, kitchen.RoomUid, 0); err != nil {
+			t.Fatalf("恢复房间 error = %v", err)
+		}
+		if rooms, _ := GetRoomList(testUserUid, move.MoveUid, 0); len(rooms) != 3 {
+			t.Errorf("恢复后房间数 = %d, want 3", len(rooms))
+		}
+	})
+}
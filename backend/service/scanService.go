@@ -0,0 +1,247 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"movingManager/config"
+	"movingManager/database"
+	"movingManager/event"
+	"movingManager/metrics"
+	"movingManager/model"
+	"movingManager/repository"
+)
+
+// 扫码链接默认配置（配置缺省时使用）
+const (
+	defaultScanBaseURL     = "http://192.168.2.17:5173"
+	defaultScanLinkTTLDays = 180
+)
+
+// PublicTagResponse 免登录扫码可见的标签信息
+// 仅包含搬家工人分拣和核销所需的字段
+type PublicTagResponse struct {
+	TagNumber            int    `json:"tag_number"`
+	TagName              string `json:"tag_name"`
+	DestRoomName         string `json:"dest_room_name"`
+	DestRoomColor        string `json:"dest_room_color"`
+	IsVerified           int    `json:"is_verified"`
+	VerifiedBy           string `json:"verified_by"`
+	VerifiedAt           string `json:"verified_at"`
+	AllowAnonymousVerify int    `json:"allow_anonymous_verify"`
+}
+
+// ScanLinkResponse 标签扫码链接
+type ScanLinkResponse struct {
+	TagUid    string `json:"tag_uid"`
+	ScanURL   string `json:"scan_url"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+// SignScanToken 生成标签扫码令牌
+// 格式为 base64url(tagUid:过期时间戳).base64url(HMAC-SHA256签名)
+func SignScanToken(tagUid string, expiresAt time.Time) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(tagUid + ":" + strconv.FormatInt(expiresAt.Unix(), 10)))
+	return payload + "." + signScanPayload(payload)
+}
+
+// ParseScanToken 校验扫码令牌签名和有效期，返回标签UID
+func ParseScanToken(token string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return "", ErrScanLinkInvalid
+	}
+	if !hmac.Equal([]byte(parts[1]), []byte(signScanPayload(parts[0]))) {
+		return "", ErrScanLinkInvalid
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return "", ErrScanLinkInvalid
+	}
+	idx := strings.LastIndex(string(payload), ":")
+	if idx <= 0 {
+		return "", ErrScanLinkInvalid
+	}
+	expiresAt, err := strconv.ParseInt(string(payload[idx+1:]), 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return "", ErrScanLinkInvalid
+	}
+	return string(payload[:idx]), nil
+}
+
+// BuildTagScanURL 生成标签二维码中的扫码链接
+func BuildTagScanURL(tagUid string) string {
+	token := SignScanToken(tagUid, time.Now().Add(scanLinkTTL()))
+	return fmt.Sprintf("%s/scan/%s", scanBaseURL(), token)
+}
+
+// GetTagScanLink 获取标签扫码链接业务处理
+func GetTagScanLink(userUid, tagUid string) (*ScanLinkResponse, error) {
+	var tag model.TagModel
+	if err := tag.GetByUID(userUid, tagUid, true); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrTagNotFound
+		}
+		return nil, fmt.Errorf("查询标签记录失败: %v", err)
+	}
+
+	expiresAt := time.Now().Add(scanLinkTTL())
+	return &ScanLinkResponse{
+		TagUid:    tag.TagUid,
+		ScanURL:   fmt.Sprintf("%s/scan/%s", scanBaseURL(), SignScanToken(tag.TagUid, expiresAt)),
+		ExpiresAt: expiresAt.Format("2006-01-02 15:04:05"),
+	}, nil
+}
+
+// GetPublicTagInfo 免登录扫码查看标签信息业务处理
+func GetPublicTagInfo(token string) (*PublicTagResponse, error) {
+	tagUid, err := ParseScanToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	var tag model.TagModel
+	if err := tag.GetUndeletedByTagUidTx(database.DB, tagUid); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrScanLinkInvalid
+		}
+		return nil, fmt.Errorf("查询标签记录失败: %v", err)
+	}
+
+	tagModel := model.TagModel{}
+	move, err := tagModel.GetMoveByUserAndMoveUidTx(database.DB, tag.UserUid, tag.MoveUid, true)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrScanLinkInvalid
+		}
+		return nil, fmt.Errorf("查询搬运记录失败: %v", err)
+	}
+
+	return buildPublicTagResponse(&tag, move)
+}
+
+// PublicVerifyTag 免登录扫码核销标签业务处理
+// 仅在搬运开启免登录核销时允许，记录核销人姓名
+func PublicVerifyTag(token, scannerName string) (*PublicTagResponse, error) {
+	tagUid, err := ParseScanToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	var tag model.TagModel
+	var move *model.MoveModel
+	var events []event.Event
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tag.GetUndeletedByTagUidTx(tx, tagUid); err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return ErrScanLinkInvalid
+			}
+			return fmt.Errorf("查询标签记录失败: %v", err)
+		}
+
+		tagModel := model.TagModel{}
+		move, err = tagModel.GetMoveByUserAndMoveUidTx(tx, tag.UserUid, tag.MoveUid, true)
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return ErrScanLinkInvalid
+			}
+			return fmt.Errorf("查询搬运记录失败: %v", err)
+		}
+		if move.AllowAnonymousVerify != 1 {
+			return ErrAnonymousVerifyDisabled
+		}
+
+		s := repository.NewGormStore(tx)
+		changed, err := verifyTagInStore(s, move, &tag, 1, false, scannerName)
+		if err != nil {
+			return err
+		}
+		if events, err = recordTagEvents(s, event.TypeTagVerified, changed); err != nil {
+			return err
+		}
+		return tag.GetUndeletedByTagUidTx(tx, tagUid)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	metrics.AddScans(metrics.ScanSourcePublic, 1)
+	publishEvents(events)
+
+	return buildPublicTagResponse(&tag, move)
+}
+
+// buildPublicTagResponse 组装免登录扫码可见的标签信息
+func buildPublicTagResponse(tag *model.TagModel, move *model.MoveModel) (*PublicTagResponse, error) {
+	var tagModel model.TagModel
+	tags, err := tagModel.GetTagsByMove(tag.UserUid, tag.MoveUid, true)
+	if err != nil {
+		return nil, fmt.Errorf("查询标签列表失败: %v", err)
+	}
+
+	response := &PublicTagResponse{
+		TagNumber:            buildTagNumbers(tags)[tag.TagUid],
+		TagName:              tag.TagName,
+		IsVerified:           tag.IsVerified,
+		VerifiedBy:           tag.VerifiedBy,
+		VerifiedAt:           formatUnixTime(tag.VerifiedAt),
+		AllowAnonymousVerify: move.AllowAnonymousVerify,
+	}
+	if tag.DestRoomUid != "" {
+		var room model.RoomModel
+		if err := room.GetByUID(tag.UserUid, tag.DestRoomUid, true); err == nil {
+			response.DestRoomName = room.RoomName
+			response.DestRoomColor = room.Color
+		} else if err != gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("查询房间记录失败: %v", err)
+		}
+	}
+	return response, nil
+}
+
+// signScanPayload 计算扫码令牌载荷的签名
+func signScanPayload(payload string) string {
+	mac := hmac.New(sha256.New, scanSigningKey())
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// scanSigningKey 获取扫码链接签名密钥
+func scanSigningKey() []byte {
+	return []byte(config.AppConfig.Scan.Secret)
+}
+
+// ValidateScanConfig 校验扫码链接配置，启动时调用
+// 签名密钥必须配置：随机密钥在重启后会使已打印的二维码全部失效，多实例部署时也无法互相校验
+func ValidateScanConfig() error {
+	if config.AppConfig.Scan.Secret == "" {
+		return fmt.Errorf("未配置scan.secret，请在配置文件中设置扫码链接签名密钥(建议32位以上随机字符串)")
+	}
+	return nil
+}
+
+// scanBaseURL 前端访问地址
+func scanBaseURL() string {
+	baseURL := config.AppConfig.Scan.BaseURL
+	if baseURL == "" {
+		baseURL = defaultScanBaseURL
+	}
+	return strings.TrimRight(baseURL, "/")
+}
+
+// scanLinkTTL 扫码链接有效期
+func scanLinkTTL() time.Duration {
+	days := config.AppConfig.Scan.LinkTTLDays
+	if days <= 0 {
+		days = defaultScanLinkTTLDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
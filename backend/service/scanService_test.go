@@ -0,0 +1,71 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"movingManager/config"
+)
+
+// setTestScanSecret 设置测试使用的扫码签名密钥，测试结束后恢复
+func setTestScanSecret(t *testing.T, secret string) {
+	t.Helper()
+	previous := config.AppConfig.Scan.Secret
+	config.AppConfig.Scan.Secret = secret
+	t.Cleanup(func() { config.AppConfig.Scan.Secret = previous })
+}
+
+func TestScanToken(t *testing.T) {
+	setTestScanSecret(t, "test-scan-secret")
+	tagUid := "44444444-4444-4444-4444-444444444444"
+	valid := SignScanToken(tagUid, time.Now().Add(time.Hour))
+	payload, signature, _ := strings.Cut(valid, ".")
+	otherPayload, _, _ := strings.Cut(SignScanToken(otherUserUid, time.Now().Add(time.Hour)), ".")
+
+	// 使用其他密钥签名的令牌
+	config.AppConfig.Scan.Secret = "another-secret"
+	foreign := SignScanToken(tagUid, time.Now().Add(time.Hour))
+	config.AppConfig.Scan.Secret = "test-scan-secret"
+
+	cases := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{"有效令牌", valid, false},
+		{"已过期", SignScanToken(tagUid, time.Now().Add(-time.Second)), true},
+		{"篡改载荷", otherPayload + "." + signature, true},
+		{"篡改签名", payload + "." + strings.Repeat("A", len(signature)), true},
+		{"缺少签名", payload, true},
+		{"多余分段", valid + ".x", true},
+		{"空令牌", "", true},
+		{"其他密钥签名", foreign, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseScanToken(tc.token)
+			if tc.wantErr {
+				if !errors.Is(err, ErrScanLinkInvalid) {
+					t.Errorf("ParseScanToken() error = %v, want ErrScanLinkInvalid", err)
+				}
+				return
+			}
+			if err != nil || got != tagUid {
+				t.Errorf("ParseScanToken() = %q, %v, want %q", got, err, tagUid)
+			}
+		})
+	}
+}
+
+func TestValidateScanConfig(t *testing.T) {
+	setTestScanSecret(t, "")
+	if err := ValidateScanConfig(); err == nil {
+		t.Error("未配置签名密钥时 ValidateScanConfig() 未返回错误")
+	}
+	setTestScanSecret(t, "configured")
+	if err := ValidateScanConfig(); err != nil {
+		t.Errorf("ValidateScanConfig() error = %v", err)
+	}
+}
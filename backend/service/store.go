@@ -0,0 +1,18 @@
+package service
+
+import (
+	"movingManager/repository"
+)
+
+// store 服务层使用的数据仓储
+// 由 main 注入GORM实现，单元测试中注入内存实现
+//
+// 已通过仓储访问数据的服务：搬运、标签(含嵌套)、房间、物品、用户，以及标签和搬运事件的发布。
+// 扫码、离线同步、导入、导出、报表、备份恢复、附件、问题、交接和Webhook管理仍直接调用模型方法，
+// 其测试需要通过 useTestDB 使用SQLite数据库
+var store repository.Store
+
+// SetStore 设置服务层使用的数据仓储
+func SetStore(s repository.Store) {
+	store = s
+}
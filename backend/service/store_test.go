@@ -0,0 +1,120 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"movingManager/database"
+	"movingManager/migrate"
+	"movingManager/model"
+	"movingManager/repository"
+)
+
+const (
+	testUserUid  = "11111111-1111-1111-1111-111111111111"
+	otherUserUid = "22222222-2222-2222-2222-222222222222"
+)
+
+// testStoreFactories 服务测试使用的仓储，每个测试在每种仓储上各运行一次
+// 设置 TEST_POSTGRES_DSN 时额外在PostgreSQL上运行
+var testStoreFactories = []struct {
+	name string
+	open func(t *testing.T) repository.Store
+}{
+	{"memory", func(t *testing.T) repository.Store { return repository.NewMemoryStore() }},
+	{"sqlite", openSQLiteTestStore},
+	{"postgres", openPostgresTestStore},
+}
+
+// forEachStore 依次注入每种仓储运行测试，测试结束后恢复
+func forEachStore(t *testing.T, fn func(t *testing.T)) {
+	t.Helper()
+	for _, factory := range testStoreFactories {
+		t.Run(factory.name, func(t *testing.T) {
+			previous := store
+			SetStore(factory.open(t))
+			t.Cleanup(func() { SetStore(previous) })
+			fn(t)
+		})
+	}
+}
+
+// openSQLiteTestStore 在临时目录中创建SQLite数据库
+func openSQLiteTestStore(t *testing.T) repository.Store {
+	cfg := &database.Config{Driver: database.DriverSQLite}
+	cfg.SQLite.Path = filepath.Join(t.TempDir(), "test.db")
+	return openTestStore(t, cfg)
+}
+
+// openPostgresTestStore 连接 TEST_POSTGRES_DSN 指定的PostgreSQL数据库并清空测试数据
+func openPostgresTestStore(t *testing.T) repository.Store {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("未设置 TEST_POSTGRES_DSN，跳过PostgreSQL测试")
+	}
+	db, err := gorm.Open(database.PostgresDialector(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("连接PostgreSQL失败: %v", err)
+	}
+	s := migrateTestDB(t, db)
+	for _, m := range []interface{}{&model.UserModel{}, &model.MoveModel{}, &model.TagModel{}, &model.RoomModel{}} {
+		if err := db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(m).Error; err != nil {
+			t.Fatalf("清空测试数据失败: %v", err)
+		}
+	}
+	return s
+}
+
+// openTestStore 按配置打开数据库并迁移表结构
+func openTestStore(t *testing.T, cfg *database.Config) repository.Store {
+	db, err := database.Open(cfg)
+	if err != nil {
+		t.Fatalf("database.Open() error = %v", err)
+	}
+	return migrateTestDB(t, db.Session(&gorm.Session{Logger: logger.Default.LogMode(logger.Silent)}))
+}
+
+// migrateTestDB 迁移表结构，测试结束后关闭连接
+func migrateTestDB(t *testing.T, db *gorm.DB) repository.Store {
+	t.Helper()
+	if _, err := migrate.Up(db); err != nil {
+		t.Fatalf("migrate.Up() error = %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return repository.NewGormStore(db)
+}
+
+// useTestDB 将 database.DB 替换为临时SQLite数据库，测试结束后恢复
+// 用于尚未迁移到仓储的服务(见 store 的说明)
+func useTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	cfg := &database.Config{Driver: database.DriverSQLite}
+	cfg.SQLite.Path = filepath.Join(t.TempDir(), "test.db")
+	db, err := database.Open(cfg)
+	if err != nil {
+		t.Fatalf("database.Open() error = %v", err)
+	}
+	db = db.Session(&gorm.Session{Logger: logger.Default.LogMode(logger.Silent)})
+	migrateTestDB(t, db)
+	previous := database.DB
+	database.DB = db
+	t.Cleanup(func() { database.DB = previous })
+	return db
+}
+
+// addTestRoom 通过当前仓储创建房间
+func addTestRoom(t *testing.T, room model.RoomModel) model.RoomModel {
+	t.Helper()
+	if err := store.Rooms().Create(&room); err != nil {
+		t.Fatalf("创建房间失败: %v", err)
+	}
+	return room
+}
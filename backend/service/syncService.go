@@ -0,0 +1,256 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"movingManager/database"
+	"movingManager/event"
+	"movingManager/metrics"
+	"movingManager/model"
+	"movingManager/repository"
+)
+
+// maxClientClockSkew 允许的客户端时钟超前量，超出时按服务器时间处理
+const maxClientClockSkew = 5 * time.Minute
+
+// 扫码事件处理结果描述
+var scanEventStatusText = map[int]string{
+	model.ScanEventApplied:  "applied",
+	model.ScanEventStale:    "stale",
+	model.ScanEventRejected: "rejected",
+}
+
+// SyncScanEvent 离线扫码事件
+type SyncScanEvent struct {
+	EventUid     string // 客户端生成的事件唯一标识
+	TagUid       string // 标签UID
+	IsVerified   int    // 扫码设置的核销状态(0-未核销,1-已核销)
+	StateVersion int64  // 客户端扫码时看到的标签核销状态版本号
+	ClientAt     int64  // 客户端扫码时间戳(毫秒)，仅用于记录核销时间
+}
+
+// SyncEventResult 单个扫码事件的处理结果
+type SyncEventResult struct {
+	EventUid  string `json:"event_uid"`
+	TagUid    string `json:"tag_uid"`
+	Status    string `json:"status"`    // 处理结果(applied,stale,rejected)
+	Duplicate bool   `json:"duplicate"` // 是否为已处理过的重复事件
+	Message   string `json:"message"`
+}
+
+// SyncTagState 同步后的标签状态
+type SyncTagState struct {
+	TagUid         string `json:"tag_uid"`
+	MoveUid        string `json:"move_uid"`
+	IsVerified     int    `json:"is_verified"`
+	VerifiedAt     string `json:"verified_at"`
+	StateChangedAt int64  `json:"state_changed_at"` // 核销状态最后变更时间戳(毫秒)
+	StateVersion   int64  `json:"state_version"`    // 核销状态版本号，后续离线扫码以此为基准
+}
+
+// SyncResponse 离线扫码同步结果
+type SyncResponse struct {
+	Results []SyncEventResult `json:"results"` // 与请求中的事件顺序一致
+	Tags    []SyncTagState    `json:"tags"`
+}
+
+// SyncScanEvents 离线扫码批量同步业务处理
+// 事件按请求中的顺序依次应用，冲突按标签核销状态版本号判断，不比较客户端与服务器的时钟：
+// 扫码后标签被其他操作修改过的事件不应用(stale)，同一批次中同一标签的后续事件以前一个已应用事件的结果为基准。
+// 已处理过的事件UID直接返回原结果，保证客户端重试时幂等
+func SyncScanEvents(userUid string, events []SyncScanEvent) (*SyncResponse, error) {
+	eventUids := make([]string, 0, len(events))
+	for _, scan := range events {
+		eventUids = append(eventUids, scan.EventUid)
+	}
+
+	var changed []model.TagModel
+	var tagEvents []event.Event
+	applied := 0
+	response := &SyncResponse{
+		Results: make([]SyncEventResult, 0, len(events)),
+		Tags:    []SyncTagState{},
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var eventModel model.ScanEventModel
+		processed, err := eventModel.MapByEventUidsTx(tx, userUid, eventUids)
+		if err != nil {
+			return fmt.Errorf("查询扫码事件失败: %v", err)
+		}
+
+		var touched []string
+		touchedSet := make(map[string]bool)
+		batchVersions := make(map[string]int64) // 本批次已应用事件产生的标签版本号
+		maxClientAt := time.Now().Add(maxClientClockSkew).UnixMilli()
+
+		for _, scan := range events {
+			if !touchedSet[scan.TagUid] {
+				touchedSet[scan.TagUid] = true
+				touched = append(touched, scan.TagUid)
+			}
+
+			// 已处理过的事件（含同一批次内重复的事件）直接返回原结果
+			if record, ok := processed[scan.EventUid]; ok {
+				response.Results = append(response.Results, duplicateSyncResult(record))
+				continue
+			}
+
+			if scan.ClientAt > maxClientAt {
+				scan.ClientAt = time.Now().UnixMilli()
+			}
+			if version, ok := batchVersions[scan.TagUid]; ok && version > scan.StateVersion {
+				scan.StateVersion = version
+			}
+
+			// 先写入事件记录占用事件UID，并发请求已处理同一事件时返回其结果
+			record := &model.ScanEventModel{
+				EventUid:   scan.EventUid,
+				UserUid:    userUid,
+				TagUid:     scan.TagUid,
+				IsVerified: scan.IsVerified,
+				ClientAt:   scan.ClientAt,
+			}
+			claimed, err := record.ClaimTx(tx)
+			if err != nil {
+				return fmt.Errorf("保存扫码事件失败: %v", err)
+			}
+			if !claimed {
+				var existing model.ScanEventModel
+				if err := existing.GetByEventUidTx(tx, userUid, scan.EventUid); err != nil {
+					return fmt.Errorf("查询扫码事件失败: %v", err)
+				}
+				processed[existing.EventUid] = existing
+				response.Results = append(response.Results, duplicateSyncResult(existing))
+				continue
+			}
+
+			tag, tagChanged, err := applyScanEventTx(tx, userUid, scan, record)
+			if err != nil {
+				return err
+			}
+			if err := record.UpdateResultTx(tx); err != nil {
+				return fmt.Errorf("保存扫码事件失败: %v", err)
+			}
+			if record.Result == model.ScanEventApplied {
+				batchVersions[scan.TagUid] = tag.StateVersion
+			}
+			if tagChanged {
+				changed = append(changed, *tag)
+			}
+			processed[record.EventUid] = *record
+			applied++
+
+			response.Results = append(response.Results, SyncEventResult{
+				EventUid: record.EventUid,
+				TagUid:   record.TagUid,
+				Status:   scanEventStatusText[record.Result],
+				Message:  record.Message,
+			})
+		}
+
+		// 返回涉及标签的最终状态
+		for _, tagUid := range touched {
+			var tag model.TagModel
+			if err := tag.GetByUserAndTagUidTx(tx, userUid, tagUid, true); err != nil {
+				if err == gorm.ErrRecordNotFound {
+					continue
+				}
+				return fmt.Errorf("查询标签失败: %v", err)
+			}
+			response.Tags = append(response.Tags, SyncTagState{
+				TagUid:         tag.TagUid,
+				MoveUid:        tag.MoveUid,
+				IsVerified:     tag.IsVerified,
+				VerifiedAt:     formatUnixTime(tag.VerifiedAt),
+				StateChangedAt: tag.StateChangedAt,
+				StateVersion:   tag.StateVersion,
+			})
+		}
+
+		tagEvents, err = recordTagEvents(repository.NewGormStore(tx), event.TypeTagVerified, changed)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	metrics.AddScans(metrics.ScanSourceSync, applied)
+	publishEvents(tagEvents)
+	return response, nil
+}
+
+// duplicateSyncResult 已处理过的事件返回保存的结果
+func duplicateSyncResult(record model.ScanEventModel) SyncEventResult {
+	return SyncEventResult{
+		EventUid:  record.EventUid,
+		TagUid:    record.TagUid,
+		Status:    scanEventStatusText[record.Result],
+		Duplicate: true,
+		Message:   record.Message,
+	}
+}
+
+// applyScanEventTx 事务中应用单个扫码事件，将处理结果写入 record
+// 返回应用后的标签及其核销状态是否发生变更；仅当标签的状态版本号与事件携带的一致时才应用，
+// 标签或搬运记录已删除时拒绝该事件
+func applyScanEventTx(tx *gorm.DB, userUid string, scan SyncScanEvent, record *model.ScanEventModel) (*model.TagModel, bool, error) {
+	// 锁定标签行，并发同步同一标签时后执行的一方读取到已更新的版本号
+	var tag model.TagModel
+	if err := tag.LockTx(tx, userUid, scan.TagUid); err != nil {
+		return nil, false, fmt.Errorf("锁定标签失败: %v", err)
+	}
+	if err := tag.GetByUserAndTagUidTx(tx, userUid, scan.TagUid, true); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			record.Result = model.ScanEventRejected
+			record.Message = "用户无此标签记录"
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("查询标签失败: %v", err)
+	}
+	record.MoveUid = tag.MoveUid
+
+	if scan.StateVersion > tag.StateVersion {
+		record.Result = model.ScanEventRejected
+		record.Message = "标签状态版本号无效"
+		return &tag, false, nil
+	}
+	if scan.StateVersion < tag.StateVersion {
+		record.Result = model.ScanEventStale
+		record.Message = "标签状态已被其他操作更新"
+		return &tag, false, nil
+	}
+
+	tagModel := model.TagModel{}
+	move, err := tagModel.GetMoveByUserAndMoveUidTx(tx, userUid, tag.MoveUid, true)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			record.Result = model.ScanEventRejected
+			record.Message = "搬运记录已删除"
+			return &tag, false, nil
+		}
+		return nil, false, fmt.Errorf("查询搬运记录失败: %v", err)
+	}
+
+	changed, err := verifyTagInStore(repository.NewGormStore(tx), move, &tag, scan.IsVerified, false, "")
+	if err != nil {
+		return nil, false, err
+	}
+	record.Result = model.ScanEventApplied
+	if len(changed) == 0 {
+		return &tag, false, nil
+	}
+
+	// 核销时间使用客户端扫码时间
+	updated := changed[0]
+	if scan.IsVerified == 1 {
+		updated.VerifiedAt = scan.ClientAt / 1000
+		if err := updated.UpdateTx(tx); err != nil {
+			return nil, false, fmt.Errorf("更新标签核销时间失败: %v", err)
+		}
+	}
+	return &updated, true, nil
+}
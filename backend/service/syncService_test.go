@@ -0,0 +1,166 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"movingManager/database"
+	"movingManager/model"
+	"movingManager/repository"
+)
+
+// TestSyncScanEventsDeletedMove 已删除搬运下的事件单独拒绝，不影响同批次其他事件
+func TestSyncScanEventsDeletedMove(t *testing.T) {
+	useTestDB(t)
+
+	active := model.MoveModel{MoveUid: "move-active", UserUid: testUserUid, TagCount: 1, UnverifiedTagCount: 1}
+	deleted := model.MoveModel{MoveUid: "move-deleted", UserUid: testUserUid, TagCount: 1, UnverifiedTagCount: 1}
+	deleted.IsDeleted = 1
+	activeTag := model.TagModel{TagUid: "33333333-3333-3333-3333-333333333333", UserUid: testUserUid, MoveUid: active.MoveUid, TagName: "厨房1"}
+	deletedTag := model.TagModel{TagUid: "44444444-4444-4444-4444-444444444444", UserUid: testUserUid, MoveUid: deleted.MoveUid, TagName: "书房1"}
+	for _, create := range []func() error{active.Create, deleted.Create, activeTag.Create, deletedTag.Create} {
+		if err := create(); err != nil {
+			t.Fatalf("创建测试数据失败: %v", err)
+		}
+	}
+
+	now := time.Now().UnixMilli()
+	events := []SyncScanEvent{
+		{EventUid: "event-1", TagUid: deletedTag.TagUid, IsVerified: 1, ClientAt: now - 2000},
+		{EventUid: "event-2", TagUid: activeTag.TagUid, IsVerified: 1, ClientAt: now - 1000},
+	}
+	response, err := SyncScanEvents(testUserUid, events)
+	if err != nil {
+		t.Fatalf("SyncScanEvents() error = %v", err)
+	}
+
+	want := map[string]string{"event-1": "rejected", "event-2": "applied"}
+	if len(response.Results) != len(want) {
+		t.Fatalf("处理结果 = %+v, want %d 条", response.Results, len(want))
+	}
+	for _, result := range response.Results {
+		if result.Status != want[result.EventUid] {
+			t.Errorf("事件 %s 结果 = %s (%s), want %s", result.EventUid, result.Status, result.Message, want[result.EventUid])
+		}
+	}
+
+	var tag model.TagModel
+	if err := tag.GetByUID(testUserUid, activeTag.TagUid, true); err != nil {
+		t.Fatalf("查询标签失败: %v", err)
+	}
+	if tag.IsVerified != 1 {
+		t.Errorf("有效事件的标签核销状态 = %d, want 1", tag.IsVerified)
+	}
+
+	// 重试时返回保存的拒绝结果
+	response, err = SyncScanEvents(testUserUid, events[:1])
+	if err != nil {
+		t.Fatalf("重试 SyncScanEvents() error = %v", err)
+	}
+	if result := response.Results[0]; !result.Duplicate || result.Status != "rejected" {
+		t.Errorf("重试结果 = %+v, want 重复的 rejected", result)
+	}
+}
+
+// useSyncTestDB 使用临时SQLite数据库，仓储与 database.DB 指向同一数据库
+func useSyncTestDB(t *testing.T) {
+	t.Helper()
+	previous := store
+	SetStore(repository.NewGormStore(useTestDB(t)))
+	t.Cleanup(func() { SetStore(previous) })
+}
+
+// syncStatuses 按结果顺序提取事件UID和处理结果
+func syncStatuses(response *SyncResponse) []string {
+	statuses := make([]string, 0, len(response.Results))
+	for _, result := range response.Results {
+		statuses = append(statuses, result.EventUid+":"+result.Status)
+	}
+	return statuses
+}
+
+// TestSyncScanEventsVersionConflict 冲突按标签状态版本号判断，结果与请求顺序一致
+func TestSyncScanEventsVersionConflict(t *testing.T) {
+	useSyncTestDB(t)
+	move := newTestMove(t)
+	first := newTestTag(t, move.MoveUid, "一", "")
+	second := newTestTag(t, move.MoveUid, "二", "")
+
+	// 离线扫码后，第二个标签被在线操作核销
+	if _, err := VerifyTag(testUserUid, second.TagUid, 1, false); err != nil {
+		t.Fatalf("VerifyTag() error = %v", err)
+	}
+
+	// 客户端时钟远早于服务器不影响结果；同一标签先核销再取消核销，以后一个为准
+	now := time.Now().UnixMilli()
+	events := []SyncScanEvent{
+		{EventUid: "b", TagUid: first.TagUid, IsVerified: 1, StateVersion: 0, ClientAt: now - 86400000},
+		{EventUid: "a", TagUid: second.TagUid, IsVerified: 0, StateVersion: 0, ClientAt: now},
+		{EventUid: "c", TagUid: first.TagUid, IsVerified: 0, StateVersion: 0, ClientAt: now - 86400000},
+		{EventUid: "d", TagUid: first.TagUid, IsVerified: 1, StateVersion: 5, ClientAt: now},
+	}
+	response, err := SyncScanEvents(testUserUid, events)
+	if err != nil {
+		t.Fatalf("SyncScanEvents() error = %v", err)
+	}
+	want := []string{"b:applied", "a:stale", "c:applied", "d:rejected"}
+	if got := syncStatuses(response); !reflect.DeepEqual(got, want) {
+		t.Errorf("处理结果 = %v, want %v", got, want)
+	}
+
+	states := make(map[string]SyncTagState)
+	for _, state := range response.Tags {
+		states[state.TagUid] = state
+	}
+	if state := states[first.TagUid]; state.IsVerified != 0 || state.StateVersion != 2 {
+		t.Errorf("标签一状态 = %+v, want 未核销 版本2", state)
+	}
+	if state := states[second.TagUid]; state.IsVerified != 1 || state.StateVersion != 1 {
+		t.Errorf("标签二状态 = %+v, want 已核销 版本1", state)
+	}
+	assertMoveCounts(t, move.MoveUid, 2, 1, 1, 0)
+
+	// 使用返回的版本号再次同步时应用
+	response, err = SyncScanEvents(testUserUid, []SyncScanEvent{{EventUid: "e", TagUid: second.TagUid, IsVerified: 0, StateVersion: 1, ClientAt: now}})
+	if err != nil {
+		t.Fatalf("SyncScanEvents() error = %v", err)
+	}
+	if got := syncStatuses(response); !reflect.DeepEqual(got, []string{"e:applied"}) {
+		t.Errorf("处理结果 = %v, want [e:applied]", got)
+	}
+}
+
+// TestScanEventClaimDuplicate 事件UID已被并发请求写入时只影响该事件，事务可继续执行
+func TestScanEventClaimDuplicate(t *testing.T) {
+	useTestDB(t)
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		first := model.ScanEventModel{EventUid: "event-1", UserUid: testUserUid, Result: model.ScanEventApplied}
+		if claimed, err := first.ClaimTx(tx); err != nil || !claimed {
+			t.Fatalf("首次 ClaimTx() = %v, %v, want true", claimed, err)
+		}
+		again := model.ScanEventModel{EventUid: "event-1", UserUid: testUserUid}
+		if claimed, err := again.ClaimTx(tx); err != nil || claimed {
+			t.Fatalf("重复 ClaimTx() = %v, %v, want false", claimed, err)
+		}
+		other := model.ScanEventModel{EventUid: "event-2", UserUid: testUserUid}
+		if claimed, err := other.ClaimTx(tx); err != nil || !claimed {
+			t.Fatalf("其他事件 ClaimTx() = %v, %v, want true", claimed, err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Transaction() error = %v", err)
+	}
+
+	// 同步时按已保存的结果返回重复事件
+	response, err := SyncScanEvents(testUserUid, []SyncScanEvent{{EventUid: "event-1", TagUid: "55555555-5555-5555-5555-555555555555", IsVerified: 1, ClientAt: 1}})
+	if err != nil {
+		t.Fatalf("SyncScanEvents() error = %v", err)
+	}
+	if result := response.Results[0]; !result.Duplicate || result.Status != "applied" {
+		t.Errorf("重复事件结果 = %+v, want 重复的 applied", result)
+	}
+}
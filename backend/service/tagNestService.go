@@ -0,0 +1,164 @@
+package service
+
+import (
+	"fmt"
+
+	"movingManager/model"
+	"movingManager/repository"
+)
+
+// MaxTagNestDepth 容器嵌套的最大层数（如 小箱 -> 木箱 -> 托盘）
+const MaxTagNestDepth = 5
+
+// validateParentTag 事务中校验标签的外层容器
+// 外层容器必须属于同一搬运且未删除，不能是标签自身或其内部的标签，嵌套层数不能超过上限
+// tagUid 为空表示新建标签
+func validateParentTag(s repository.Store, userUid, moveUid, tagUid, parentTagUid string) (*model.TagModel, error) {
+	if parentTagUid == "" {
+		return nil, nil
+	}
+	if parentTagUid == tagUid {
+		return nil, validationError("标签不能放入自身")
+	}
+
+	parent, err := s.Tags().GetByUID(userUid, parentTagUid, true)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, validationError("外层容器标签不存在")
+		}
+		return nil, fmt.Errorf("查询外层容器标签失败: %v", err)
+	}
+	if parent.MoveUid != moveUid {
+		return nil, validationError("外层容器标签不属于该搬运")
+	}
+
+	// 向上查找外层容器的层级，同时检测是否会形成环
+	parentDepth := 1
+	current := parent
+	for current.ParentTagUid != "" {
+		if current.ParentTagUid == tagUid {
+			return nil, validationError("不能将标签放入其内部的标签中")
+		}
+		next, err := s.Tags().GetByUID(userUid, current.ParentTagUid, true)
+		if err != nil {
+			if err == repository.ErrNotFound {
+				// 外层容器已删除，视为顶层
+				break
+			}
+			return nil, fmt.Errorf("查询外层容器标签失败: %v", err)
+		}
+		current = next
+		parentDepth++
+		if parentDepth > MaxTagNestDepth {
+			break
+		}
+	}
+
+	// 已有标签需要计入其内部标签的层数
+	subtreeDepth := 1
+	if tagUid != "" {
+		descendants, err := collectDescendants(s, userUid, tagUid)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range descendants {
+			if d.depth+1 > subtreeDepth {
+				subtreeDepth = d.depth + 1
+			}
+		}
+	}
+
+	if parentDepth+subtreeDepth > MaxTagNestDepth {
+		return nil, validationError("容器嵌套不能超过%d层", MaxTagNestDepth)
+	}
+	return parent, nil
+}
+
+// nestedTag 带层级信息的内部标签
+type nestedTag struct {
+	tag   model.TagModel
+	depth int // 相对起始标签的层级，直接子标签为1
+}
+
+// collectDescendants 按层序收集标签内部的全部未删除标签
+func collectDescendants(s repository.Store, userUid, tagUid string) ([]nestedTag, error) {
+	var result []nestedTag
+	visited := map[string]bool{tagUid: true}
+	queue := []nestedTag{{tag: model.TagModel{TagUid: tagUid}}}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		children, err := s.Tags().GetChildren(userUid, current.tag.TagUid)
+		if err != nil {
+			return nil, fmt.Errorf("查询内部标签失败: %v", err)
+		}
+		for _, child := range children {
+			// 防御历史脏数据形成的环
+			if visited[child.TagUid] {
+				continue
+			}
+			visited[child.TagUid] = true
+			item := nestedTag{tag: child, depth: current.depth + 1}
+			result = append(result, item)
+			queue = append(queue, item)
+		}
+	}
+	return result, nil
+}
+
+// buildTagChildren 递归构建标签的内部标签树
+func buildTagChildren(userUid string, parent *TagResponse, rooms map[string]model.RoomModel, depth int) error {
+	if depth >= MaxTagNestDepth {
+		return nil
+	}
+
+	children, err := store.Tags().GetChildren(userUid, parent.TagUid)
+	if err != nil {
+		return fmt.Errorf("查询内部标签失败: %v", err)
+	}
+
+	parent.ChildCount = len(children)
+	for _, child := range children {
+		response := convertTagToResponse(&child)
+		fillTagRoomInfo(response, rooms)
+		response.ParentTagName = parent.TagName
+		if err := buildTagChildren(userUid, response, rooms, depth+1); err != nil {
+			return err
+		}
+		parent.Children = append(parent.Children, *response)
+	}
+	return nil
+}
+
+// fillTagHierarchy 批量补全标签响应中的外层容器名称和内部标签数量
+func fillTagHierarchy(userUid string, responses []TagResponse) error {
+	var parentUids, tagUids []string
+	for _, response := range responses {
+		tagUids = append(tagUids, response.TagUid)
+		if response.ParentTagUid != "" {
+			parentUids = append(parentUids, response.ParentTagUid)
+		}
+	}
+
+	parents, err := store.Tags().MapByUIDs(userUid, parentUids)
+	if err != nil {
+		return fmt.Errorf("查询外层容器标签失败: %v", err)
+	}
+	counts, err := store.Tags().CountChildren(userUid, tagUids)
+	if err != nil {
+		return fmt.Errorf("统计内部标签失败: %v", err)
+	}
+
+	for i := range responses {
+		if parent, ok := parents[responses[i].ParentTagUid]; ok {
+			responses[i].ParentTagName = parent.TagName
+		} else {
+			// 外层容器已删除，按顶层标签展示
+			responses[i].ParentTagUid = ""
+		}
+		responses[i].ChildCount = counts[responses[i].TagUid]
+	}
+	return nil
+}
@@ -14,50 +14,73 @@ import (
 	"golang.org/x/image/math/fixed"
 
 	"github.com/golang/freetype"
+	"github.com/golang/freetype/truetype"
 	"github.com/jung-kurt/gofpdf"
 	"github.com/skip2/go-qrcode"
-	"gorm.io/gorm"
 
-	"movingManager/database"
+	"movingManager/event"
+	"movingManager/metrics"
 	"movingManager/model"
+	"movingManager/repository"
 )
 
 // TagResponse 标签响应结构
 type TagResponse struct {
-	MoveUid            string `json:"move_uid"`
-	TagUid             string `json:"tag_uid"`
-	TagName            string `json:"tag_name"`
-	Remark             string `json:"remark"`
-	IsVerified         int    `json:"is_verified"`
-	Status             int    `json:"status"` // 标签状态
-	IsDeleted          int    `json:"is_deleted"`
-	DeletedAt          int64  `json:"deleted_at,omitempty"`
-	CreatedAt          string `json:"created_at"`
-	UpdatedAt          string `json:"updated_at,omitempty"`
-	StartLocation      string `json:"start_location"`
-	EndLocation        string `json:"end_location"`
-	MoveTime           string `json:"move_at"`
-	MoveRemark         string `json:"move_remark"`
-	TagCount           int    `json:"tag_count"`
-	VerifiedTagCount   int    `json:"verified_tag_count"`
-	UnverifiedTagCount int    `json:"unverified_tag_count"`
-	IsCompleted        int    `json:"is_completed"`
+	MoveUid            string         `json:"move_uid"`
+	TagUid             string         `json:"tag_uid"`
+	TagName            string         `json:"tag_name"`
+	Remark             string         `json:"remark"`
+	IsVerified         int            `json:"is_verified"`
+	VerifiedBy         string         `json:"verified_by"`   // 免登录扫码核销人姓名
+	VerifiedAt         string         `json:"verified_at"`   // 核销时间(未核销为空)
+	StateVersion       int64          `json:"state_version"` // 核销状态版本号(离线同步时随扫码事件上传)
+	OriginRoomUid      string         `json:"origin_room_uid"`
+	OriginRoomName     string         `json:"origin_room_name"`
+	DestRoomUid        string         `json:"dest_room_uid"`
+	DestRoomName       string         `json:"dest_room_name"`
+	DestRoomColor      string         `json:"dest_room_color"`
+	ParentTagUid       string         `json:"parent_tag_uid"`     // 外层容器标签UID
+	ParentTagName      string         `json:"parent_tag_name"`    // 外层容器标签名称
+	ChildCount         int            `json:"child_count"`        // 直接放在该标签内的标签数
+	DeclaredValue      int64          `json:"declared_value"`     // 申报价值(分)
+	Currency           string         `json:"currency"`           // 币种
+	Children           []TagResponse  `json:"children,omitempty"` // 内部标签树(仅详情返回)
+	Items              []ItemResponse `json:"items,omitempty"`    // 物品清单(仅详情返回)
+	Status             int            `json:"status"`             // 标签状态
+	IsDeleted          int            `json:"is_deleted"`
+	DeletedAt          int64          `json:"deleted_at,omitempty"`
+	CreatedAt          string         `json:"created_at"`
+	UpdatedAt          string         `json:"updated_at,omitempty"`
+	StartLocation      string         `json:"start_location"`
+	EndLocation        string         `json:"end_location"`
+	MoveTime           string         `json:"move_at"`
+	MoveRemark         string         `json:"move_remark"`
+	TagCount           int            `json:"tag_count"`
+	VerifiedTagCount   int            `json:"verified_tag_count"`
+	UnverifiedTagCount int            `json:"unverified_tag_count"`
+	IsCompleted        int            `json:"is_completed"`
 }
 
 // GenerateTagPDF 生成标签PDF业务处理
 func GenerateTagPDF(userUid, moveUid string) ([]byte, error) {
 	// 获取该搬运下的所有未删除标签
-	tagModel := model.TagModel{}
-	tags, err := tagModel.GetTagsByMove(userUid, moveUid, true)
+	tags, err := store.Tags().ListAllByMove(userUid, moveUid)
 	if err != nil {
 		return nil, fmt.Errorf("查询标签失败: %v", err)
 	}
 
 	if len(tags) == 0 {
-		return nil, fmt.Errorf("该搬运下没有标签")
+		return nil, ErrNoTags
+	}
+
+	// 获取搬运下的房间，用于在标签上打印目的地房间
+	rooms, err := store.Rooms().MapByMove(userUid, moveUid)
+	if err != nil {
+		return nil, fmt.Errorf("查询房间列表失败: %v", err)
 	}
 
 	// 创建PDF
+	defer metrics.ObservePDF("labels", time.Now())
 	pdf := gofpdf.New("P", "mm", "A4", "")
 	pdf.AddPage()
 
@@ -72,6 +95,7 @@ func GenerateTagPDF(userUid, moveUid string) ([]byte, error) {
 		columns         = 4     // 每行固定4列
 		padding         = 1.0   // 容器内边距
 		verticalSpacing = 2.0   // 行垂直间距
+		roomBandHeight  = 9.0   // 目的地房间色带高度
 		pageHeight      = 297.0 // A4页面高度(mm)
 		yStart          = 2.0   // 页面顶部起始Y坐标
 	)
@@ -80,7 +104,7 @@ func GenerateTagPDF(userUid, moveUid string) ([]byte, error) {
 	currentY := yStart                                          // 当前Y坐标
 	currentRowHeight := 0.0                                     // 当前行高度（动态计算）
 
-	i := 0
+	numbers := buildTagNumbers(tags)
 
 	// 容器布局主循环
 	for _, tag := range tags {
@@ -89,6 +113,12 @@ func GenerateTagPDF(userUid, moveUid string) ([]byte, error) {
 		qrSize := containerWidth - padding*2 - 10                                    // 二维码宽度=容器宽度-内边距-额外间距
 		contentHeight := textHeight + qrSize + padding*3                             // 总内容高度（增加额外内边距）
 
+		// 分配了目的地房间的标签在顶部增加房间色带
+		destRoom, hasDestRoom := rooms[tag.DestRoomUid]
+		if hasDestRoom {
+			contentHeight += roomBandHeight
+		}
+
 		// 检查是否需要换行
 		if currentX+containerWidth > pageWidth-margin {
 			// 换行逻辑：更新Y坐标并重置行宽度
@@ -108,14 +138,21 @@ func GenerateTagPDF(userUid, moveUid string) ([]byte, error) {
 		// 绘制容器边框
 		pdf.Rect(currentX, currentY, containerWidth, contentHeight, "D")
 
+		// 绘制目的地房间色带（房间名醒目显示，便于卸货时分拣）
+		textTop := currentY + padding
+		if hasDestRoom {
+			drawRoomBand(pdf, destRoom, currentX, currentY, containerWidth, roomBandHeight)
+			textTop += roomBandHeight
+		}
+
 		// 绘制标签文本（自适应字体）
-		pdf.SetXY(currentX+padding, currentY+padding)
+		pdf.SetXY(currentX+padding, textTop)
 		adjustFontSize(pdf, tag.TagName, containerWidth-padding*2, 11, 8)
-		pdf.MultiCell(containerWidth-padding*2, 5, fmt.Sprintf("标签 %d\n%s", i+1, tag.TagName), "", "CM", false)
+		pdf.MultiCell(containerWidth-padding*2, 5, fmt.Sprintf("标签 %d\n%s", numbers[tag.TagUid], tag.TagName), "", "CM", false)
 		pdf.SetFontSize(11) // 恢复默认字体
 
 		// 绘制二维码（容器内底部居中）
-		qrImgPath, err := generateQRCodeImage(fmt.Sprintf("http://192.168.2.17:5173/tag/%s", tag.TagUid))
+		qrImgPath, err := generateQRCodeImage(BuildTagScanURL(tag.TagUid))
 		if err != nil {
 			return nil, fmt.Errorf("生成二维码失败: %v", err)
 		}
@@ -130,7 +167,6 @@ func GenerateTagPDF(userUid, moveUid string) ([]byte, error) {
 		if contentHeight > currentRowHeight {
 			currentRowHeight = contentHeight // 记录当前行最大高度
 		}
-		i++
 	}
 
 	// 将PDF输出到字节缓冲区
@@ -166,81 +202,131 @@ func GenerateTagPDF(userUid, moveUid string) ([]byte, error) {
 // CreateTag 创建标签业务处理
 // CreateTagRequest 创建标签请求参数
 type CreateTagRequest struct {
-	MoveUid string `json:"move_uid"` // 搬运UID
-	TagName string `json:"tag_name"` // 标签名称
-	Remark  string `json:"remark"`   // 标签备注
+	MoveUid       string `json:"move_uid"`        // 搬运UID
+	TagName       string `json:"tag_name"`        // 标签名称
+	Remark        string `json:"remark"`          // 标签备注
+	OriginRoomUid string `json:"origin_room_uid"` // 出发地房间UID
+	DestRoomUid   string `json:"dest_room_uid"`   // 目的地房间UID
+	ParentTagUid  string `json:"parent_tag_uid"`  // 外层容器标签UID
+	DeclaredValue int64  `json:"declared_value"`  // 申报价值(分)
+	Currency      string `json:"currency"`        // 币种(为空默认CNY)
 }
 
 func CreateTag(userUid string, req CreateTagRequest) (*TagResponse, error) {
 	var response *TagResponse
-	err := database.DB.Transaction(func(tx *gorm.DB) error {
+	var tag model.TagModel
+	var events []event.Event
+	err := store.Transaction(func(s repository.Store) error {
 		// 验证搬运记录是否存在且属于当前用户
-		tagModel := model.TagModel{}
-		move, err := tagModel.GetMoveByUserAndMoveUidTx(tx, userUid, req.MoveUid, true)
+		move, err := s.Moves().GetByUID(userUid, req.MoveUid, true)
+		if err != nil {
+			if err == repository.ErrNotFound {
+				return ErrMoveNotFound
+			}
+			return fmt.Errorf("查询搬运记录失败: %v", err)
+		}
+
+		// 验证分配的房间属于该搬运
+		rooms, err := validateTagRooms(s, userUid, req.MoveUid, req.OriginRoomUid, req.DestRoomUid)
 		if err != nil {
 			return err
 		}
 
-		// 创建标签
-		tag := model.TagModel{
-			UserUid:    userUid,
-			MoveUid:    req.MoveUid,
-			TagName:    req.TagName,
-			Remark:     req.Remark,
-			IsVerified: 0, // 默认未核销
+		// 验证外层容器标签
+		parent, err := validateParentTag(s, userUid, req.MoveUid, "", req.ParentTagUid)
+		if err != nil {
+			return err
+		}
 
+		// 创建标签
+		tag = model.TagModel{
+			UserUid:       userUid,
+			MoveUid:       req.MoveUid,
+			TagName:       req.TagName,
+			Remark:        req.Remark,
+			IsVerified:    0, // 默认未核销
+			OriginRoomUid: req.OriginRoomUid,
+			DestRoomUid:   req.DestRoomUid,
+			ParentTagUid:  req.ParentTagUid,
+			DeclaredValue: req.DeclaredValue,
+			Currency:      normalizeCurrency(req.Currency),
 		}
-		if err := tag.CreateTx(tx); err != nil {
+		if err := s.Tags().Create(&tag); err != nil {
 			return fmt.Errorf("创建标签失败: %v", err)
 		}
 
 		// 更新搬运记录的标签统计
-		if err := tagModel.UpdateMoveTagCountTx(tx, move, 1, 0, 1); err != nil {
+		if err := s.Moves().AdjustTagCounts(move, 1, 0, 1); err != nil {
 			return fmt.Errorf("更新搬运标签统计失败: %v", err)
 		}
+		if events, err = recordTagEvents(s, event.TypeTagCreated, []model.TagModel{tag}); err != nil {
+			return err
+		}
 
 		// 转换为响应格式
 		response = convertTagToResponse(&tag)
+		fillTagRoomInfo(response, rooms)
+		if parent != nil {
+			response.ParentTagName = parent.TagName
+		}
 		return nil
 	})
-	return response, err
+	if err != nil {
+		return nil, err
+	}
+
+	publishEvents(events)
+	return response, nil
 }
 
 // UpdateTag 更新标签业务处理
 // UpdateTagRequest 编辑标签请求参数
 type UpdateTagRequest struct {
-	TagUid     string `json:"tag_uid"`     // 标签UID
-	TagName    string `json:"tag_name"`    // 标签名称
-	Remark     string `json:"remark"`      // 标签备注
-	IsVerified int    `json:"is_verified"` // 是否核销(0-未核销,1-已核销)
+	TagUid        string  `json:"tag_uid"`         // 标签UID
+	TagName       string  `json:"tag_name"`        // 标签名称
+	Remark        string  `json:"remark"`          // 标签备注
+	IsVerified    int     `json:"is_verified"`     // 是否核销(0-未核销,1-已核销)
+	OriginRoomUid *string `json:"origin_room_uid"` // 出发地房间UID(nil表示不修改)
+	DestRoomUid   *string `json:"dest_room_uid"`   // 目的地房间UID(nil表示不修改)
+	ParentTagUid  *string `json:"parent_tag_uid"`  // 外层容器标签UID(nil表示不修改)
+	DeclaredValue *int64  `json:"declared_value"`  // 申报价值(分)(nil表示不修改)
+	Currency      *string `json:"currency"`        // 币种(nil表示不修改，为空默认CNY)
 }
 
 // GetTagDetail 获取标签详情业务处理
 func GetTagDetail(userUid, tagUid string) (*TagResponse, error) {
-	var tag model.TagModel
-	if err := tag.GetByUID(userUid, tagUid, true); err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("用户无此标签记录")
+	tag, err := store.Tags().GetByUID(userUid, tagUid, true)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, ErrTagNotFound
 		}
 		return nil, fmt.Errorf("查询标签记录失败: %v", err)
 	}
 
 	// 查询关联的搬运记录
-	var move model.MoveModel
-	if err := move.GetByUID(userUid, tag.MoveUid, true); err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("关联的搬运记录不存在")
+	move, err := store.Moves().GetByUID(userUid, tag.MoveUid, true)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, ErrMoveNotFound
 		}
 		return nil, fmt.Errorf("查询搬运记录失败: %v", err)
 	}
 
+	// 查询搬运下的房间
+	rooms, err := store.Rooms().MapByMove(userUid, tag.MoveUid)
+	if err != nil {
+		return nil, fmt.Errorf("查询房间列表失败: %v", err)
+	}
+
 	// 转换为响应格式
-	return &TagResponse{
+	response := &TagResponse{
 		MoveUid:            tag.MoveUid,
 		TagUid:             tag.TagUid,
 		TagName:            tag.TagName,
 		Remark:             tag.Remark,
 		IsVerified:         tag.IsVerified,
+		VerifiedBy:         tag.VerifiedBy,
+		VerifiedAt:         formatUnixTime(tag.VerifiedAt),
 		IsDeleted:          tag.IsDeleted,
 		DeletedAt:          tag.DeletedAt,
 		CreatedAt:          time.Unix(tag.CreatedAt, 0).Format("2006-01-02 15:04:05"),
@@ -253,37 +339,117 @@ func GetTagDetail(userUid, tagUid string) (*TagResponse, error) {
 		VerifiedTagCount:   move.VerifiedTagCount,
 		UnverifiedTagCount: move.UnverifiedTagCount,
 		IsCompleted:        move.IsCompleted,
-	}, nil
+		OriginRoomUid:      tag.OriginRoomUid,
+		DestRoomUid:        tag.DestRoomUid,
+		ParentTagUid:       tag.ParentTagUid,
+		DeclaredValue:      tag.DeclaredValue,
+		Currency:           tag.Currency,
+	}
+	fillTagRoomInfo(response, rooms)
+
+	// 补全外层容器和内部标签树
+	if tag.ParentTagUid != "" {
+		if parent, err := store.Tags().GetByUID(userUid, tag.ParentTagUid, true); err == nil {
+			response.ParentTagName = parent.TagName
+		} else if err == repository.ErrNotFound {
+			response.ParentTagUid = ""
+		} else {
+			return nil, fmt.Errorf("查询外层容器标签失败: %v", err)
+		}
+	}
+	if err := buildTagChildren(userUid, response, rooms, 0); err != nil {
+		return nil, err
+	}
+
+	// 补全物品清单
+	items, err := store.Items().ListByTag(userUid, tag.TagUid)
+	if err != nil {
+		return nil, fmt.Errorf("查询物品列表失败: %v", err)
+	}
+	for _, item := range items {
+		response.Items = append(response.Items, *convertItemToResponse(&item))
+	}
+	return response, nil
 }
 
 func UpdateTag(userUid string, req UpdateTagRequest) (*TagResponse, error) {
 	var response *TagResponse
-	err := database.DB.Transaction(func(tx *gorm.DB) error {
+	var tag model.TagModel
+	var events []event.Event
+	err := store.Transaction(func(s repository.Store) error {
 		// 查询标签并验证所有权
-		var tag model.TagModel
-		if err := tag.GetByUserAndTagUidTx(tx, userUid, req.TagUid, true); err != nil {
-			return err
+		found, err := s.Tags().GetByUID(userUid, req.TagUid, true)
+		if err != nil {
+			if err == repository.ErrNotFound {
+				return ErrTagNotFound
+			}
+			return fmt.Errorf("查询标签失败: %v", err)
 		}
+		tag = *found
 
 		// 记录原始核销状态
 		oldIsVerified := tag.IsVerified
 
+		// 仅校验本次修改的房间，未传入的房间保持原值
+		var originRoomUid, destRoomUid string
+		if req.OriginRoomUid != nil {
+			originRoomUid = *req.OriginRoomUid
+		}
+		if req.DestRoomUid != nil {
+			destRoomUid = *req.DestRoomUid
+		}
+		if _, err := validateTagRooms(s, userUid, tag.MoveUid, originRoomUid, destRoomUid); err != nil {
+			return err
+		}
+
+		// 验证外层容器标签（不能放入自身或内部标签），未传入时保持原容器
+		var parent *model.TagModel
+		if req.ParentTagUid != nil {
+			if parent, err = validateParentTag(s, userUid, tag.MoveUid, tag.TagUid, *req.ParentTagUid); err != nil {
+				return err
+			}
+			tag.ParentTagUid = *req.ParentTagUid
+		} else if tag.ParentTagUid != "" {
+			parent, err = s.Tags().GetByUID(userUid, tag.ParentTagUid, true)
+			if err != nil && err != repository.ErrNotFound {
+				return fmt.Errorf("查询外层容器标签失败: %v", err)
+			}
+		}
+
 		// 更新标签信息
 		tag.TagName = req.TagName
 		tag.Remark = req.Remark
+		if req.OriginRoomUid != nil {
+			tag.OriginRoomUid = *req.OriginRoomUid
+		}
+		if req.DestRoomUid != nil {
+			tag.DestRoomUid = *req.DestRoomUid
+		}
+		if req.DeclaredValue != nil {
+			tag.DeclaredValue = *req.DeclaredValue
+		}
+		if req.Currency != nil {
+			tag.Currency = normalizeCurrency(*req.Currency)
+		}
 
 		// 如果核销状态变更，需要更新搬运记录的统计
 		if oldIsVerified != req.IsVerified {
 			tag.IsVerified = req.IsVerified
+			tag.VerifiedBy = ""
+			tag.VerifiedAt = 0
+			tag.StateChangedAt = time.Now().UnixMilli()
+			tag.StateVersion++
+			if req.IsVerified == 1 {
+				tag.VerifiedAt = time.Now().Unix()
+			}
 
 			// 查询关联的搬运记录
-			tagModel := model.TagModel{}
-			move, err := tagModel.GetMoveByMoveUidTx(tx, tag.MoveUid, true)
+			move, err := s.Moves().GetByUID(userUid, tag.MoveUid, true)
 			if err != nil {
 				return fmt.Errorf("查询搬运记录失败: %v", err)
 			}
 
-			// 使用原子操作更新搬运标签统计（确保非负）
+			// 更新搬运标签统计（确保非负）并自动计算完成状态
 			var deltaVerified, deltaUnverified int
 			if req.IsVerified == 1 {
 				// 从未核销变为已核销：已核销+1，未核销-1
@@ -295,176 +461,317 @@ func UpdateTag(userUid string, req UpdateTagRequest) (*TagResponse, error) {
 				deltaUnverified = 1
 			}
 
-			// 执行原子更新并自动计算完成状态
-			if err := tx.Model(move).Updates(map[string]interface{}{
-				"verified_tag_count":   gorm.Expr("GREATEST(verified_tag_count + ?, 0)", deltaVerified),
-				"unverified_tag_count": gorm.Expr("GREATEST(unverified_tag_count + ?, 0)", deltaUnverified),
-				"is_completed":         gorm.Expr("CASE WHEN unverified_tag_count + ? = 0 THEN 1 ELSE 0 END", deltaUnverified),
-			}).Error; err != nil {
+			if err := s.Moves().AdjustTagCounts(move, 0, deltaVerified, deltaUnverified); err != nil {
 				return fmt.Errorf("更新搬运标签统计失败: %v", err)
 			}
 		}
 
-		if err := tag.UpdateTx(tx); err != nil {
+		if err := s.Tags().Update(&tag); err != nil {
 			return fmt.Errorf("更新标签失败: %v", err)
 		}
+		if events, err = recordTagEvents(s, event.TypeTagUpdated, []model.TagModel{tag}); err != nil {
+			return err
+		}
 
-		// 转换为响应格式
+		// 转换为响应格式，房间信息包含未修改的房间
+		rooms, err := s.Rooms().MapByMove(userUid, tag.MoveUid)
+		if err != nil {
+			return fmt.Errorf("查询房间列表失败: %v", err)
+		}
 		response = convertTagToResponse(&tag)
+		fillTagRoomInfo(response, rooms)
+		if parent != nil {
+			response.ParentTagName = parent.TagName
+		}
 		return nil
 	})
-	return response, err
+	if err != nil {
+		return nil, err
+	}
+
+	publishEvents(events)
+	return response, nil
 }
 
 // DeleteTag 删除标签业务处理
 func DeleteTag(userUid, tagUid string, isDeleted int) error {
-	return database.DB.Transaction(func(tx *gorm.DB) error {
+	var tag model.TagModel
+	var events []event.Event
+	err := store.Transaction(func(s repository.Store) error {
 		// 恢复操作时需要查找已删除记录（isDeleted=0表示恢复）
 		onlyUndeleted := isDeleted != 0
 		// 查询标签并验证所有权
-		var tag model.TagModel
-		if err := tag.GetByUserAndTagUidTx(tx, userUid, tagUid, onlyUndeleted); err != nil {
-			return err
+		found, err := s.Tags().GetByUID(userUid, tagUid, onlyUndeleted)
+		if err != nil {
+			if err == repository.ErrNotFound {
+				return ErrTagNotFound
+			}
+			return fmt.Errorf("查询标签失败: %v", err)
 		}
+		tag = *found
 
-		// 如果是删除操作(不是恢复)
-		if isDeleted == 1 {
-			// 查询关联的搬运记录
-			tagModel := model.TagModel{}
-			move, err := tagModel.GetMoveByMoveUidTx(tx, tag.MoveUid, true)
-			if err != nil {
-				return fmt.Errorf("查询搬运记录失败: %v", err)
-			}
+		// 查询关联的搬运记录
+		move, err := s.Moves().GetByUID(userUid, tag.MoveUid, true)
+		if err != nil {
+			return fmt.Errorf("查询搬运记录失败: %v", err)
+		}
 
-			// 更新搬运记录的标签统计
-			var verifiedDelta, unverifiedDelta int
-			if tag.IsVerified == 1 {
-				verifiedDelta = -1
-			} else {
-				unverifiedDelta = -1
-			}
-			if err := tagModel.UpdateMoveTagCountTx(tx, move, -1, verifiedDelta, unverifiedDelta); err != nil {
-				return fmt.Errorf("更新搬运标签统计失败: %v", err)
-			}
+		// 删除时减少、恢复时增加标签总数和对应核销状态的计数
+		delta := 1
+		if isDeleted == 1 {
+			delta = -1
+		}
+		var verifiedDelta, unverifiedDelta int
+		if tag.IsVerified == 1 {
+			verifiedDelta = delta
+		} else {
+			unverifiedDelta = delta
+		}
+		if err := s.Moves().AdjustTagCounts(move, delta, verifiedDelta, unverifiedDelta); err != nil {
+			return fmt.Errorf("更新搬运标签统计失败: %v", err)
 		}
 
 		// 更新标签删除状态
-		// 恢复标签时增加总数和未核销数
-		if isDeleted == 0 {
-			// 查询关联的搬运记录
-			tagModel := model.TagModel{}
-			move, err := tagModel.GetMoveByMoveUidTx(tx, tag.MoveUid, true)
-			if err != nil {
-				return fmt.Errorf("查询搬运记录失败: %v", err)
-			}
-
-			// 根据标签原核销状态更新对应计数
-			var unverifiedDelta, verifiedDelta int
-			if tag.IsVerified == 1 {
-				verifiedDelta = 1
-			} else {
-				unverifiedDelta = 1
-			}
-
-			// 更新搬运记录的标签统计（原子操作确保非负）
-			if err := tx.Model(move).Updates(map[string]interface{}{
-				"tag_count":            gorm.Expr("GREATEST(tag_count + 1, 0)"),
-				"unverified_tag_count": gorm.Expr("GREATEST(unverified_tag_count + ?, 0)", unverifiedDelta),
-				"verified_tag_count":   gorm.Expr("GREATEST(verified_tag_count + ?, 0)", verifiedDelta),
-				"is_completed":         0, // 恢复后可能有未核销标签，设置为未完成
-			}).Error; err != nil {
-				return fmt.Errorf("更新搬运标签统计失败: %v", err)
-			}
+		tag.IsDeleted = isDeleted
+		if isDeleted == 1 {
+			tag.DeletedAt = time.Now().Unix()
 		}
-
-		return tag.UpdateDeleteStatusTx(tx, isDeleted)
+		if err := s.Tags().Update(&tag); err != nil {
+			return err
+		}
+		events, err = recordTagEvents(s, event.TypeTagDeleted, []model.TagModel{tag})
+		return err
 	})
+	if err != nil {
+		return err
+	}
+
+	publishEvents(events)
+	return nil
 }
 
 // VerifyTag 核销标签业务处理
-func VerifyTag(userUid, tagUid string, isVerified int) error {
-	db := database.DB
+// cascade 为true时同时将容器内的全部标签设置为相同核销状态，返回实际变更的标签数量
+func VerifyTag(userUid, tagUid string, isVerified int, cascade bool) (int, error) {
+	var changed []model.TagModel
+	var events []event.Event
 
 	// 开启事务
-	return db.Transaction(func(tx *gorm.DB) error {
+	err := store.Transaction(func(s repository.Store) error {
 		// 查询标签并验证所有权
-		var tag model.TagModel
-		if err := tag.GetByUserAndTagUidTx(tx, userUid, tagUid, true); err != nil {
-			if err == gorm.ErrRecordNotFound {
-				return fmt.Errorf("用户无此标签记录")
+		tag, err := s.Tags().GetByUID(userUid, tagUid, true)
+		if err != nil {
+			if err == repository.ErrNotFound {
+				return ErrTagNotFound
 			}
 			return fmt.Errorf("查询标签失败: %v", err)
 		}
 
-		// 如果状态没变，直接返回
-		if tag.IsVerified == isVerified {
-			return nil
-		}
-
 		// 查询关联的搬运记录
-		tagModel := model.TagModel{}
-		move, err := tagModel.GetMoveByMoveUidTx(tx, tag.MoveUid, true)
+		move, err := s.Moves().GetByUID(userUid, tag.MoveUid, true)
 		if err != nil {
 			return fmt.Errorf("查询搬运记录失败: %v", err)
 		}
 
-		// 计算标签统计变化量
-		var verifiedDelta, unverifiedDelta int
-		if isVerified == 1 {
-			verifiedDelta = 1
-			unverifiedDelta = -1
-		} else {
-			verifiedDelta = -1
-			unverifiedDelta = 1
+		changed, err = verifyTagInStore(s, move, tag, isVerified, cascade, "")
+		if err != nil {
+			return err
 		}
+		events, err = recordTagEvents(s, event.TypeTagVerified, changed)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
 
-		// 更新搬运记录的标签统计
-		// 核销标签时更新计数并检查完成状态
-		if err := tx.Model(&move).Updates(map[string]interface{}{
-			"verified_tag_count":   gorm.Expr("GREATEST(verified_tag_count + ?, 0)", verifiedDelta),
-			"unverified_tag_count": gorm.Expr("GREATEST(unverified_tag_count + ?, 0)", unverifiedDelta),
-			"is_completed":         gorm.Expr("CASE WHEN unverified_tag_count + ? = 0 THEN 1 ELSE is_completed END", unverifiedDelta),
-		}).Error; err != nil {
-			return fmt.Errorf("更新搬运标签统计失败: %v", err)
+	if isVerified == 1 {
+		metrics.AddScans(metrics.ScanSourceApp, 1)
+	}
+	publishEvents(events)
+	return len(changed), nil
+}
+
+// verifyTagInStore 事务中变更标签核销状态并同步搬运统计，返回实际变更的标签
+// verifiedBy 为免登录扫码核销人姓名，登录用户核销时为空
+func verifyTagInStore(s repository.Store, move *model.MoveModel, tag *model.TagModel, isVerified int, cascade bool, verifiedBy string) ([]model.TagModel, error) {
+	// 收集需要变更状态的标签
+	targets := []model.TagModel{*tag}
+	if cascade {
+		descendants, err := collectDescendants(s, tag.UserUid, tag.TagUid)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range descendants {
+			targets = append(targets, d.tag)
 		}
+	}
 
-		// 更新标签核销状态
-		tag.IsVerified = isVerified
-		if err := tag.UpdateTx(tx); err != nil {
-			return fmt.Errorf("更新标签核销状态失败: %v", err)
+	var changed []model.TagModel
+	for _, target := range targets {
+		if target.IsVerified != isVerified {
+			changed = append(changed, target)
 		}
+	}
 
-		return nil
-	})
+	// 如果状态都没变，直接返回
+	if len(changed) == 0 {
+		return nil, nil
+	}
+
+	// 计算标签统计变化量
+	n := len(changed)
+	var verifiedDelta, unverifiedDelta int
+	if isVerified == 1 {
+		verifiedDelta = n
+		unverifiedDelta = -n
+	} else {
+		verifiedDelta = -n
+		unverifiedDelta = n
+	}
+
+	// 更新搬运记录的标签统计
+	// 核销标签时更新计数并检查完成状态
+	if err := s.Moves().AdjustTagCounts(move, 0, verifiedDelta, unverifiedDelta); err != nil {
+		return nil, fmt.Errorf("更新搬运标签统计失败: %v", err)
+	}
+
+	// 更新标签核销状态，记录核销人和核销时间
+	now := time.Now()
+	for i := range changed {
+		changed[i].IsVerified = isVerified
+		changed[i].VerifiedBy = ""
+		changed[i].VerifiedAt = 0
+		changed[i].StateChangedAt = now.UnixMilli()
+		changed[i].StateVersion++
+		if isVerified == 1 {
+			changed[i].VerifiedBy = verifiedBy
+			changed[i].VerifiedAt = now.Unix()
+		}
+		if err := s.Tags().Update(&changed[i]); err != nil {
+			return nil, fmt.Errorf("更新标签核销状态失败: %v", err)
+		}
+	}
+
+	return changed, nil
 }
 
 // GetTagList 获取标签列表业务处理
 func GetTagList(userUid, moveUid string, page, pageSize int) ([]TagResponse, int64, error) {
 	// 验证搬运记录是否存在且属于当前用户
-	var move model.MoveModel
-	if err := move.GetByUID(userUid, moveUid, true); err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, 0, fmt.Errorf("搬运记录不存在")
+	if _, err := store.Moves().GetByUID(userUid, moveUid, true); err != nil {
+		if err == repository.ErrNotFound {
+			return nil, 0, ErrMoveNotFound
 		}
 		return nil, 0, fmt.Errorf("查询搬运记录失败: %v", err)
 	}
 
-	// 调用model层查询方法
-	var tag model.TagModel
-	tags, total, err := tag.ListByMove(userUid, moveUid, page, pageSize, true)
+	tags, total, err := store.Tags().ListByMove(userUid, moveUid, page, pageSize)
 	if err != nil {
 		return nil, 0, fmt.Errorf("查询标签列表失败: %v", err)
 	}
 
+	// 查询搬运下的房间
+	rooms, err := store.Rooms().MapByMove(userUid, moveUid)
+	if err != nil {
+		return nil, 0, fmt.Errorf("查询房间列表失败: %v", err)
+	}
+
 	// 转换为响应格式
 	var responses []TagResponse
 	for _, tag := range tags {
-		responses = append(responses, *convertTagToResponse(&tag))
+		response := convertTagToResponse(&tag)
+		fillTagRoomInfo(response, rooms)
+		responses = append(responses, *response)
+	}
+
+	// 补全外层容器和内部标签数量
+	if err := fillTagHierarchy(userUid, responses); err != nil {
+		return nil, 0, err
 	}
 
 	return responses, total, nil
 }
 
+// TagRoomGroup 按目的地房间分组的标签
+type TagRoomGroup struct {
+	RoomUid          string        `json:"room_uid"` // 为空表示未分配房间
+	RoomName         string        `json:"room_name"`
+	Color            string        `json:"color"`
+	TagCount         int           `json:"tag_count"`
+	VerifiedTagCount int           `json:"verified_tag_count"`
+	Tags             []TagResponse `json:"tags"`
+}
+
+// GetTagListByRoom 按目的地房间分组获取标签列表业务处理
+// 分组顺序与房间排序一致，未分配房间的标签放在最后
+func GetTagListByRoom(userUid, moveUid string) ([]TagRoomGroup, error) {
+	// 验证搬运记录是否存在且属于当前用户
+	if _, err := store.Moves().GetByUID(userUid, moveUid, true); err != nil {
+		if err == repository.ErrNotFound {
+			return nil, ErrMoveNotFound
+		}
+		return nil, fmt.Errorf("查询搬运记录失败: %v", err)
+	}
+
+	roomList, err := store.Rooms().ListByMove(userUid, moveUid, 0)
+	if err != nil {
+		return nil, fmt.Errorf("查询房间列表失败: %v", err)
+	}
+	rooms := make(map[string]model.RoomModel, len(roomList))
+	for _, room := range roomList {
+		rooms[room.RoomUid] = room
+	}
+
+	tags, err := store.Tags().ListAllByMove(userUid, moveUid)
+	if err != nil {
+		return nil, fmt.Errorf("查询标签失败: %v", err)
+	}
+
+	responses := make([]TagResponse, 0, len(tags))
+	for _, tag := range tags {
+		response := convertTagToResponse(&tag)
+		fillTagRoomInfo(response, rooms)
+		responses = append(responses, *response)
+	}
+	if err := fillTagHierarchy(userUid, responses); err != nil {
+		return nil, err
+	}
+
+	// 按目的地房间归组，房间已删除的标签视为未分配
+	grouped := make(map[string][]TagResponse)
+	for _, response := range responses {
+		key := ""
+		if _, ok := rooms[response.DestRoomUid]; ok {
+			key = response.DestRoomUid
+		}
+		grouped[key] = append(grouped[key], response)
+	}
+
+	groups := make([]TagRoomGroup, 0, len(grouped))
+	appendGroup := func(group TagRoomGroup) {
+		group.Tags = grouped[group.RoomUid]
+		if len(group.Tags) == 0 {
+			return
+		}
+		group.TagCount = len(group.Tags)
+		for _, tag := range group.Tags {
+			if tag.IsVerified == 1 {
+				group.VerifiedTagCount++
+			}
+		}
+		groups = append(groups, group)
+	}
+	for _, room := range roomList {
+		if room.Side != model.RoomSideDestination {
+			continue
+		}
+		appendGroup(TagRoomGroup{RoomUid: room.RoomUid, RoomName: room.RoomName, Color: room.Color})
+	}
+	appendGroup(TagRoomGroup{RoomName: "未分配房间"})
+
+	return groups, nil
+}
+
 // calculateTextHeight 计算文本高度
 func calculateTextHeight(text string, width float64, fontSize float64) float64 {
 	// 使用freetype测量文本高度
@@ -506,6 +813,55 @@ func calculateTextHeight(text string, width float64, fontSize float64) float64 {
 	return float64(lines) * lineHeight
 }
 
+// buildTagNumbers 按标签创建顺序生成标签编号（与打印的"标签 n"一致）
+// tags 需为 GetTagsByMove 返回的未删除标签
+func buildTagNumbers(tags []model.TagModel) map[string]int {
+	numbers := make(map[string]int, len(tags))
+	for i, tag := range tags {
+		numbers[tag.TagUid] = i + 1
+	}
+	return numbers
+}
+
+// drawRoomBand 在标签顶部绘制目的地房间色带
+// 未设置颜色的房间使用浅灰底色，文字颜色根据底色亮度选择黑或白
+func drawRoomBand(pdf *gofpdf.Fpdf, room model.RoomModel, x, y, width, height float64) {
+	r, g, b := parseHexColor(room.Color)
+	pdf.SetFillColor(r, g, b)
+	pdf.Rect(x, y, width, height, "FD")
+
+	// 按ITU-R BT.601计算亮度
+	if float64(r)*0.299+float64(g)*0.587+float64(b)*0.114 < 140 {
+		pdf.SetTextColor(255, 255, 255)
+	} else {
+		pdf.SetTextColor(0, 0, 0)
+	}
+	pdf.SetXY(x, y)
+	adjustFontSize(pdf, room.RoomName, width-2, 16, 9)
+	pdf.CellFormat(width, height, room.RoomName, "", 0, "CM", false, 0, "")
+
+	// 恢复默认颜色和字体
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetFillColor(255, 255, 255)
+	pdf.SetFontSize(11)
+}
+
+// parseHexColor 解析#RRGGBB或#RGB格式颜色，无效时返回浅灰色
+func parseHexColor(color string) (int, int, int) {
+	hex := strings.TrimPrefix(color, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	var r, g, b int
+	if len(hex) != 6 {
+		return 230, 230, 230
+	}
+	if _, err := fmt.Sscanf(hex, "%02x%02x%02x", &r, &g, &b); err != nil {
+		return 230, 230, 230
+	}
+	return r, g, b
+}
+
 // adjustFontSize 调整字体大小以适应宽度
 func adjustFontSize(pdf *gofpdf.Fpdf, text string, maxWidth float64, maxSize, minSize float64) {
 	// 保存原始字体大小
@@ -582,23 +938,12 @@ func generateTextImage(text string, fontSize float64) ([]byte, error) {
 	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
 
 	// 使用项目中已有的中文字体文件
-	fontBytes, err := os.ReadFile("fonts/AlibabaPuHuiTi-3-95-ExtraBold.ttf")
+	font, err := loadTextFont()
 	if err != nil {
-		return nil, fmt.Errorf("读取内置字体失败: %v", err)
-	}
-	font, err := freetype.ParseFont(fontBytes)
-	if err != nil {
-		return nil, fmt.Errorf("解析字体失败: %v", err)
+		return nil, err
 	}
 
-	c := freetype.NewContext()
-	c.SetDPI(72)
-	c.SetFont(font)
-	c.SetFontSize(float64(fontSize))
-	c.SetClip(img.Bounds())
-	c.SetDst(img)
-	c.SetSrc(image.Black)
-
+	c := newTextContext(img, font, fontSize, 72, image.Black)
 	pt := freetype.Pt(10, 20) // 文本位置
 	if _, err := c.DrawString(text, pt); err != nil {
 		return nil, fmt.Errorf("绘制文本失败: %v", err)
@@ -612,6 +957,32 @@ func generateTextImage(text string, fontSize float64) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+// loadTextFont 读取并解析项目内置的中文字体
+func loadTextFont() (*truetype.Font, error) {
+	fontBytes, err := os.ReadFile("fonts/AlibabaPuHuiTi-3-95-ExtraBold.ttf")
+	if err != nil {
+		return nil, fmt.Errorf("读取内置字体失败: %v", err)
+	}
+	font, err := freetype.ParseFont(fontBytes)
+	if err != nil {
+		return nil, fmt.Errorf("解析字体失败: %v", err)
+	}
+	return font, nil
+}
+
+// newTextContext 创建在dst上绘制文本的freetype上下文
+// fontSize 单位为磅，dpi 决定磅到像素的换算
+func newTextContext(dst draw.Image, font *truetype.Font, fontSize, dpi float64, src image.Image) *freetype.Context {
+	c := freetype.NewContext()
+	c.SetDPI(dpi)
+	c.SetFont(font)
+	c.SetFontSize(fontSize)
+	c.SetClip(dst.Bounds())
+	c.SetDst(dst)
+	c.SetSrc(src)
+	return c
+}
+
 // generateQRCode 生成二维码图片
 func generateQRCode(data string, size int) ([]byte, error) {
 	var buf bytes.Buffer
@@ -638,14 +1009,30 @@ func convertTagToResponse(tag *model.TagModel) *TagResponse {
 	}
 
 	return &TagResponse{
-		MoveUid:    tag.MoveUid,
-		TagUid:     tag.TagUid,
-		TagName:    tag.TagName,
-		Remark:     tag.Remark,
-		IsVerified: tag.IsVerified,
-		IsDeleted:  tag.IsDeleted,
-		DeletedAt:  deleteTime,
-		CreatedAt:  time.Unix(tag.CreatedAt, 0).Format("2006-01-02 15:04:05"),
-		UpdatedAt:  updatedAt,
+		MoveUid:       tag.MoveUid,
+		TagUid:        tag.TagUid,
+		TagName:       tag.TagName,
+		Remark:        tag.Remark,
+		IsVerified:    tag.IsVerified,
+		VerifiedBy:    tag.VerifiedBy,
+		VerifiedAt:    formatUnixTime(tag.VerifiedAt),
+		StateVersion:  tag.StateVersion,
+		IsDeleted:     tag.IsDeleted,
+		DeletedAt:     deleteTime,
+		CreatedAt:     time.Unix(tag.CreatedAt, 0).Format("2006-01-02 15:04:05"),
+		UpdatedAt:     updatedAt,
+		OriginRoomUid: tag.OriginRoomUid,
+		DestRoomUid:   tag.DestRoomUid,
+		ParentTagUid:  tag.ParentTagUid,
+		DeclaredValue: tag.DeclaredValue,
+		Currency:      tag.Currency,
+	}
+}
+
+// formatUnixTime 格式化时间戳，0表示未设置返回空字符串
+func formatUnixTime(ts int64) string {
+	if ts == 0 {
+		return ""
 	}
+	return time.Unix(ts, 0).Format("2006-01-02 15:04:05")
 }
@@ -0,0 +1,396 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"movingManager/model"
+)
+
+// newTestMove 创建测试用搬运记录
+func newTestMove(t *testing.T) *model.MoveModel {
+	t.Helper()
+	move, err := CreateMove(testUserUid, 1700000000, "旧家", "新家", "")
+	if err != nil {
+		t.Fatalf("CreateMove() error = %v", err)
+	}
+	return move
+}
+
+// newTestTag 创建测试用标签
+func newTestTag(t *testing.T, moveUid, name, parentTagUid string) *TagResponse {
+	t.Helper()
+	tag, err := CreateTag(testUserUid, CreateTagRequest{MoveUid: moveUid, TagName: name, ParentTagUid: parentTagUid})
+	if err != nil {
+		t.Fatalf("CreateTag(%s) error = %v", name, err)
+	}
+	return tag
+}
+
+// assertMoveCounts 校验搬运的标签统计和完成状态
+func assertMoveCounts(t *testing.T, moveUid string, total, verified, unverified, completed int) {
+	t.Helper()
+	move, err := GetMoveDetail(testUserUid, moveUid, true)
+	if err != nil {
+		t.Fatalf("GetMoveDetail() error = %v", err)
+	}
+	if move.TagCount != total || move.VerifiedTagCount != verified || move.UnverifiedTagCount != unverified || move.IsCompleted != completed {
+		t.Errorf("搬运统计 = (%d, %d, %d, %d), want (%d, %d, %d, %d)",
+			move.TagCount, move.VerifiedTagCount, move.UnverifiedTagCount, move.IsCompleted,
+			total, verified, unverified, completed)
+	}
+}
+
+func TestCreateTag(t *testing.T) {
+	forEachStore(t, func(t *testing.T) {
+		move := newTestMove(t)
+
+		tag := newTestTag(t, move.MoveUid, "厨房1", "")
+		if tag.Currency != DefaultCurrency || tag.IsVerified != 0 {
+			t.Errorf("CreateTag() = %+v", tag)
+		}
+		assertMoveCounts(t, move.MoveUid, 1, 0, 1, 0)
+
+		if _, err := CreateTag(otherUserUid, CreateTagRequest{MoveUid: move.MoveUid, TagName: "x"}); !errors.Is(err, ErrMoveNotFound) {
+			t.Errorf("其他用户创建 error = %v, want ErrMoveNotFound", err)
+		}
+		// 失败的事务不应改变统计
+		assertMoveCounts(t, move.MoveUid, 1, 0, 1, 0)
+	})
+}
+
+func TestCreateTagRoomValidation(t *testing.T) {
+	forEachStore(t, func(t *testing.T) {
+		move := newTestMove(t)
+		otherMove := newTestMove(t)
+		origin := addTestRoom(t, model.RoomModel{UserUid: testUserUid, MoveUid: move.MoveUid, Side: model.RoomSideOrigin, RoomName: "旧厨房"})
+		dest := addTestRoom(t, model.RoomModel{UserUid: testUserUid, MoveUid: move.MoveUid, Side: model.RoomSideDestination, RoomName: "新厨房", Color: "#ff0000"})
+		foreign := addTestRoom(t, model.RoomModel{UserUid: testUserUid, MoveUid: otherMove.MoveUid, Side: model.RoomSideDestination})
+
+		cases := []struct {
+			name      string
+			origin    string
+			dest      string
+			wantErr   error
+			wantColor string
+		}{
+			{"正确分配", origin.RoomUid, dest.RoomUid, nil, "#ff0000"},
+			{"不分配房间", "", "", nil, ""},
+			{"出发地填了目的地房间", dest.RoomUid, "", ErrValidation, ""},
+			{"其他搬运的房间", "", foreign.RoomUid, ErrValidation, ""},
+			{"房间不存在", "", "44444444-4444-4444-4444-444444444444", ErrRoomNotFound, ""},
+		}
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				tag, err := CreateTag(testUserUid, CreateTagRequest{MoveUid: move.MoveUid, TagName: tc.name, OriginRoomUid: tc.origin, DestRoomUid: tc.dest})
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("CreateTag() error = %v, want %v", err, tc.wantErr)
+				}
+				if err == nil && tag.DestRoomColor != tc.wantColor {
+					t.Errorf("DestRoomColor = %q, want %q", tag.DestRoomColor, tc.wantColor)
+				}
+			})
+		}
+		assertMoveCounts(t, move.MoveUid, 2, 0, 2, 0)
+	})
+}
+
+func TestUpdateTagRoomsOptional(t *testing.T) {
+	forEachStore(t, func(t *testing.T) {
+		move := newTestMove(t)
+		origin := addTestRoom(t, model.RoomModel{UserUid: testUserUid, MoveUid: move.MoveUid, Side: model.RoomSideOrigin, RoomName: "旧厨房"})
+		dest := addTestRoom(t, model.RoomModel{UserUid: testUserUid, MoveUid: move.MoveUid, Side: model.RoomSideDestination, RoomName: "新厨房"})
+		otherDest := addTestRoom(t, model.RoomModel{UserUid: testUserUid, MoveUid: move.MoveUid, Side: model.RoomSideDestination, RoomName: "新书房"})
+		empty := ""
+
+		cases := []struct {
+			name       string
+			origin     *string
+			dest       *string
+			wantOrigin string
+			wantDest   string
+		}{
+			{"不传房间保持原值", nil, nil, origin.RoomUid, dest.RoomUid},
+			{"只修改目的地", nil, &otherDest.RoomUid, origin.RoomUid, otherDest.RoomUid},
+			{"空字符串取消分配", &empty, nil, "", dest.RoomUid},
+		}
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				tag, err := CreateTag(testUserUid, CreateTagRequest{MoveUid: move.MoveUid, TagName: "书", OriginRoomUid: origin.RoomUid, DestRoomUid: dest.RoomUid})
+				if err != nil {
+					t.Fatalf("CreateTag() error = %v", err)
+				}
+				updated, err := UpdateTag(testUserUid, UpdateTagRequest{TagUid: tag.TagUid, TagName: "书", OriginRoomUid: tc.origin, DestRoomUid: tc.dest})
+				if err != nil {
+					t.Fatalf("UpdateTag() error = %v", err)
+				}
+				if updated.OriginRoomUid != tc.wantOrigin || updated.DestRoomUid != tc.wantDest {
+					t.Errorf("房间 = (%q, %q), want (%q, %q)", updated.OriginRoomUid, updated.DestRoomUid, tc.wantOrigin, tc.wantDest)
+				}
+				if tc.wantDest != "" && updated.DestRoomName == "" {
+					t.Error("响应缺少目的地房间名称")
+				}
+			})
+		}
+	})
+}
+
+func TestUpdateTagDeclaredValueOptional(t *testing.T) {
+	forEachStore(t, func(t *testing.T) {
+		move := newTestMove(t)
+		value, usd, empty := int64(2500), "USD", ""
+
+		cases := []struct {
+			name         string
+			value        *int64
+			currency     *string
+			wantValue    int64
+			wantCurrency string
+		}{
+			{"不传保持原值", nil, nil, 10000, "EUR"},
+			{"修改价值和币种", &value, &usd, 2500, "USD"},
+			{"币种为空恢复默认", nil, &empty, 10000, DefaultCurrency},
+		}
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				tag, err := CreateTag(testUserUid, CreateTagRequest{MoveUid: move.MoveUid, TagName: "电视", DeclaredValue: 10000, Currency: "EUR"})
+				if err != nil {
+					t.Fatalf("CreateTag() error = %v", err)
+				}
+				updated, err := UpdateTag(testUserUid, UpdateTagRequest{TagUid: tag.TagUid, TagName: "电视", DeclaredValue: tc.value, Currency: tc.currency})
+				if err != nil {
+					t.Fatalf("UpdateTag() error = %v", err)
+				}
+				if updated.DeclaredValue != tc.wantValue || updated.Currency != tc.wantCurrency {
+					t.Errorf("申报价值 = (%d, %s), want (%d, %s)", updated.DeclaredValue, updated.Currency, tc.wantValue, tc.wantCurrency)
+				}
+			})
+		}
+	})
+}
+
+func TestUpdateTagNotFound(t *testing.T) {
+	forEachStore(t, func(t *testing.T) {
+		move := newTestMove(t)
+		tag := newTestTag(t, move.MoveUid, "书", "")
+
+		if _, err := UpdateTag(otherUserUid, UpdateTagRequest{TagUid: tag.TagUid, TagName: "x"}); !errors.Is(err, ErrTagNotFound) {
+			t.Errorf("UpdateTag() error = %v, want ErrTagNotFound", err)
+		}
+	})
+}
+
+func TestUpdateTagNesting(t *testing.T) {
+	forEachStore(t, func(t *testing.T) {
+		move := newTestMove(t)
+		pallet := newTestTag(t, move.MoveUid, "托盘", "")
+		box := newTestTag(t, move.MoveUid, "木箱", pallet.TagUid)
+		missing, top := "55555555-5555-5555-5555-555555555555", ""
+
+		cases := []struct {
+			name    string
+			tagUid  string
+			parent  *string
+			wantErr error
+		}{
+			{"放入自身", pallet.TagUid, &pallet.TagUid, ErrValidation},
+			{"放入内部标签形成环", pallet.TagUid, &box.TagUid, ErrValidation},
+			{"外层容器不存在", box.TagUid, &missing, ErrValidation},
+			{"不传保持原容器", box.TagUid, nil, nil},
+			{"取出到顶层", box.TagUid, &top, nil},
+		}
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				got, err := UpdateTag(testUserUid, UpdateTagRequest{TagUid: tc.tagUid, TagName: "x", ParentTagUid: tc.parent})
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("UpdateTag() error = %v, want %v", err, tc.wantErr)
+				}
+				if err == nil && tc.parent == nil && (got.ParentTagUid != pallet.TagUid || got.ParentTagName != "托盘") {
+					t.Errorf("未传外层容器时 = (%q, %q), want 保持原容器", got.ParentTagUid, got.ParentTagName)
+				}
+			})
+		}
+	})
+}
+
+func TestUpdateTagNestDepthLimit(t *testing.T) {
+	forEachStore(t, func(t *testing.T) {
+		move := newTestMove(t)
+
+		parent := ""
+		for i := 0; i < MaxTagNestDepth; i++ {
+			parent = newTestTag(t, move.MoveUid, "层", parent).TagUid
+		}
+		if _, err := CreateTag(testUserUid, CreateTagRequest{MoveUid: move.MoveUid, TagName: "超限", ParentTagUid: parent}); !errors.Is(err, ErrValidation) {
+			t.Errorf("超过嵌套层数 error = %v, want ErrValidation", err)
+		}
+	})
+}
+
+func TestVerifyTagCounts(t *testing.T) {
+	forEachStore(t, func(t *testing.T) {
+		move := newTestMove(t)
+		first := newTestTag(t, move.MoveUid, "一", "")
+		second := newTestTag(t, move.MoveUid, "二", "")
+
+		steps := []struct {
+			name        string
+			tagUid      string
+			isVerified  int
+			wantChanged int
+			counts      [4]int // 总数, 已核销, 未核销, 是否完成
+		}{
+			{"核销第一个", first.TagUid, 1, 1, [4]int{2, 1, 1, 0}},
+			{"重复核销无变化", first.TagUid, 1, 0, [4]int{2, 1, 1, 0}},
+			{"全部核销后完成", second.TagUid, 1, 1, [4]int{2, 2, 0, 1}},
+			{"取消核销后未完成", second.TagUid, 0, 1, [4]int{2, 1, 1, 0}},
+		}
+		for _, step := range steps {
+			changed, err := VerifyTag(testUserUid, step.tagUid, step.isVerified, false)
+			if err != nil {
+				t.Fatalf("%s: VerifyTag() error = %v", step.name, err)
+			}
+			if changed != step.wantChanged {
+				t.Errorf("%s: changed = %d, want %d", step.name, changed, step.wantChanged)
+			}
+			assertMoveCounts(t, move.MoveUid, step.counts[0], step.counts[1], step.counts[2], step.counts[3])
+		}
+	})
+}
+
+func TestVerifyTagCascade(t *testing.T) {
+	forEachStore(t, func(t *testing.T) {
+		move := newTestMove(t)
+		pallet := newTestTag(t, move.MoveUid, "托盘", "")
+		box := newTestTag(t, move.MoveUid, "木箱", pallet.TagUid)
+		newTestTag(t, move.MoveUid, "小箱", box.TagUid)
+		newTestTag(t, move.MoveUid, "散件", "")
+
+		changed, err := VerifyTag(testUserUid, pallet.TagUid, 1, true)
+		if err != nil {
+			t.Fatalf("VerifyTag() error = %v", err)
+		}
+		if changed != 3 {
+			t.Errorf("级联核销 changed = %d, want 3", changed)
+		}
+		assertMoveCounts(t, move.MoveUid, 4, 3, 1, 0)
+	})
+}
+
+func TestDeleteAndRestoreTag(t *testing.T) {
+	forEachStore(t, func(t *testing.T) {
+		move := newTestMove(t)
+		verified := newTestTag(t, move.MoveUid, "已核销", "")
+		pending := newTestTag(t, move.MoveUid, "未核销", "")
+		VerifyTag(testUserUid, verified.TagUid, 1, false)
+
+		if err := DeleteTag(testUserUid, pending.TagUid, 1); err != nil {
+			t.Fatalf("DeleteTag() error = %v", err)
+		}
+		// 剩余标签全部已核销，搬运完成
+		assertMoveCounts(t, move.MoveUid, 1, 1, 0, 1)
+
+		if err := DeleteTag(testUserUid, pending.TagUid, 1); !errors.Is(err, ErrTagNotFound) {
+			t.Errorf("重复删除 error = %v, want ErrTagNotFound", err)
+		}
+
+		if err := DeleteTag(testUserUid, pending.TagUid, 0); err != nil {
+			t.Fatalf("恢复 DeleteTag() error = %v", err)
+		}
+		assertMoveCounts(t, move.MoveUid, 2, 1, 1, 0)
+	})
+}
+
+func TestGetTagListHierarchy(t *testing.T) {
+	forEachStore(t, func(t *testing.T) {
+		move := newTestMove(t)
+		pallet := newTestTag(t, move.MoveUid, "托盘", "")
+		box := newTestTag(t, move.MoveUid, "木箱", pallet.TagUid)
+		newTestTag(t, move.MoveUid, "小箱", pallet.TagUid)
+		VerifyTag(testUserUid, box.TagUid, 1, false)
+
+		tags, total, err := GetTagList(testUserUid, move.MoveUid, 1, 10)
+		if err != nil {
+			t.Fatalf("GetTagList() error = %v", err)
+		}
+		if total != 3 || len(tags) != 3 {
+			t.Fatalf("GetTagList() total = %d, len = %d", total, len(tags))
+		}
+		// 未核销的在前
+		if tags[2].TagUid != box.TagUid {
+			t.Errorf("已核销标签应排在最后，got %s", tags[2].TagName)
+		}
+		byUid := make(map[string]TagResponse)
+		for _, tag := range tags {
+			byUid[tag.TagUid] = tag
+		}
+		if byUid[pallet.TagUid].ChildCount != 2 {
+			t.Errorf("托盘 ChildCount = %d, want 2", byUid[pallet.TagUid].ChildCount)
+		}
+		if byUid[box.TagUid].ParentTagName != "托盘" {
+			t.Errorf("木箱 ParentTagName = %q", byUid[box.TagUid].ParentTagName)
+		}
+
+		if _, _, err := GetTagList(otherUserUid, move.MoveUid, 1, 10); !errors.Is(err, ErrMoveNotFound) {
+			t.Errorf("其他用户 error = %v, want ErrMoveNotFound", err)
+		}
+	})
+}
+
+// TestBuildTagNumbers 标签编号按列表顺序从1开始
+func TestBuildTagNumbers(t *testing.T) {
+	tags := []model.TagModel{{TagUid: "a"}, {TagUid: "b"}, {TagUid: "c"}}
+	numbers := buildTagNumbers(tags)
+	if len(numbers) != 3 || numbers["a"] != 1 || numbers["b"] != 2 || numbers["c"] != 3 {
+		t.Errorf("buildTagNumbers() = %v, want a=1 b=2 c=3", numbers)
+	}
+	if numbers := buildTagNumbers(nil); len(numbers) != 0 {
+		t.Errorf("buildTagNumbers(nil) = %v, want 空", numbers)
+	}
+}
+
+func TestGetTagDetailWithItems(t *testing.T) {
+	forEachStore(t, func(t *testing.T) {
+		move := newTestMove(t)
+		box := newTestTag(t, move.MoveUid, "书箱", "")
+		inner := newTestTag(t, move.MoveUid, "相册", box.TagUid)
+
+		frame, err := CreateItem(testUserUid, CreateItemRequest{TagUid: inner.TagUid, ItemName: "相框", Quantity: 2})
+		if err != nil {
+			t.Fatalf("CreateItem() error = %v", err)
+		}
+		if _, err := CreateItem(testUserUid, CreateItemRequest{TagUid: inner.TagUid, ItemName: "底片"}); err != nil {
+			t.Fatalf("CreateItem() error = %v", err)
+		}
+		if _, err := CreateItem(otherUserUid, CreateItemRequest{TagUid: inner.TagUid, ItemName: "x"}); !errors.Is(err, ErrTagNotFound) {
+			t.Errorf("其他用户添加物品 error = %v, want ErrTagNotFound", err)
+		}
+		if _, err := UpdateItem(testUserUid, UpdateItemRequest{ItemUid: frame.ItemUid, ItemName: "相框", Quantity: 3}); err != nil {
+			t.Fatalf("UpdateItem() error = %v", err)
+		}
+
+		detail, err := GetTagDetail(testUserUid, inner.TagUid)
+		if err != nil {
+			t.Fatalf("GetTagDetail() error = %v", err)
+		}
+		if detail.ParentTagName != "书箱" || detail.StartLocation != "旧家" {
+			t.Errorf("GetTagDetail() 外层容器 = %q, 出发地 = %q", detail.ParentTagName, detail.StartLocation)
+		}
+		if len(detail.Items) != 2 || detail.Items[0].ItemName != "相框" || detail.Items[0].Quantity != 3 {
+			t.Errorf("GetTagDetail() 物品 = %+v", detail.Items)
+		}
+
+		// 删除的物品不再出现
+		if err := DeleteItem(testUserUid, frame.ItemUid, 1); err != nil {
+			t.Fatalf("DeleteItem() error = %v", err)
+		}
+		items, err := GetItemList(testUserUid, inner.TagUid)
+		if err != nil || len(items) != 1 || items[0].ItemName != "底片" {
+			t.Errorf("GetItemList() = %+v, %v", items, err)
+		}
+
+		if _, err := GetTagDetail(otherUserUid, inner.TagUid); !errors.Is(err, ErrTagNotFound) {
+			t.Errorf("其他用户查询标签 error = %v, want ErrTagNotFound", err)
+		}
+	})
+}
@@ -7,9 +7,8 @@ import (
 	"fmt"
 	"time"
 
-	"gorm.io/gorm"
-
 	"movingManager/model"
+	"movingManager/repository"
 )
 
 // UserAuth 用户登录/注册业务处理
@@ -35,8 +34,9 @@ func UserAuth(mobile string) (*model.UserModel, string, error) {
 	}
 
 	// 查询用户是否存在
-	if err := user.GetByMobile(mobile); err != nil {
-		if err == gorm.ErrRecordNotFound {
+	existing, err := store.Users().GetByMobile(mobile)
+	if err != nil {
+		if err == repository.ErrNotFound {
 			// 用户不存在，创建新用户
 			salt, saltErr := generateSalt()
 			if saltErr != nil {
@@ -49,7 +49,7 @@ func UserAuth(mobile string) (*model.UserModel, string, error) {
 				Salt:     salt,
 			}
 
-			if createErr := newUser.Create(); createErr != nil {
+			if createErr := store.Users().Create(&newUser); createErr != nil {
 				return nil, "", fmt.Errorf("创建用户失败: %v", createErr)
 			}
 			return &newUser, token, nil
@@ -58,10 +58,10 @@ func UserAuth(mobile string) (*model.UserModel, string, error) {
 		return nil, "", fmt.Errorf("查询用户失败: %v", err)
 	} else {
 		// 用户存在，更新token
-		if err := user.UpdateAuthCode(token); err != nil {
+		if err := store.Users().UpdateAuthCode(existing, token); err != nil {
 			return nil, "", fmt.Errorf("更新授权码失败: %v", err)
 		}
-		return &user, token, nil
+		return existing, token, nil
 	}
 }
 
@@ -86,6 +86,11 @@ func generateSalt() (string, error) {
 	return base64.URLEncoding.EncodeToString(saltBytes), nil
 }
 
+// GetUserByAuthCode 根据登录授权码查询有效用户
+func GetUserByAuthCode(authCode string) (*model.UserModel, error) {
+	return store.Users().GetByAuthCode(authCode)
+}
+
 // getUserNameFromMobile 从手机号提取用户名(后四位)
 func getUserNameFromMobile(mobile string) string {
 	if len(mobile) >= 4 {
@@ -0,0 +1,575 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"movingManager/config"
+	"movingManager/event"
+	"movingManager/model"
+	"movingManager/repository"
+)
+
+// Webhook默认配置（配置缺省时使用）
+const (
+	defaultWebhookMaxAttempts  = 8
+	defaultWebhookTimeout      = 10 * time.Second
+	defaultWebhookPollInterval = 5 * time.Second
+	webhookBaseBackoff         = 30 * time.Second // 首次重试间隔，之后每次翻倍
+	webhookMaxBackoff          = time.Hour        // 重试间隔上限
+	webhookBatchSize           = 20               // 每轮最多投递的记录数
+	webhookResolveTimeout      = 5 * time.Second  // 注册时解析域名的超时时间
+)
+
+// webhookBlockedNets 不允许推送的保留网段(共享地址、基准测试、文档示例等)
+// 回环、内网、链路本地(含云服务元数据地址169.254.169.254)、组播和未指定地址由net.IP方法判断
+var webhookBlockedNets = mustParseCIDRs(
+	"0.0.0.0/8",
+	"100.64.0.0/10",
+	"192.0.0.0/24",
+	"192.0.2.0/24",
+	"198.18.0.0/15",
+	"198.51.100.0/24",
+	"203.0.113.0/24",
+	"240.0.0.0/4",
+	"64:ff9b::/96",
+	"2001:db8::/32",
+)
+
+// WebhookResponse Webhook响应结构
+type WebhookResponse struct {
+	WebhookUid string   `json:"webhook_uid"`
+	Url        string   `json:"url"`
+	Secret     string   `json:"secret,omitempty"` // 签名密钥，仅创建时返回
+	EventTypes []string `json:"event_types"`
+	IsEnabled  int      `json:"is_enabled"`
+	Remark     string   `json:"remark"`
+	CreatedAt  string   `json:"created_at"`
+}
+
+// WebhookDeliveryResponse 投递记录响应结构
+type WebhookDeliveryResponse struct {
+	DeliveryUid    string `json:"delivery_uid"`
+	EventType      string `json:"event_type"`
+	Payload        string `json:"payload"`
+	Status         int    `json:"status"` // 投递状态(0-等待,1-成功,2-失败)
+	Attempts       int    `json:"attempts"`
+	NextAttemptAt  string `json:"next_attempt_at"`
+	LastStatusCode int    `json:"last_status_code"`
+	LastError      string `json:"last_error"`
+	DeliveredAt    string `json:"delivered_at"`
+	CreatedAt      string `json:"created_at"`
+}
+
+// webhookPayload 推送给用户的JSON内容
+type webhookPayload struct {
+	DeliveryUid string      `json:"delivery_uid"`
+	EventType   string      `json:"event_type"`
+	MoveUid     string      `json:"move_uid"`
+	CreatedAt   int64       `json:"created_at"` // 事件时间戳(毫秒)
+	Data        interface{} `json:"data"`
+}
+
+// CreateWebhookRequest 创建Webhook请求参数
+type CreateWebhookRequest struct {
+	Url        string   `json:"url"`         // 推送地址
+	EventTypes []string `json:"event_types"` // 订阅的事件类型(为空订阅全部)
+	Remark     string   `json:"remark"`      // 备注
+}
+
+// CreateWebhook 创建Webhook业务处理
+// 返回的签名密钥只在创建时下发一次
+func CreateWebhook(userUid string, req CreateWebhookRequest) (*WebhookResponse, error) {
+	if err := validateWebhookEventTypes(req.EventTypes); err != nil {
+		return nil, err
+	}
+	if err := validateWebhookURL(req.Url); err != nil {
+		return nil, err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("生成签名密钥失败: %v", err)
+	}
+
+	webhook := model.WebhookModel{
+		UserUid:    userUid,
+		Url:        req.Url,
+		Secret:     hex.EncodeToString(secret),
+		EventTypes: req.EventTypes,
+		IsEnabled:  1,
+		Remark:     req.Remark,
+	}
+	if err := webhook.Create(); err != nil {
+		return nil, fmt.Errorf("创建Webhook失败: %v", err)
+	}
+
+	response := convertWebhookToResponse(&webhook)
+	response.Secret = webhook.Secret
+	return response, nil
+}
+
+// UpdateWebhookRequest 编辑Webhook请求参数
+type UpdateWebhookRequest struct {
+	WebhookUid string   `json:"webhook_uid"` // WebhookUID
+	Url        string   `json:"url"`         // 推送地址
+	EventTypes []string `json:"event_types"` // 订阅的事件类型(为空订阅全部)
+	IsEnabled  int      `json:"is_enabled"`  // 是否启用(0-停用,1-启用)
+	Remark     string   `json:"remark"`      // 备注
+}
+
+// UpdateWebhook 编辑Webhook业务处理
+func UpdateWebhook(userUid string, req UpdateWebhookRequest) (*WebhookResponse, error) {
+	if err := validateWebhookEventTypes(req.EventTypes); err != nil {
+		return nil, err
+	}
+	if err := validateWebhookURL(req.Url); err != nil {
+		return nil, err
+	}
+
+	var webhook model.WebhookModel
+	if err := webhook.GetByUID(userUid, req.WebhookUid, true); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, fmt.Errorf("查询Webhook记录失败: %v", err)
+	}
+
+	webhook.Url = req.Url
+	webhook.EventTypes = req.EventTypes
+	webhook.IsEnabled = req.IsEnabled
+	webhook.Remark = req.Remark
+	if err := webhook.Update(); err != nil {
+		return nil, fmt.Errorf("更新Webhook失败: %v", err)
+	}
+
+	return convertWebhookToResponse(&webhook), nil
+}
+
+// DeleteWebhook 删除Webhook业务处理
+// 删除后尚未投递的记录不再重试
+func DeleteWebhook(userUid, webhookUid string, isDeleted int) error {
+	var webhook model.WebhookModel
+
+	// 恢复操作时需要查找已删除记录（isDeleted=0表示恢复）
+	onlyUndeleted := isDeleted != 0
+	if err := webhook.GetByUID(userUid, webhookUid, onlyUndeleted); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return ErrWebhookNotFound
+		}
+		return fmt.Errorf("查询Webhook记录失败: %v", err)
+	}
+
+	return webhook.UpdateDeleteStatus(isDeleted)
+}
+
+// GetWebhookList 获取Webhook列表业务处理
+func GetWebhookList(userUid string) ([]WebhookResponse, error) {
+	var webhookModel model.WebhookModel
+	webhooks, err := webhookModel.ListByUser(userUid)
+	if err != nil {
+		return nil, fmt.Errorf("查询Webhook列表失败: %v", err)
+	}
+
+	responses := make([]WebhookResponse, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		responses = append(responses, *convertWebhookToResponse(&webhook))
+	}
+	return responses, nil
+}
+
+// GetWebhookDeliveries 分页获取Webhook投递记录业务处理
+func GetWebhookDeliveries(userUid, webhookUid string, page, pageSize int) ([]WebhookDeliveryResponse, int64, error) {
+	var webhook model.WebhookModel
+	if err := webhook.GetByUID(userUid, webhookUid, false); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, 0, ErrWebhookNotFound
+		}
+		return nil, 0, fmt.Errorf("查询Webhook记录失败: %v", err)
+	}
+
+	var deliveryModel model.WebhookDeliveryModel
+	deliveries, total, err := deliveryModel.ListByWebhook(userUid, webhookUid, page, pageSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("查询投递记录失败: %v", err)
+	}
+
+	responses := make([]WebhookDeliveryResponse, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		nextAttemptAt := ""
+		if delivery.Status == model.DeliveryPending {
+			nextAttemptAt = formatUnixTime(delivery.NextAttemptAt)
+		}
+		responses = append(responses, WebhookDeliveryResponse{
+			DeliveryUid:    delivery.DeliveryUid,
+			EventType:      delivery.EventType,
+			Payload:        delivery.Payload,
+			Status:         delivery.Status,
+			Attempts:       delivery.Attempts,
+			NextAttemptAt:  nextAttemptAt,
+			LastStatusCode: delivery.LastStatusCode,
+			LastError:      delivery.LastError,
+			DeliveredAt:    formatUnixTime(delivery.DeliveredAt),
+			CreatedAt:      formatUnixTime(delivery.CreatedAt),
+		})
+	}
+	return responses, total, nil
+}
+
+// StartWebhookWorker 启动Webhook投递任务
+// 后台轮询并投递到期记录，投递记录由业务事务写入
+// ctx 取消时完成当前批次后退出，返回的通道在退出后关闭
+func StartWebhookWorker(ctx context.Context) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(webhookPollInterval())
+		defer ticker.Stop()
+		client := newWebhookClient()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				processDueDeliveries(ctx, client)
+			}
+		}
+	}()
+	return done
+}
+
+// enqueueWebhookDeliveries 为订阅了事件的Webhook创建投递记录
+// 在业务事务中调用，业务提交则投递记录一定存在，业务回滚则不会推送
+func enqueueWebhookDeliveries(s repository.Store, events []event.Event) error {
+	if len(events) == 0 || events[0].UserUid == "" {
+		return nil
+	}
+
+	webhooks, err := s.Webhooks().ListEnabledByUser(events[0].UserUid)
+	if err != nil {
+		return fmt.Errorf("查询Webhook失败: %v", err)
+	}
+
+	for _, e := range events {
+		for _, webhook := range webhooks {
+			if !webhook.Subscribes(e.Type) {
+				continue
+			}
+
+			deliveryUid := uuid.New().String()
+			payload, err := json.Marshal(webhookPayload{
+				DeliveryUid: deliveryUid,
+				EventType:   e.Type,
+				MoveUid:     e.MoveUid,
+				CreatedAt:   e.CreatedAt,
+				Data:        e.Data,
+			})
+			if err != nil {
+				return fmt.Errorf("序列化Webhook内容失败: %v", err)
+			}
+
+			delivery := model.WebhookDeliveryModel{
+				DeliveryUid:   deliveryUid,
+				WebhookUid:    webhook.WebhookUid,
+				UserUid:       webhook.UserUid,
+				EventType:     e.Type,
+				Payload:       string(payload),
+				Status:        model.DeliveryPending,
+				NextAttemptAt: time.Now().Unix(),
+			}
+			if err := s.Webhooks().CreateDelivery(&delivery); err != nil {
+				return fmt.Errorf("写入Webhook投递队列失败: %v", err)
+			}
+		}
+	}
+	return nil
+}
+
+// processDueDeliveries 领取并投递到期的记录
+func processDueDeliveries(ctx context.Context, client *http.Client) {
+	// 租约覆盖整批投递的最长耗时，期间其他实例不会重复领取
+	now := time.Now()
+	leaseUntil := now.Add(time.Duration(webhookBatchSize+1) * webhookTimeout()).Unix()
+
+	var deliveryModel model.WebhookDeliveryModel
+	deliveries, err := deliveryModel.ClaimDue(now.Unix(), leaseUntil, webhookBatchSize)
+	if err != nil {
+		slog.Error("领取Webhook投递记录失败", "error", err)
+		if len(deliveries) == 0 {
+			return
+		}
+	}
+
+	for i := range deliveries {
+		if ctx.Err() != nil {
+			return
+		}
+		deliverWebhook(ctx, client, &deliveries[i])
+	}
+}
+
+// deliverWebhook 执行一次投递并更新投递记录
+// 2xx 视为成功，其余按指数退避重试，超过最大尝试次数后标记失败
+func deliverWebhook(ctx context.Context, client *http.Client, delivery *model.WebhookDeliveryModel) {
+	var webhook model.WebhookModel
+	err := webhook.GetByWebhookUid(delivery.WebhookUid)
+	if err == nil && (webhook.IsDeleted == 1 || webhook.IsEnabled != 1) {
+		err = fmt.Errorf("Webhook已停用或删除")
+	}
+	if err != nil {
+		delivery.Status = model.DeliveryFailed
+		delivery.LastError = truncateError(err)
+		if err := delivery.Update(); err != nil {
+			slog.Error("更新Webhook投递记录失败", "delivery_uid", delivery.DeliveryUid, "error", err)
+		}
+		return
+	}
+
+	delivery.Attempts++
+	statusCode, err := postWebhook(ctx, client, &webhook, delivery)
+	delivery.LastStatusCode = statusCode
+	if err == nil {
+		delivery.Status = model.DeliverySuccess
+		delivery.LastError = ""
+		delivery.DeliveredAt = time.Now().Unix()
+	} else {
+		delivery.LastError = truncateError(err)
+		if delivery.Attempts >= webhookMaxAttempts() {
+			delivery.Status = model.DeliveryFailed
+		} else {
+			delivery.NextAttemptAt = time.Now().Add(webhookBackoff(delivery.Attempts)).Unix()
+		}
+	}
+
+	if err := delivery.Update(); err != nil {
+		slog.Error("更新Webhook投递记录失败", "delivery_uid", delivery.DeliveryUid, "error", err)
+	}
+}
+
+// postWebhook 发送签名后的推送请求，返回响应状态码
+// 签名为 HMAC-SHA256(密钥, 时间戳 + "." + 请求体) 的十六进制值
+func postWebhook(ctx context.Context, client *http.Client, webhook *model.WebhookModel, delivery *model.WebhookDeliveryModel) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(webhook.Secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.Url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "movingManager-webhook")
+	req.Header.Set("X-Webhook-Event", delivery.EventType)
+	req.Header.Set("X-Webhook-Delivery", delivery.DeliveryUid)
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("响应状态码 %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// newWebhookClient 创建投递使用的HTTP客户端
+// 连接时校验实际拨号的IP，防止域名解析到内网地址；不跟随重定向，3xx按投递失败处理
+func newWebhookClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: webhookTimeout(),
+		Control: webhookDialControl,
+	}
+	return &http.Client{
+		Timeout: webhookTimeout(),
+		Transport: &http.Transport{
+			Proxy:               nil, // 不走环境代理，保证连接校验作用于实际目标
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: webhookTimeout(),
+			MaxIdleConnsPerHost: 2,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// webhookDialControl 拨号前校验目标地址，address 为已解析的 IP:端口
+func webhookDialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || isBlockedWebhookIP(ip) {
+		return fmt.Errorf("推送地址 %s 属于内网或保留地址", host)
+	}
+	return nil
+}
+
+// validateWebhookURL 校验推送地址
+// 仅允许http/https，域名解析出的任一地址属于内网或保留网段时拒绝
+func validateWebhookURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return validationError("推送地址格式错误")
+	}
+
+	host := u.Hostname()
+	var ips []net.IP
+	if ip := net.ParseIP(host); ip != nil {
+		ips = []net.IP{ip}
+	} else {
+		ctx, cancel := context.WithTimeout(context.Background(), webhookResolveTimeout)
+		defer cancel()
+		addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+		if err != nil || len(addrs) == 0 {
+			return validationError("推送地址域名无法解析: %s", host)
+		}
+		for _, addr := range addrs {
+			ips = append(ips, addr.IP)
+		}
+	}
+
+	for _, ip := range ips {
+		if isBlockedWebhookIP(ip) {
+			return validationError("推送地址不能指向内网或保留地址")
+		}
+	}
+	return nil
+}
+
+// isBlockedWebhookIP 判断IP是否属于不允许推送的地址
+func isBlockedWebhookIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return true
+	}
+	for _, n := range webhookBlockedNets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// mustParseCIDRs 解析网段列表，格式错误时panic(仅用于包级常量)
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		nets = append(nets, n)
+	}
+	return nets
+}
+
+// validateWebhookEventTypes 校验订阅的事件类型
+func validateWebhookEventTypes(eventTypes []string) error {
+	for _, t := range eventTypes {
+		supported := false
+		for _, known := range event.AllTypes {
+			if t == known {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			return validationError("不支持的事件类型: %s", t)
+		}
+	}
+	return nil
+}
+
+// webhookBackoff 第 attempts 次失败后的重试间隔
+func webhookBackoff(attempts int) time.Duration {
+	backoff := webhookBaseBackoff
+	for i := 1; i < attempts && backoff < webhookMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > webhookMaxBackoff {
+		backoff = webhookMaxBackoff
+	}
+	return backoff
+}
+
+// truncateError 截断错误信息以适应字段长度
+func truncateError(err error) string {
+	msg := []rune(err.Error())
+	if len(msg) > 500 {
+		msg = msg[:500]
+	}
+	return string(msg)
+}
+
+// webhookMaxAttempts 最大尝试次数
+func webhookMaxAttempts() int {
+	attempts := config.AppConfig.Webhook.MaxAttempts
+	if attempts <= 0 {
+		attempts = defaultWebhookMaxAttempts
+	}
+	return attempts
+}
+
+// webhookTimeout 单次请求超时
+func webhookTimeout() time.Duration {
+	if seconds := config.AppConfig.Webhook.TimeoutSeconds; seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return defaultWebhookTimeout
+}
+
+// webhookPollInterval 投递队列轮询间隔
+func webhookPollInterval() time.Duration {
+	if seconds := config.AppConfig.Webhook.PollIntervalSeconds; seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return defaultWebhookPollInterval
+}
+
+// convertWebhookToResponse 将模型转换为响应格式
+func convertWebhookToResponse(webhook *model.WebhookModel) *WebhookResponse {
+	eventTypes := webhook.EventTypes
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+	return &WebhookResponse{
+		WebhookUid: webhook.WebhookUid,
+		Url:        webhook.Url,
+		EventTypes: eventTypes,
+		IsEnabled:  webhook.IsEnabled,
+		Remark:     webhook.Remark,
+		CreatedAt:  time.Unix(webhook.CreatedAt, 0).Format("2006-01-02 15:04:05"),
+	}
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"movingManager/event"
+	"movingManager/model"
+)
+
+func TestIsBlockedWebhookIP(t *testing.T) {
+	cases := []struct {
+		ip      string
+		blocked bool
+	}{
+		{"127.0.0.1", true},
+		{"10.1.2.3", true},
+		{"172.16.0.1", true},
+		{"192.168.1.1", true},
+		{"169.254.169.254", true},
+		{"100.64.0.1", true},
+		{"0.0.0.0", true},
+		{"224.0.0.1", true},
+		{"::1", true},
+		{"fe80::1", true},
+		{"fd00::1", true},
+		{"::ffff:127.0.0.1", true},
+		{"8.8.8.8", false},
+		{"93.184.216.34", false},
+		{"2606:4700::1111", false},
+	}
+	for _, tc := range cases {
+		if got := isBlockedWebhookIP(net.ParseIP(tc.ip)); got != tc.blocked {
+			t.Errorf("isBlockedWebhookIP(%s) = %v, want %v", tc.ip, got, tc.blocked)
+		}
+	}
+}
+
+func TestValidateWebhookURL(t *testing.T) {
+	cases := []struct {
+		url string
+		ok  bool
+	}{
+		{"https://8.8.8.8/hook", true},
+		{"http://[2606:4700::1111]:8080/hook", true},
+		{"http://127.0.0.1:8080/hook", false},
+		{"http://169.254.169.254/latest/meta-data", false},
+		{"http://[::1]/hook", false},
+		{"http://localhost/hook", false},
+		{"ftp://8.8.8.8/hook", false},
+		{"http:///hook", false},
+	}
+	for _, tc := range cases {
+		err := validateWebhookURL(tc.url)
+		if tc.ok && err != nil {
+			t.Errorf("validateWebhookURL(%s) error = %v", tc.url, err)
+		}
+		if !tc.ok && !errors.Is(err, ErrValidation) {
+			t.Errorf("validateWebhookURL(%s) error = %v, want ErrValidation", tc.url, err)
+		}
+	}
+}
+
+// TestWebhookClientBlocksPrivateDial 注册后解析结果变化时，连接阶段仍拒绝内网地址
+func TestWebhookClientBlocksPrivateDial(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	resp, err := newWebhookClient().Post(server.URL, "application/json", nil)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatal("投递到回环地址未被拒绝")
+	}
+}
+
+// TestWebhookDeliveriesEnqueuedInTransaction 投递记录与业务修改在同一事务中写入
+func TestWebhookDeliveriesEnqueuedInTransaction(t *testing.T) {
+	forEachStore(t, func(t *testing.T) {
+		webhook := model.WebhookModel{
+			UserUid:    testUserUid,
+			Url:        "https://8.8.8.8/hook",
+			Secret:     "secret",
+			EventTypes: []string{event.TypeTagCreated},
+			IsEnabled:  1,
+		}
+		if err := store.Webhooks().Create(&webhook); err != nil {
+			t.Fatalf("创建Webhook失败: %v", err)
+		}
+
+		move := newTestMove(t)
+		newTestTag(t, move.MoveUid, "厨房1", "")
+
+		// 外层容器不存在，事务回滚，不产生投递记录
+		if _, err := CreateTag(testUserUid, CreateTagRequest{MoveUid: move.MoveUid, TagName: "厨房2", ParentTagUid: otherUserUid}); err == nil {
+			t.Fatal("CreateTag() 外层容器不存在时未返回错误")
+		}
+
+		deliveries, err := store.Webhooks().ListDeliveries(testUserUid, webhook.WebhookUid)
+		if err != nil {
+			t.Fatalf("ListDeliveries() error = %v", err)
+		}
+		if len(deliveries) != 1 || deliveries[0].EventType != event.TypeTagCreated || deliveries[0].Status != model.DeliveryPending {
+			t.Fatalf("投递记录 = %+v, want 1 条待投递的 %s", deliveries, event.TypeTagCreated)
+		}
+		if !strings.Contains(deliveries[0].Payload, `"tag_name":"厨房1"`) {
+			t.Errorf("投递内容 = %s", deliveries[0].Payload)
+		}
+	})
+}
+
+// TestClaimDueDeliveries 同一到期记录只能被领取一次
+func TestClaimDueDeliveries(t *testing.T) {
+	useTestDB(t)
+
+	now := time.Now().Unix()
+	for _, nextAttemptAt := range []int64{now - 10, now - 5, now + 60} {
+		delivery := model.WebhookDeliveryModel{WebhookUid: otherUserUid, UserUid: testUserUid, NextAttemptAt: nextAttemptAt}
+		if err := delivery.Create(); err != nil {
+			t.Fatalf("创建投递记录失败: %v", err)
+		}
+	}
+
+	var deliveryModel model.WebhookDeliveryModel
+	claimed, err := deliveryModel.ClaimDue(now, now+300, 10)
+	if err != nil || len(claimed) != 2 {
+		t.Fatalf("ClaimDue() = %d 条, %v, want 2 条", len(claimed), err)
+	}
+	if claimed[0].NextAttemptAt != now+300 {
+		t.Errorf("领取后下次尝试时间 = %d, want %d", claimed[0].NextAttemptAt, now+300)
+	}
+
+	// 租约期内再次领取不到记录，租约到期后与其余到期记录一起重新领取
+	if again, err := deliveryModel.ClaimDue(now, now+300, 10); err != nil || len(again) != 0 {
+		t.Errorf("租约期内 ClaimDue() = %d 条, %v, want 0 条", len(again), err)
+	}
+	if again, err := deliveryModel.ClaimDue(now+300, now+600, 10); err != nil || len(again) != 3 {
+		t.Errorf("租约到期后 ClaimDue() = %d 条, %v, want 3 条", len(again), err)
+	}
+}
+
+func TestPostWebhookSignature(t *testing.T) {
+	const secret = "webhook-secret"
+	payload := `{"type":"tag.verified"}`
+
+	cases := []struct {
+		name       string
+		status     int
+		wantErr    bool
+		wantStatus int
+	}{
+		{"成功响应", http.StatusNoContent, false, http.StatusNoContent},
+		{"失败响应", http.StatusInternalServerError, true, http.StatusInternalServerError},
+		{"重定向按失败处理", http.StatusFound, true, http.StatusFound},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				mac := hmac.New(sha256.New, []byte(secret))
+				mac.Write([]byte(r.Header.Get("X-Webhook-Timestamp") + "."))
+				mac.Write(body)
+				want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+				if got := r.Header.Get("X-Webhook-Signature"); !hmac.Equal([]byte(got), []byte(want)) {
+					t.Errorf("签名 = %q, want %q", got, want)
+				}
+				if string(body) != payload {
+					t.Errorf("推送内容 = %q, want %q", body, payload)
+				}
+				if got := r.Header.Get("X-Webhook-Delivery"); got != "delivery-1" {
+					t.Errorf("X-Webhook-Delivery = %q", got)
+				}
+				if tc.status == http.StatusFound {
+					w.Header().Set("Location", "http://127.0.0.1/")
+				}
+				w.WriteHeader(tc.status)
+			}))
+			defer server.Close()
+
+			client := newWebhookClient()
+			// 测试服务器监听在回环地址，跳过拨号校验
+			client.Transport.(*http.Transport).DialContext = (&net.Dialer{}).DialContext
+
+			webhook := model.WebhookModel{Url: server.URL, Secret: secret}
+			delivery := model.WebhookDeliveryModel{DeliveryUid: "delivery-1", EventType: "tag.verified", Payload: payload}
+			status, err := postWebhook(context.Background(), client, &webhook, &delivery)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("postWebhook() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if status != tc.wantStatus {
+				t.Errorf("postWebhook() status = %d, want %d", status, tc.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStorage 本地文件系统存储
+type LocalStorage struct {
+	root string // 存储根目录
+}
+
+// NewLocalStorage 创建本地文件系统存储，根目录不存在时自动创建
+func NewLocalStorage(root string) (*LocalStorage, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("创建存储目录失败: %v", err)
+	}
+	return &LocalStorage{root: root}, nil
+}
+
+// Save 保存文件内容
+// 先写入临时文件再重命名，避免中途失败留下不完整的文件
+func (s *LocalStorage) Save(key string, r io.Reader) (int64, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return 0, fmt.Errorf("创建存储目录失败: %v", err)
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return 0, fmt.Errorf("创建临时文件失败: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	n, err := io.Copy(tmpFile, r)
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, fmt.Errorf("写入文件失败: %v", err)
+	}
+
+	if err := os.Rename(tmpFile.Name(), path); err != nil {
+		return 0, fmt.Errorf("保存文件失败: %v", err)
+	}
+	return n, nil
+}
+
+// Open 打开文件用于读取
+func (s *LocalStorage) Open(key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+// Delete 删除文件
+func (s *LocalStorage) Delete(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// path 将存储key转换为本地路径，拒绝跳出根目录的key
+func (s *LocalStorage) path(key string) (string, error) {
+	cleaned := filepath.Clean(filepath.FromSlash(key))
+	if key == "" || filepath.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("非法的存储路径: %s", key)
+	}
+	return filepath.Join(s.root, cleaned), nil
+}
@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+
+	"movingManager/config"
+)
+
+// Storage 附件存储接口
+// key 使用"/"分隔的相对路径，由业务层生成，实现负责映射到具体存储位置
+type Storage interface {
+	// Save 保存文件内容，返回写入的字节数
+	Save(key string, r io.Reader) (int64, error)
+	// Open 打开文件用于读取，调用方负责关闭
+	Open(key string) (io.ReadCloser, error)
+	// Delete 删除文件，文件不存在时不返回错误
+	Delete(key string) error
+}
+
+// Default 全局附件存储实例
+var Default Storage
+
+// InitStorage 根据配置初始化附件存储
+func InitStorage(cfg config.StorageConfig) error {
+	switch cfg.Driver {
+	case "", "local":
+		root := cfg.LocalRoot
+		if root == "" {
+			root = "uploads"
+		}
+		local, err := NewLocalStorage(root)
+		if err != nil {
+			return err
+		}
+		Default = local
+		return nil
+	default:
+		return fmt.Errorf("不支持的存储驱动: %s", cfg.Driver)
+	}
+}
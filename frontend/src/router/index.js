@@ -4,6 +4,7 @@ import TagListView from '../views/TagListView.vue'
 import ScanView from '../views/ScanView.vue'
 import LoginView from '../views/LoginView.vue'
 import TagDetailView from '../views/TagDetailView.vue'
+import PublicScanView from '../views/PublicScanView.vue'
 import { checkTokenExists } from '../utils/request'
 import { ElMessage } from 'element-plus'
 
@@ -12,7 +13,8 @@ const routes = [
   { path: '/tags/:moveUid', name: 'tagList', component: TagListView, props: true },
   { path: '/scan', name: 'scan', component: ScanView },
   { path: '/login', name: 'login', component: LoginView },
-  { path: '/tag/:tagUid', name: 'tagDetail', component: TagDetailView, props: true }
+  { path: '/tag/:tagUid', name: 'tagDetail', component: TagDetailView, props: true },
+  { path: '/scan/:token', name: 'publicScan', component: PublicScanView, props: true }
 ]
 
 const router = createRouter({
@@ -24,7 +26,8 @@ const router = createRouter({
 router.beforeEach((to, from, next) => {
   // 不需要登录的页面
   const publicPages = ['/login'];
-  const authRequired = !publicPages.includes(to.path);
+  // 扫码链接凭签名令牌访问，无需登录
+  const authRequired = !publicPages.includes(to.path) && !to.path.startsWith('/scan/');
   
   if (authRequired && !checkTokenExists()) {
       // 未登录，显示提示并延迟2秒跳转
@@ -26,4 +26,15 @@ export const downloadMovePdf = async (moveId) => {
     responseType: 'blob'
   });
   return response.data;
-};
\ No newline at end of file
+};
+
+// 导出搬运清单(csv,xlsx,json)
+export const exportMove = async (moveId, format) => {
+  const response = await api.post('/move/export', {
+    move_uid: moveId,
+    format
+  }, {
+    responseType: 'blob'
+  });
+  return response.data;
+};
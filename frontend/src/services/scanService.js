@@ -0,0 +1,13 @@
+import api from './api';
+
+// 免登录扫码查看标签
+export const getScanDetail = async (token) => {
+  const response = await api.post('/scan/detail', { token });
+  return response.data;
+};
+
+// 免登录扫码核销标签
+export const scanVerifyTag = async (token, scannerName) => {
+  const response = await api.post('/scan/verify', { token, scanner_name: scannerName });
+  return response.data;
+};